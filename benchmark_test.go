@@ -152,3 +152,94 @@ func generateProduceItems(size int) []*pb.QueueProduceItem {
 	}
 	return items
 }
+
+// BenchmarkReserveDeepQueue measures the cost of reserving from a deep queue where every item has the
+// same priority, the cost of each reservation should not grow with the number of items in the queue.
+func BenchmarkReserveDeepQueue(b *testing.B) {
+	bdb := boltTestSetup{Dir: b.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+		},
+	} {
+		for _, depth := range []int{1_000, 10_000} {
+			b.Run(fmt.Sprintf("%s/Depth_%d", tc.Name, depth), func(b *testing.B) {
+				d, err := daemon.NewDaemon(context.Background(), daemon.Config{
+					ServiceConfig: querator.ServiceConfig{
+						StorageConfig: tc.Setup(clock.NewProvider()),
+						Logger:        log,
+					},
+				})
+				require.NoError(b, err)
+				defer func() {
+					_ = d.Shutdown(context.Background())
+					tc.TearDown()
+				}()
+				s := d.Service()
+				ctx := context.Background()
+				require.NoError(b, s.QueuesCreate(ctx, &pb.QueueInfo{
+					QueueName:      "bench-queue",
+					DeadTimeout:    "24h0m0s",
+					ReserveTimeout: "1m0s",
+					Partitions:     1,
+				}))
+
+				items := generateProduceItems(1_000)
+				for i := 0; i < depth; i += len(items) {
+					require.NoError(b, s.QueueProduce(ctx, &pb.QueueProduceRequest{
+						QueueName:      "bench-queue",
+						RequestTimeout: "1m",
+						Items:          items,
+					}))
+				}
+
+				b.ResetTimer()
+				for n := 0; n < b.N; n++ {
+					var res pb.QueueReserveResponse
+					if err := s.QueueReserve(ctx, &pb.QueueReserveRequest{
+						ClientId:       "bench-client",
+						QueueName:      "bench-queue",
+						RequestTimeout: "1m",
+						BatchSize:      1,
+					}, &res); err != nil {
+						b.Fatal(err)
+					}
+
+					// Complete and produce the item again, such that the depth of the queue remains the same
+					if err := s.QueueComplete(ctx, &pb.QueueCompleteRequest{
+						QueueName:      "bench-queue",
+						RequestTimeout: "1m",
+						Ids:            []string{res.Items[0].Id},
+					}, &pb.QueueCompleteResponse{}); err != nil {
+						b.Fatal(err)
+					}
+					if err := s.QueueProduce(ctx, &pb.QueueProduceRequest{
+						QueueName:      "bench-queue",
+						RequestTimeout: "1m",
+						Items:          items[:1],
+					}); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
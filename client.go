@@ -13,8 +13,11 @@ import (
 	"github.com/kapetan-io/querator/transport"
 	"github.com/kapetan-io/tackle/clock"
 	"github.com/kapetan-io/tackle/set"
+	"go.opentelemetry.io/otel/propagation"
 	"google.golang.org/protobuf/proto"
 	"net/http"
+	"net/url"
+	"sync/atomic"
 )
 
 const (
@@ -23,6 +26,20 @@ const (
 	MsgServiceInShutdown = internal.MsgServiceInShutdown
 	MsgQueueInShutdown   = internal.MsgQueueInShutdown
 	MsgQueueOverLoaded   = internal.MsgQueueOverLoaded
+	MsgRateLimited       = internal.MsgRateLimited
+	MsgHealthCheckFailed = internal.MsgHealthCheckFailed
+	MsgReloadFailed      = internal.MsgReloadFailed
+	MsgServiceDraining   = internal.MsgServiceDraining
+)
+
+const (
+	HealthPass = transport.HealthPass
+	HealthFail = transport.HealthFail
+)
+
+const (
+	DefaultMaxItemSize    = internal.DefaultMaxItemSize
+	DefaultMaxReserveSize = internal.DefaultMaxReserveSize
 )
 
 type ListOptions struct {
@@ -35,11 +52,27 @@ type ClientConfig struct {
 	Client *http.Client
 	// The address of endpoint in the format `<scheme>://<host>:<port>`
 	Endpoint string
+	// Namespace is the namespace all requests made by this client operate within. If empty,
+	// requests operate within the default namespace.
+	Namespace string
+	// Identity identifies this client to the server for the purpose of rate limiting. If empty,
+	// the server identifies the client by its remote address.
+	Identity string
+	// RetryPolicy is the policy used to retry requests which fail with a retryable error.
+	// See RetryPolicy for details. The default is to not retry.
+	RetryPolicy RetryPolicy
+	// FailoverEndpoints are alternate endpoints in the format `<scheme>://<host>:<port>` which
+	// requests are retried against when the current endpoint is shutting down or unreachable.
+	// Requests are only retried if RetryPolicy.MaxAttempts allows it.
+	FailoverEndpoints []string
 }
 
 type Client struct {
-	client *duh.Client
-	conf   ClientConfig
+	client    *duh.Client
+	endpoints []*url.URL
+	// endpoint is the index of the endpoint in endpoints which requests are currently sent to
+	endpoint atomic.Int32
+	conf     ClientConfig
 }
 
 // NewClient creates a new instance of the Gubernator user client
@@ -57,15 +90,30 @@ func NewClient(conf ClientConfig) (*Client, error) {
 		return nil, errors.New("conf.Endpoint is empty; must provide an http endpoint")
 	}
 
+	var endpoints []*url.URL
+	for _, e := range append([]string{conf.Endpoint}, conf.FailoverEndpoints...) {
+		u, err := url.Parse(e)
+		if err != nil {
+			return nil, fmt.Errorf("endpoint '%s' is invalid: %w", e, err)
+		}
+		endpoints = append(endpoints, u)
+	}
+
 	return &Client{
 		client: &duh.Client{
 			Client: conf.Client,
 		},
-		conf: conf,
+		endpoints: endpoints,
+		conf:      conf,
 	}, nil
 }
 
 func (c *Client) QueueProduce(ctx context.Context, req *pb.QueueProduceRequest) error {
+	return c.produce(ctx, req, c.conf.RetryPolicy)
+}
+
+// produce produces the items using the retry policy provided instead of the policy of the client
+func (c *Client) produce(ctx context.Context, req *pb.QueueProduceRequest, policy RetryPolicy) error {
 	payload, err := proto.Marshal(req)
 	if err != nil {
 		return duh.NewClientError("while marshaling request payload: %w", err, nil)
@@ -77,9 +125,9 @@ func (c *Client) QueueProduce(ctx context.Context, req *pb.QueueProduceRequest)
 		return duh.NewClientError("", err, nil)
 	}
 
-	r.Header.Set("Content-Type", duh.ContentTypeProtoBuf)
+	c.setHeaders(r)
 	var res v1.Reply
-	return c.client.Do(r, &res)
+	return c.retry(r, &res, policy)
 }
 
 func (c *Client) QueueReserve(ctx context.Context, req *pb.QueueReserveRequest, res *pb.QueueReserveResponse) error {
@@ -94,8 +142,8 @@ func (c *Client) QueueReserve(ctx context.Context, req *pb.QueueReserveRequest,
 		return duh.NewClientError("", err, nil)
 	}
 
-	r.Header.Set("Content-Type", duh.ContentTypeProtoBuf)
-	return c.client.Do(r, res)
+	c.setHeaders(r)
+	return c.do(r, res)
 }
 
 func (c *Client) QueueComplete(ctx context.Context, req *pb.QueueCompleteRequest) error {
@@ -110,9 +158,161 @@ func (c *Client) QueueComplete(ctx context.Context, req *pb.QueueCompleteRequest
 		return duh.NewClientError("", err, nil)
 	}
 
-	r.Header.Set("Content-Type", duh.ContentTypeProtoBuf)
+	c.setHeaders(r)
+	var res v1.Reply
+	return c.do(r, &res)
+}
+
+// QueueCompleteDetailed marks reserved items as complete, returning the outcome of each id in res.
+// Unlike QueueComplete, ids which cannot be completed do not cause the request to fail.
+// req.Detailed is set to true by this method.
+func (c *Client) QueueCompleteDetailed(ctx context.Context, req *pb.QueueCompleteRequest,
+	res *pb.QueueCompleteResponse) error {
+
+	req.Detailed = true
+	payload, err := proto.Marshal(req)
+	if err != nil {
+		return duh.NewClientError("while marshaling request payload: %w", err, nil)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s%s", c.conf.Endpoint, transport.RPCQueueComplete), bytes.NewReader(payload))
+	if err != nil {
+		return duh.NewClientError("", err, nil)
+	}
+
+	c.setHeaders(r)
+	return c.do(r, res)
+}
+
+func (c *Client) QueueExtend(ctx context.Context, req *pb.QueueExtendRequest, res *pb.QueueExtendResponse) error {
+	payload, err := proto.Marshal(req)
+	if err != nil {
+		return duh.NewClientError("while marshaling request payload: %w", err, nil)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s%s", c.conf.Endpoint, transport.RPCQueueExtend), bytes.NewReader(payload))
+	if err != nil {
+		return duh.NewClientError("", err, nil)
+	}
+
+	c.setHeaders(r)
+	return c.do(r, res)
+}
+
+func (c *Client) QueueRelease(ctx context.Context, req *pb.QueueReleaseRequest) error {
+	payload, err := proto.Marshal(req)
+	if err != nil {
+		return duh.NewClientError("while marshaling request payload: %w", err, nil)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s%s", c.conf.Endpoint, transport.RPCQueueRelease), bytes.NewReader(payload))
+	if err != nil {
+		return duh.NewClientError("", err, nil)
+	}
+
+	c.setHeaders(r)
+	var res v1.Reply
+	return c.do(r, &res)
+}
+
+func (c *Client) QueuePeek(ctx context.Context, req *pb.QueuePeekRequest, res *pb.QueuePeekResponse) error {
+	payload, err := proto.Marshal(req)
+	if err != nil {
+		return duh.NewClientError("while marshaling request payload: %w", err, nil)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s%s", c.conf.Endpoint, transport.RPCQueuePeek), bytes.NewReader(payload))
+	if err != nil {
+		return duh.NewClientError("", err, nil)
+	}
+
+	c.setHeaders(r)
+	return c.do(r, res)
+}
+
+func (c *Client) QueueGet(ctx context.Context, req *pb.QueueGetRequest, res *pb.QueueGetResponse) error {
+	payload, err := proto.Marshal(req)
+	if err != nil {
+		return duh.NewClientError("while marshaling request payload: %w", err, nil)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s%s", c.conf.Endpoint, transport.RPCQueueGet), bytes.NewReader(payload))
+	if err != nil {
+		return duh.NewClientError("", err, nil)
+	}
+
+	c.setHeaders(r)
+	return c.do(r, res)
+}
+
+func (c *Client) QueueRegister(ctx context.Context, req *pb.QueueRegisterRequest, res *pb.QueueHeartbeatResponse) error {
+	payload, err := proto.Marshal(req)
+	if err != nil {
+		return duh.NewClientError("while marshaling request payload: %w", err, nil)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s%s", c.conf.Endpoint, transport.RPCQueueRegister), bytes.NewReader(payload))
+	if err != nil {
+		return duh.NewClientError("", err, nil)
+	}
+
+	c.setHeaders(r)
+	return c.do(r, res)
+}
+
+func (c *Client) QueueHeartbeat(ctx context.Context, req *pb.QueueHeartbeatRequest, res *pb.QueueHeartbeatResponse) error {
+	payload, err := proto.Marshal(req)
+	if err != nil {
+		return duh.NewClientError("while marshaling request payload: %w", err, nil)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s%s", c.conf.Endpoint, transport.RPCQueueHeartbeat), bytes.NewReader(payload))
+	if err != nil {
+		return duh.NewClientError("", err, nil)
+	}
+
+	c.setHeaders(r)
+	return c.do(r, res)
+}
+
+func (c *Client) QueueDeregister(ctx context.Context, req *pb.QueueDeregisterRequest) error {
+	payload, err := proto.Marshal(req)
+	if err != nil {
+		return duh.NewClientError("while marshaling request payload: %w", err, nil)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s%s", c.conf.Endpoint, transport.RPCQueueDeregister), bytes.NewReader(payload))
+	if err != nil {
+		return duh.NewClientError("", err, nil)
+	}
+
+	c.setHeaders(r)
 	var res v1.Reply
-	return c.client.Do(r, &res)
+	return c.do(r, &res)
+}
+
+func (c *Client) QueueConsumers(ctx context.Context, req *pb.QueueConsumersRequest, res *pb.QueueConsumersResponse) error {
+	payload, err := proto.Marshal(req)
+	if err != nil {
+		return duh.NewClientError("while marshaling request payload: %w", err, nil)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s%s", c.conf.Endpoint, transport.RPCQueueConsumers), bytes.NewReader(payload))
+	if err != nil {
+		return duh.NewClientError("", err, nil)
+	}
+
+	c.setHeaders(r)
+	return c.do(r, res)
 }
 
 func (c *Client) QueueClear(ctx context.Context, req *pb.QueueClearRequest) error {
@@ -127,9 +327,9 @@ func (c *Client) QueueClear(ctx context.Context, req *pb.QueueClearRequest) erro
 		return duh.NewClientError("", err, nil)
 	}
 
-	r.Header.Set("Content-Type", duh.ContentTypeProtoBuf)
+	c.setHeaders(r)
 	var res v1.Reply
-	return c.client.Do(r, &res)
+	return c.do(r, &res)
 }
 
 // -------------------------------------------------
@@ -148,9 +348,9 @@ func (c *Client) QueuesCreate(ctx context.Context, req *pb.QueueInfo) error {
 		return duh.NewClientError("", err, nil)
 	}
 
-	r.Header.Set("Content-Type", duh.ContentTypeProtoBuf)
+	c.setHeaders(r)
 	var res v1.Reply
-	return c.client.Do(r, &res)
+	return c.do(r, &res)
 }
 
 func (c *Client) QueuesList(ctx context.Context, res *pb.QueuesListResponse, opts *ListOptions) error {
@@ -171,8 +371,8 @@ func (c *Client) QueuesList(ctx context.Context, res *pb.QueuesListResponse, opt
 		return duh.NewClientError("", err, nil)
 	}
 
-	r.Header.Set("Content-Type", duh.ContentTypeProtoBuf)
-	return c.client.Do(r, res)
+	c.setHeaders(r)
+	return c.do(r, res)
 }
 
 func (c *Client) QueuesUpdate(ctx context.Context, req *pb.QueueInfo) error {
@@ -187,9 +387,9 @@ func (c *Client) QueuesUpdate(ctx context.Context, req *pb.QueueInfo) error {
 		return duh.NewClientError("", err, nil)
 	}
 
-	r.Header.Set("Content-Type", duh.ContentTypeProtoBuf)
+	c.setHeaders(r)
 	var res v1.Reply
-	return c.client.Do(r, &res)
+	return c.do(r, &res)
 }
 
 func (c *Client) QueuesDelete(ctx context.Context, req *pb.QueuesDeleteRequest) error {
@@ -204,13 +404,11 @@ func (c *Client) QueuesDelete(ctx context.Context, req *pb.QueuesDeleteRequest)
 		return duh.NewClientError("", err, nil)
 	}
 
-	r.Header.Set("Content-Type", duh.ContentTypeProtoBuf)
+	c.setHeaders(r)
 	var res v1.Reply
-	return c.client.Do(r, &res)
+	return c.do(r, &res)
 }
 
-// TODO: Write an iterator we can use to iterate through list APIs
-
 func (c *Client) StorageQueueList(ctx context.Context, name string, res *pb.StorageQueueListResponse,
 	opts *ListOptions) error {
 
@@ -235,8 +433,8 @@ func (c *Client) StorageQueueList(ctx context.Context, name string, res *pb.Stor
 		return duh.NewClientError("", err, nil)
 	}
 
-	r.Header.Set("Content-Type", duh.ContentTypeProtoBuf)
-	return c.client.Do(r, res)
+	c.setHeaders(r)
+	return c.do(r, res)
 }
 
 func (c *Client) StorageQueueAdd(ctx context.Context, req *pb.StorageQueueAddRequest,
@@ -253,8 +451,8 @@ func (c *Client) StorageQueueAdd(ctx context.Context, req *pb.StorageQueueAddReq
 		return duh.NewClientError("", err, nil)
 	}
 
-	r.Header.Set("Content-Type", duh.ContentTypeProtoBuf)
-	return c.client.Do(r, res)
+	c.setHeaders(r)
+	return c.do(r, res)
 }
 
 func (c *Client) StorageQueueDelete(ctx context.Context, req *pb.StorageQueueDeleteRequest) error {
@@ -270,9 +468,9 @@ func (c *Client) StorageQueueDelete(ctx context.Context, req *pb.StorageQueueDel
 		return duh.NewClientError("", err, nil)
 	}
 
-	r.Header.Set("Content-Type", duh.ContentTypeProtoBuf)
+	c.setHeaders(r)
 	var res v1.Reply
-	return c.client.Do(r, &res)
+	return c.do(r, &res)
 }
 
 func (c *Client) QueueStats(ctx context.Context, req *pb.QueueStatsRequest,
@@ -289,8 +487,38 @@ func (c *Client) QueueStats(ctx context.Context, req *pb.QueueStatsRequest,
 		return duh.NewClientError("", err, nil)
 	}
 
-	r.Header.Set("Content-Type", duh.ContentTypeProtoBuf)
-	return c.client.Do(r, res)
+	c.setHeaders(r)
+	return c.do(r, res)
+}
+
+// AdminReload asks the server to reload its config, applying any changes to limits,
+// timeouts and storage backends without a restart
+func (c *Client) AdminReload(ctx context.Context) error {
+	var res v1.Reply
+	return c.admin(ctx, transport.RPCAdminReload, &res)
+}
+
+// AdminDrain places the server into drain mode in preparation for shutdown, and returns the
+// progress of the drain. See Service.Drain() for details.
+func (c *Client) AdminDrain(ctx context.Context, res *pb.AdminDrainResponse) error {
+	return c.admin(ctx, transport.RPCAdminDrain, res)
+}
+
+// AdminDrainStatus returns the progress of a drain started by AdminDrain()
+func (c *Client) AdminDrainStatus(ctx context.Context, res *pb.AdminDrainResponse) error {
+	return c.admin(ctx, transport.RPCAdminDrainStatus, res)
+}
+
+// admin performs an admin request which has no request payload
+func (c *Client) admin(ctx context.Context, rpc string, res proto.Message) error {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s%s", c.conf.Endpoint, rpc), nil)
+	if err != nil {
+		return duh.NewClientError("", err, nil)
+	}
+
+	c.setHeaders(r)
+	return c.do(r, res)
 }
 
 // WithNoTLS returns ClientConfig suitable for use with NON-TLS clients
@@ -335,3 +563,16 @@ func CollectIDs[S ~[]E, E ItemsWithIDs](items S) []string {
 	}
 	return result
 }
+
+// setHeaders sets the headers common to all requests made by the client
+func (c *Client) setHeaders(r *http.Request) {
+	r.Header.Set("Content-Type", duh.ContentTypeProtoBuf)
+	if c.conf.Namespace != "" {
+		r.Header.Set(transport.HeaderNamespace, c.conf.Namespace)
+	}
+	if c.conf.Identity != "" {
+		r.Header.Set(transport.HeaderClientIdentity, c.conf.Identity)
+	}
+	// Propagate the trace context of the caller, if any, to the server
+	propagation.TraceContext{}.Inject(r.Context(), propagation.HeaderCarrier(r.Header))
+}
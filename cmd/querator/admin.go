@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+)
+
+func adminReload(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("admin reload", flag.ContinueOnError)
+	if err := c.parse(flags, args, 0, "admin reload\n\n"+
+		"Reloads the server config, applying changes to limits, timeouts and storage backends"); err != nil {
+		return err
+	}
+
+	if err := c.client.AdminReload(ctx); err != nil {
+		return err
+	}
+	return c.out.message("config reloaded")
+}
+
+func adminDrain(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("admin drain", flag.ContinueOnError)
+	wait := flags.Bool("wait", false, "wait until the server has drained")
+	if err := c.parse(flags, args, 0, "admin drain [flags]\n\n"+
+		"Places the server into drain mode in preparation for shutdown. New produce and reserve\n"+
+		"requests are rejected while in flight requests complete. Drain mode cannot be cancelled"); err != nil {
+		return err
+	}
+
+	var res pb.AdminDrainResponse
+	if err := c.client.AdminDrain(ctx, &res); err != nil {
+		return err
+	}
+
+	for *wait && !res.Drained {
+		select {
+		case <-clock.After(100 * clock.Millisecond):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if err := c.client.AdminDrainStatus(ctx, &res); err != nil {
+			return err
+		}
+	}
+	return c.drainStatus(&res)
+}
+
+func adminDrainStatus(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("admin drain-status", flag.ContinueOnError)
+	if err := c.parse(flags, args, 0, "admin drain-status"); err != nil {
+		return err
+	}
+
+	var res pb.AdminDrainResponse
+	if err := c.client.AdminDrainStatus(ctx, &res); err != nil {
+		return err
+	}
+	return c.drainStatus(&res)
+}
+
+func (c *cli) drainStatus(res *pb.AdminDrainResponse) error {
+	t := c.out.table("DRAINING", "DRAINED", "QUEUES", "IN FLIGHT", "RESERVED")
+	t.row(res, res.Draining, res.Drained, res.Queues, res.InFlight, res.Reserved)
+	return t.flush()
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/tackle/clock"
+)
+
+const usage = `querator is a tool for operating Querator
+
+Usage:
+  querator [flags] <command> <subcommand> [flags] [args]
+
+Commands:
+  server                           Run the Querator server
+
+  queues list                      List queues
+  queues create <queue>            Create a queue
+  queues update <queue>            Update a queue
+  queues delete <queue>            Delete a queue
+
+  queue produce <queue>            Produce items from stdin or a file
+  queue reserve <queue>            Reserve items, optionally completing them interactively
+  queue complete <queue> <id>...   Mark reserved items as complete
+  queue extend <queue> <id>...     Extend the reservation of reserved items
+  queue release <queue> <id>...    Release reserved items back to the queue
+  queue peek <queue>               Show the next items to be reserved without reserving them
+  queue get <queue> <id>...        Show items by id without reserving them
+  queue consumers <queue>          List consumers registered with a queue
+  queue stats <queue>              Show queue statistics
+  queue clear <queue>              Remove items from a queue
+
+  storage list <queue>             List items in storage
+  storage add <queue>              Add items to storage from stdin or a file
+  storage delete <queue> <id>...   Delete items from storage
+
+  admin reload                     Reload the server config without a restart
+  admin drain                      Drain the server in preparation for shutdown
+  admin drain-status               Show the progress of a drain
+
+Flags:
+`
+
+// cli holds the global options and client shared by all commands
+type cli struct {
+	client *que.Client
+	stdin  io.Reader
+	stderr io.Writer
+	out    *output
+	// timeout is the request_timeout used for requests which support it
+	timeout clock.Duration
+}
+
+// command is a subcommand of the cli
+type command func(ctx context.Context, c *cli, args []string) error
+
+var commands = map[string]map[string]command{
+	"queues": {
+		"list":   queuesList,
+		"create": queuesCreate,
+		"update": queuesUpdate,
+		"delete": queuesDelete,
+	},
+	"queue": {
+		"produce":   queueProduce,
+		"reserve":   queueReserve,
+		"complete":  queueComplete,
+		"extend":    queueExtend,
+		"release":   queueRelease,
+		"peek":      queuePeek,
+		"get":       queueGet,
+		"consumers": queueConsumers,
+		"stats":     queueStats,
+		"clear":     queueClear,
+	},
+	"storage": {
+		"list":   storageList,
+		"add":    storageAdd,
+		"delete": storageDelete,
+	},
+	"admin": {
+		"reload":       adminReload,
+		"drain":        adminDrain,
+		"drain-status": adminDrainStatus,
+	},
+}
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("querator", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	endpoint := flags.String("endpoint", envOrDefault("QUERATOR_ENDPOINT", "http://localhost:2319"),
+		"the endpoint of the querator server (env QUERATOR_ENDPOINT)")
+	namespace := flags.String("namespace", os.Getenv("QUERATOR_NAMESPACE"),
+		"the namespace requests operate within (env QUERATOR_NAMESPACE)")
+	format := flags.String("output", "table", "the output format, one of 'table' or 'json'")
+	timeout := flags.Duration("timeout", 30*clock.Second, "the timeout of each request made to the server")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	out, err := newOutput(stdout, *format)
+	if err != nil {
+		return err
+	}
+
+	if flags.Arg(0) == "server" {
+		return server(ctx, flags.Args()[1:], stderr)
+	}
+
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+
+	cmd, ok := commands[flags.Arg(0)][flags.Arg(1)]
+	if !ok {
+		flags.Usage()
+		return fmt.Errorf("unknown command '%s'", strings.Join(flags.Args()[:2], " "))
+	}
+
+	// The timeout applies to each request rather than the whole command, such that commands which
+	// make many requests or wait on the operator, such as 'queue produce' and 'queue reserve -interactive'
+	// are not cut short. The server is given time to respond after the request timeout has elapsed.
+	client, err := que.NewClient(que.ClientConfig{
+		Client: &http.Client{
+			Transport: http.DefaultTransport,
+			Timeout:   *timeout + (5 * clock.Second),
+		},
+		Endpoint:  *endpoint,
+		Namespace: *namespace,
+	})
+	if err != nil {
+		return err
+	}
+
+	return cmd(ctx, &cli{
+		client:  client,
+		stdin:   stdin,
+		stderr:  stderr,
+		out:     out,
+		timeout: *timeout,
+	}, flags.Args()[2:])
+}
+
+// parse parses the flags of a subcommand, and ensures at least `required` positional arguments are provided
+func (c *cli) parse(flags *flag.FlagSet, args []string, required int, usage string) error {
+	flags.SetOutput(c.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage:\n  querator %s\n\nFlags:\n", usage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < required {
+		flags.Usage()
+		return errors.New("missing required arguments")
+	}
+	return nil
+}
+
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// int32Flag returns a flag.Func which parses the flag value into the int32 provided
+func int32Flag(v *int32) func(string) error {
+	return func(s string) error {
+		i, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return err
+		}
+		*v = int32(i)
+		return nil
+	}
+}
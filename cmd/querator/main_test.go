@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/daemon"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestCLI(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*clock.Second)
+	defer cancel()
+
+	conf := store.StorageConfig{QueueStore: store.NewMemoryQueueStore(), Clock: clock.NewProvider()}
+	conf.Backends = []store.Backend{
+		{
+			PartitionStore: store.NewMemoryPartitionStore(conf),
+			Name:           "memory-0",
+			Affinity:       1,
+		},
+	}
+	d, err := daemon.NewDaemon(ctx, daemon.Config{ServiceConfig: que.ServiceConfig{StorageConfig: conf}})
+	require.NoError(t, err)
+	defer func() { _ = d.Shutdown(context.Background()) }()
+
+	endpoint := fmt.Sprintf("http://%s", d.Listener.Addr().String())
+	cmd := func(t *testing.T, stdin string, args ...string) (string, error) {
+		t.Helper()
+		var stdout, stderr bytes.Buffer
+		err := run(ctx, append([]string{"-endpoint", endpoint, "-timeout", "1s"}, args...),
+			strings.NewReader(stdin), &stdout, &stderr)
+		return stdout.String(), err
+	}
+
+	t.Run("Queues", func(t *testing.T) {
+		out, err := cmd(t, "", "queues", "create", "-reserve-timeout", "1m", "-dead-timeout", "10m",
+			"-partitions", "1", "queue-00")
+		require.NoError(t, err)
+		assert.Equal(t, "queue 'queue-00' created\n", out)
+
+		_, err = cmd(t, "", "queues", "update", "-max-attempts", "5", "queue-00")
+		require.NoError(t, err)
+
+		out, err = cmd(t, "", "-output", "json", "queues", "list")
+		require.NoError(t, err)
+		var info pb.QueueInfo
+		require.NoError(t, protojson.Unmarshal([]byte(out), &info))
+		assert.Equal(t, "queue-00", info.QueueName)
+		assert.Equal(t, int32(5), info.MaxAttempts)
+
+		out, err = cmd(t, "", "queues", "list")
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		require.Len(t, lines, 2)
+		assert.True(t, strings.HasPrefix(lines[0], "NAME"))
+		assert.True(t, strings.HasPrefix(lines[1], "queue-00"))
+
+		_, err = cmd(t, "", "queues", "create", "-reserve-timeout", "1m", "-dead-timeout", "10m",
+			"-partitions", "1", "queue-01")
+		require.NoError(t, err)
+		out, err = cmd(t, "", "queues", "delete", "queue-01")
+		require.NoError(t, err)
+		assert.Equal(t, "queue 'queue-01' deleted\n", out)
+	})
+
+	t.Run("ProduceReserveComplete", func(t *testing.T) {
+		out, err := cmd(t, "one\ntwo\n\nthree\n", "queue", "produce", "-kind", "cli", "-batch-size", "2", "queue-00")
+		require.NoError(t, err)
+		assert.Equal(t, "produced 3 items to queue 'queue-00'\n", out)
+
+		out, err = cmd(t, "", "-output", "json", "queue", "stats", "queue-00")
+		require.NoError(t, err)
+		var stats pb.QueueStatsResponse
+		require.NoError(t, protojson.Unmarshal([]byte(out), &stats))
+		assert.Equal(t, int32(3), stats.Total)
+
+		// Complete the first item interactively, leave the second reserved
+		out, err = cmd(t, "y\nn\n", "-output", "json", "queue", "reserve", "-batch-size", "2",
+			"-interactive", "queue-00")
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		require.Len(t, lines, 3)
+		var first, second pb.QueueReserveItem
+		require.NoError(t, protojson.Unmarshal([]byte(lines[0]), &first))
+		require.NoError(t, protojson.Unmarshal([]byte(lines[1]), &second))
+		assert.Equal(t, "one", string(first.Bytes))
+		assert.Equal(t, "cli", first.Kind)
+		var msg map[string]string
+		require.NoError(t, json.Unmarshal([]byte(lines[2]), &msg))
+		assert.Equal(t, "completed 1 of 2 items", msg["message"])
+
+		out, err = cmd(t, "", "queue", "extend", "-reserve-timeout", "5m", "queue-00", second.Id)
+		require.NoError(t, err)
+		assert.Contains(t, out, "extended 1 items until ")
+
+		// A released item keeps its place in the queue
+		out, err = cmd(t, "", "queue", "release", "queue-00", second.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "released 1 items\n", out)
+
+		out, err = cmd(t, "", "-output", "json", "queue", "peek", "-batch-size", "1", "queue-00")
+		require.NoError(t, err)
+		var peeked pb.QueueItem
+		require.NoError(t, protojson.Unmarshal([]byte(out), &peeked))
+		assert.Equal(t, second.Id, peeked.Id)
+		assert.False(t, peeked.IsReserved)
+
+		out, err = cmd(t, "", "queue", "get", "queue-00", second.Id)
+		require.NoError(t, err)
+		assert.Contains(t, out, second.Id)
+
+		out, err = cmd(t, "", "-output", "json", "queue", "reserve", "queue-00")
+		require.NoError(t, err)
+		var again pb.QueueReserveItem
+		require.NoError(t, protojson.Unmarshal([]byte(out), &again))
+		assert.Equal(t, second.Id, again.Id)
+		assert.Equal(t, int32(1), again.Attempts)
+
+		out, err = cmd(t, "", "queue", "complete", "queue-00", second.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "completed 1 items\n", out)
+
+		out, err = cmd(t, "", "queue", "complete", "-detailed", "queue-00", second.Id)
+		require.NoError(t, err)
+		assert.Contains(t, out, "COMPLETE_NOT_FOUND")
+
+		// No consumers have registered with the queue
+		out, err = cmd(t, "", "queue", "consumers", "queue-00")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(out, "CLIENT ID"))
+		assert.Len(t, strings.Split(strings.TrimSpace(out), "\n"), 1)
+
+		out, err = cmd(t, "", "queue", "stats", "queue-00")
+		require.NoError(t, err)
+		assert.Contains(t, out, "Total ")
+		assert.Regexp(t, `Total\s+1\n`, out)
+	})
+
+	t.Run("Storage", func(t *testing.T) {
+		out, err := cmd(t, "", "-output", "json", "storage", "list", "queue-00")
+		require.NoError(t, err)
+		var item pb.StorageQueueItem
+		require.NoError(t, protojson.Unmarshal([]byte(out), &item))
+		assert.Equal(t, "three", string(item.Payload))
+
+		_, err = cmd(t, "", "storage", "delete", "queue-00", item.Id)
+		require.NoError(t, err)
+
+		// Items listed as json can be added back to storage
+		item.Id = ""
+		b, err := protojson.Marshal(&item)
+		require.NoError(t, err)
+		out, err = cmd(t, string(b)+"\n", "storage", "add", "queue-00")
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		require.Len(t, lines, 2)
+
+		_, err = cmd(t, "", "queue", "clear", "-destructive", "queue-00")
+		require.NoError(t, err)
+		out, err = cmd(t, "", "storage", "list", "queue-00")
+		require.NoError(t, err)
+		assert.Len(t, strings.Split(strings.TrimSpace(out), "\n"), 1)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := cmd(t, "", "queue", "stats", "does-not-exist")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not exist")
+
+		_, err = cmd(t, "", "queue", "unknown")
+		require.EqualError(t, err, "unknown command 'queue unknown'")
+
+		_, err = cmd(t, "", "-output", "yaml", "queues", "list")
+		require.Error(t, err)
+
+		_, err = cmd(t, "", "queue", "complete", "queue-00")
+		require.EqualError(t, err, "missing required arguments")
+
+		// The daemon was not started from a config file
+		_, err = cmd(t, "", "admin", "reload")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "reload failed; no config loader configured")
+	})
+
+	// Drain must be last, as the daemon no longer accepts produce or reserve requests
+	t.Run("Drain", func(t *testing.T) {
+		out, err := cmd(t, "", "admin", "drain-status")
+		require.NoError(t, err)
+		assert.Contains(t, out, "DRAINING")
+		assert.Contains(t, out, "false")
+
+		out, err = cmd(t, "", "-output", "json", "admin", "drain", "-wait")
+		require.NoError(t, err)
+		assert.Contains(t, out, `"drained":true`)
+
+		_, err = cmd(t, "one\n", "queue", "produce", "queue-00")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), que.MsgServiceDraining)
+	})
+}
+
+func TestServer(t *testing.T) {
+	t.Setenv("QUERATOR_LISTEN_ADDRESS", "localhost:0")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var stderr bytes.Buffer
+	done := make(chan error)
+	go func() {
+		done <- run(ctx, []string{"server"}, strings.NewReader(""), &bytes.Buffer{}, &stderr)
+	}()
+
+	// Cancelling the context is equivalent to receiving SIGTERM
+	clock.Sleep(100 * clock.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-clock.After(5 * clock.Second):
+		t.Fatal("server did not shut down")
+	}
+	assert.Contains(t, stderr.String(), "HTTP Listening")
+	assert.Contains(t, stderr.String(), "Shutting down querator")
+
+	err := run(context.Background(), []string{"server", "-config", "does-not-exist.yaml"},
+		strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{})
+	require.ErrorContains(t, err, "while reading config file")
+}
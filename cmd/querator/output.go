@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+)
+
+// output writes results in either table or json format. In json format each result is written
+// as a single json object per line, such that lists can be streamed and processed with tools like `jq`.
+type output struct {
+	format string
+	w      io.Writer
+}
+
+func newOutput(w io.Writer, format string) (*output, error) {
+	switch format {
+	case FormatTable, FormatJSON:
+		return &output{format: format, w: w}, nil
+	}
+	return nil, fmt.Errorf("invalid output format '%s'; must be one of '%s' or '%s'",
+		format, FormatTable, FormatJSON)
+}
+
+// message writes a status message for commands which do not return a result
+func (o *output) message(format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	if o.format == FormatJSON {
+		b, err := json.Marshal(map[string]string{"message": msg})
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(o.w, string(b))
+		return err
+	}
+	_, err := fmt.Fprintln(o.w, msg)
+	return err
+}
+
+// table returns a table which writes rows of the provided columns. In json format each row is
+// written as the proto.Message provided to table.row()
+func (o *output) table(columns ...string) *table {
+	t := &table{out: o}
+	if o.format == FormatTable {
+		t.tw = tabwriter.NewWriter(o.w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(t.tw, strings.Join(columns, "\t"))
+	}
+	return t
+}
+
+type table struct {
+	tw  *tabwriter.Writer
+	out *output
+	err error
+}
+
+func (t *table) row(m proto.Message, values ...any) {
+	if t.err != nil {
+		return
+	}
+	if t.tw == nil {
+		t.err = t.out.json(m)
+		return
+	}
+	cells := make([]string, len(values))
+	for i, v := range values {
+		cells[i] = cell(v)
+	}
+	_, t.err = fmt.Fprintln(t.tw, strings.Join(cells, "\t"))
+}
+
+func (t *table) flush() error {
+	if t.err != nil {
+		return t.err
+	}
+	if t.tw != nil {
+		return t.tw.Flush()
+	}
+	return nil
+}
+
+func (o *output) json(m proto.Message) error {
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(o.w, string(b))
+	return err
+}
+
+func cell(v any) string {
+	switch t := v.(type) {
+	case string:
+		if t == "" {
+			return "-"
+		}
+		return t
+	case *timestamppb.Timestamp:
+		if t == nil || (t.Seconds == 0 && t.Nanos == 0) {
+			return "-"
+		}
+		return t.AsTime().Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
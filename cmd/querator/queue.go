@@ -0,0 +1,355 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	pb "github.com/kapetan-io/querator/proto"
+)
+
+func queueProduce(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("queue produce", flag.ContinueOnError)
+	file := flags.String("file", "", "read items from the file instead of stdin")
+	single := flags.Bool("single", false, "produce the entire input as a single item instead of one item per line")
+	batchSize := flags.Int("batch-size", 1_000, "the maximum number of items produced per request")
+	var item pb.QueueProduceItem
+	flags.StringVar(&item.Kind, "kind", "", "the kind assigned to each item")
+	flags.StringVar(&item.Encoding, "encoding", "", "the encoding assigned to each item")
+	flags.StringVar(&item.Reference, "reference", "", "the reference assigned to each item")
+	flags.Func("priority", "the priority assigned to each item, from 0 (lowest) to 3", int32Flag(&item.Priority))
+	flags.StringVar(&item.OrderingKey, "ordering-key", "", "the ordering key assigned to each item")
+	flags.StringVar(&item.ExpireTimeout, "expire-timeout", "", "how long each item is useful for (e.g. '1m')")
+	if err := c.parse(flags, args, 1, "queue produce [flags] <queue>"); err != nil {
+		return err
+	}
+
+	in, closer, err := c.input(*file)
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	req := pb.QueueProduceRequest{
+		RequestTimeout: c.timeout.String(),
+		QueueName:      flags.Arg(0),
+	}
+	var total int
+	flush := func() error {
+		if len(req.Items) == 0 {
+			return nil
+		}
+		if err := c.client.QueueProduce(ctx, &req); err != nil {
+			return fmt.Errorf("after producing %d items: %w", total, err)
+		}
+		total += len(req.Items)
+		req.Items = nil
+		return nil
+	}
+
+	add := func(b []byte) {
+		req.Items = append(req.Items, &pb.QueueProduceItem{
+			ExpireTimeout: item.ExpireTimeout,
+			Encoding:      item.Encoding,
+			Reference:     item.Reference,
+			Kind:          item.Kind,
+			Priority:      item.Priority,
+			OrderingKey:   item.OrderingKey,
+			Bytes:         b,
+		})
+	}
+
+	if *single {
+		b, err := io.ReadAll(in)
+		if err != nil {
+			return err
+		}
+		add(b)
+	} else {
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(nil, 10*1024*1024)
+		for scanner.Scan() {
+			if len(scanner.Bytes()) == 0 {
+				continue
+			}
+			add(append([]byte(nil), scanner.Bytes()...))
+			if len(req.Items) >= *batchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+	return c.out.message("produced %d items to queue '%s'", total, flags.Arg(0))
+}
+
+func queueReserve(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("queue reserve", flag.ContinueOnError)
+	batchSize := flags.Int("batch-size", 1, "the number of items to reserve")
+	clientID := flags.String("client-id", "", "the client id used to reserve items (defaults to the hostname)")
+	interactive := flags.Bool("interactive", false, "prompt to complete each item after it is reserved")
+	if err := c.parse(flags, args, 1, "queue reserve [flags] <queue>"); err != nil {
+		return err
+	}
+	if *clientID == "" {
+		host, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("while determining the client id; provide -client-id: %w", err)
+		}
+		*clientID = fmt.Sprintf("querator-cli-%s", host)
+	}
+
+	var res pb.QueueReserveResponse
+	if err := c.client.QueueReserve(ctx, &pb.QueueReserveRequest{
+		RequestTimeout: c.timeout.String(),
+		BatchSize:      int32(*batchSize),
+		QueueName:      flags.Arg(0),
+		ClientId:       *clientID,
+	}, &res); err != nil {
+		return err
+	}
+
+	t := c.out.table("ID", "ATTEMPTS", "RESERVE DEADLINE", "KIND", "ENCODING", "REFERENCE", "PAYLOAD")
+	for _, item := range res.Items {
+		t.row(item, item.Id, item.Attempts, item.ReserveDeadline, item.Kind, item.Encoding,
+			item.Reference, string(item.Bytes))
+	}
+	if err := t.flush(); err != nil {
+		return err
+	}
+
+	if !*interactive || len(res.Items) == 0 {
+		return nil
+	}
+
+	// Prompts are written to stderr such that stdout remains parsable when the output format is json
+	var ids []string
+	scanner := bufio.NewScanner(c.stdin)
+	for _, item := range res.Items {
+		_, _ = fmt.Fprintf(c.stderr, "complete item '%s'? [y/N]: ", item.Id)
+		if !scanner.Scan() {
+			break
+		}
+		if answer := strings.ToLower(strings.TrimSpace(scanner.Text())); answer == "y" || answer == "yes" {
+			ids = append(ids, item.Id)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		return c.out.message("no items completed; items will be offered again after the reserve deadline")
+	}
+	if err := c.client.QueueComplete(ctx, &pb.QueueCompleteRequest{
+		RequestTimeout: c.timeout.String(),
+		QueueName:      flags.Arg(0),
+		Ids:            ids,
+	}); err != nil {
+		return err
+	}
+	return c.out.message("completed %d of %d items", len(ids), len(res.Items))
+}
+
+func queueComplete(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("queue complete", flag.ContinueOnError)
+	detailed := flags.Bool("detailed", false, "complete each id independently and show the outcome of each")
+	if err := c.parse(flags, args, 2, "queue complete [flags] <queue> <id>..."); err != nil {
+		return err
+	}
+
+	if *detailed {
+		var res pb.QueueCompleteResponse
+		if err := c.client.QueueCompleteDetailed(ctx, &pb.QueueCompleteRequest{
+			RequestTimeout: c.timeout.String(),
+			QueueName:      flags.Arg(0),
+			Ids:            flags.Args()[1:],
+		}, &res); err != nil {
+			return err
+		}
+		t := c.out.table("ID", "STATUS", "MESSAGE")
+		for _, result := range res.Results {
+			t.row(result, result.Id, result.Status.String(), result.Message)
+		}
+		return t.flush()
+	}
+
+	if err := c.client.QueueComplete(ctx, &pb.QueueCompleteRequest{
+		RequestTimeout: c.timeout.String(),
+		QueueName:      flags.Arg(0),
+		Ids:            flags.Args()[1:],
+	}); err != nil {
+		return err
+	}
+	return c.out.message("completed %d items", flags.NArg()-1)
+}
+
+func queueExtend(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("queue extend", flag.ContinueOnError)
+	reserveTimeout := flags.String("reserve-timeout", "", "how long to extend the reservation by; "+
+		"defaults to the reserve timeout of the queue")
+	if err := c.parse(flags, args, 2, "queue extend <queue> <id>..."); err != nil {
+		return err
+	}
+
+	var res pb.QueueExtendResponse
+	if err := c.client.QueueExtend(ctx, &pb.QueueExtendRequest{
+		RequestTimeout: c.timeout.String(),
+		ReserveTimeout: *reserveTimeout,
+		QueueName:      flags.Arg(0),
+		Ids:            flags.Args()[1:],
+	}, &res); err != nil {
+		return err
+	}
+	return c.out.message("extended %d items until %s", flags.NArg()-1,
+		res.ReserveDeadline.AsTime().Format(time.RFC3339))
+}
+
+func queueRelease(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("queue release", flag.ContinueOnError)
+	skipAttempt := flags.Bool("skip-attempt", false, "do not count the release as an attempt to process the items")
+	if err := c.parse(flags, args, 2, "queue release <queue> <id>..."); err != nil {
+		return err
+	}
+
+	if err := c.client.QueueRelease(ctx, &pb.QueueReleaseRequest{
+		RequestTimeout: c.timeout.String(),
+		QueueName:      flags.Arg(0),
+		Ids:            flags.Args()[1:],
+		SkipAttempt:    *skipAttempt,
+	}); err != nil {
+		return err
+	}
+	return c.out.message("released %d items", flags.NArg()-1)
+}
+
+func queuePeek(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("queue peek", flag.ContinueOnError)
+	batchSize := flags.Int("batch-size", 10, "the maximum number of items to show")
+	if err := c.parse(flags, args, 1, "queue peek [flags] <queue>"); err != nil {
+		return err
+	}
+
+	var res pb.QueuePeekResponse
+	if err := c.client.QueuePeek(ctx, &pb.QueuePeekRequest{
+		BatchSize: int32(*batchSize),
+		QueueName: flags.Arg(0),
+	}, &res); err != nil {
+		return err
+	}
+	return writeQueueItems(c, res.Items)
+}
+
+func queueGet(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("queue get", flag.ContinueOnError)
+	if err := c.parse(flags, args, 2, "queue get <queue> <id>..."); err != nil {
+		return err
+	}
+
+	var res pb.QueueGetResponse
+	if err := c.client.QueueGet(ctx, &pb.QueueGetRequest{
+		QueueName: flags.Arg(0),
+		Ids:       flags.Args()[1:],
+	}, &res); err != nil {
+		return err
+	}
+	return writeQueueItems(c, res.Items)
+}
+
+func queueConsumers(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("queue consumers", flag.ContinueOnError)
+	if err := c.parse(flags, args, 1, "queue consumers <queue>"); err != nil {
+		return err
+	}
+
+	var res pb.QueueConsumersResponse
+	if err := c.client.QueueConsumers(ctx, &pb.QueueConsumersRequest{QueueName: flags.Arg(0)}, &res); err != nil {
+		return err
+	}
+
+	t := c.out.table("CLIENT ID", "RESERVED", "HEARTBEAT TIMEOUT", "LAST ACTIVITY", "HEARTBEAT DEADLINE")
+	for _, con := range res.Consumers {
+		t.row(con, con.ClientId, con.Reserved, con.HeartbeatTimeout, con.LastActivity, con.HeartbeatDeadline)
+	}
+	return t.flush()
+}
+
+func writeQueueItems(c *cli, items []*pb.QueueItem) error {
+	t := c.out.table("ID", "PRIORITY", "ATTEMPTS", "RESERVED", "RESERVE DEADLINE", "KIND", "ENCODING",
+		"REFERENCE", "PAYLOAD")
+	for _, item := range items {
+		t.row(item, item.Id, item.Priority, item.Attempts, item.IsReserved, item.ReserveDeadline, item.Kind,
+			item.Encoding, item.Reference, string(item.Bytes))
+	}
+	return t.flush()
+}
+
+func queueStats(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("queue stats", flag.ContinueOnError)
+	if err := c.parse(flags, args, 1, "queue stats <queue>"); err != nil {
+		return err
+	}
+
+	var res pb.QueueStatsResponse
+	if err := c.client.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: flags.Arg(0)}, &res); err != nil {
+		return err
+	}
+
+	if c.out.format == FormatJSON {
+		return c.out.json(&res)
+	}
+	t := c.out.table("STAT", "VALUE")
+	t.row(nil, "Total", res.Total)
+	t.row(nil, "TotalReserved", res.TotalReserved)
+	t.row(nil, "TotalBytes", res.TotalBytes)
+	t.row(nil, "TotalExpired", res.TotalExpired)
+	t.row(nil, "AverageAge", res.AverageAge)
+	t.row(nil, "AverageReservedAge", res.AverageReservedAge)
+	t.row(nil, "ProduceWaiting", res.ProduceWaiting)
+	t.row(nil, "ReserveWaiting", res.ReserveWaiting)
+	t.row(nil, "CompleteWaiting", res.CompleteWaiting)
+	t.row(nil, "ReserveBlocked", res.ReserveBlocked)
+	t.row(nil, "InFlight", res.InFlight)
+	return t.flush()
+}
+
+func queueClear(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("queue clear", flag.ContinueOnError)
+	destructive := flags.Bool("destructive", false, "also remove items which are currently reserved")
+	if err := c.parse(flags, args, 1, "queue clear [flags] <queue>"); err != nil {
+		return err
+	}
+
+	if err := c.client.QueueClear(ctx, &pb.QueueClearRequest{
+		QueueName:   flags.Arg(0),
+		Destructive: *destructive,
+		Queue:       true,
+	}); err != nil {
+		return err
+	}
+	return c.out.message("queue '%s' cleared", flags.Arg(0))
+}
+
+// input returns the file provided, or stdin if no file was provided
+func (c *cli) input(file string) (io.Reader, func(), error) {
+	if file == "" || file == "-" {
+		return c.stdin, func() {}, nil
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, func() { _ = f.Close() }, nil
+}
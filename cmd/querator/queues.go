@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strings"
+
+	que "github.com/kapetan-io/querator"
+	pb "github.com/kapetan-io/querator/proto"
+)
+
+func queuesList(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("queues list", flag.ContinueOnError)
+	limit := flags.Int("limit", 100, "the number of queues requested per page")
+	pivot := flags.String("pivot", "", "the name of the queue to start listing from")
+	if err := c.parse(flags, args, 0, "queues list [flags]"); err != nil {
+		return err
+	}
+
+	t := c.out.table("NAME", "PARTITIONS", "RESERVE TIMEOUT", "DEAD TIMEOUT", "MAX ATTEMPTS",
+		"DEAD QUEUE", "CREATED")
+	it := c.client.QueuesListIter(&que.ListOptions{Limit: *limit, Pivot: *pivot})
+	for it.Next(ctx) {
+		q := it.Value()
+		t.row(q, q.QueueName, q.Partitions, q.ReserveTimeout, q.DeadTimeout, q.MaxAttempts,
+			q.DeadQueue, q.CreatedAt)
+	}
+	if err := t.flush(); err != nil {
+		return err
+	}
+	return it.Err()
+}
+
+// queueInfoFlags registers the flags used to create or update a queue
+func queueInfoFlags(flags *flag.FlagSet) *pb.QueueInfo {
+	var info pb.QueueInfo
+	flags.StringVar(&info.ReserveTimeout, "reserve-timeout", "", "how long a reservation is valid (e.g. '1m')")
+	flags.StringVar(&info.DeadTimeout, "dead-timeout", "", "how long an item can exist in the queue (e.g. '24h')")
+	flags.StringVar(&info.DeadQueue, "dead-queue", "", "the queue items are moved to when they expire")
+	flags.StringVar(&info.Reference, "reference", "", "a user supplied reference for the queue")
+	flags.Func("max-attempts", "the maximum number of times an item can be reserved", int32Flag(&info.MaxAttempts))
+	flags.Func("partitions", "the number of partitions the queue has", int32Flag(&info.Partitions))
+	flags.Func("max-item-size", "the maximum size of an item payload in bytes", int32Flag(&info.MaxItemSize))
+	flags.StringVar(&info.DedupWindow, "dedup-window", "", "how long the dedup id of an item is remembered (e.g. '5m')")
+	flags.StringVar(&info.ExpireTimeout, "expire-timeout", "", "how long an item is useful for before it expires (e.g. '1m')")
+	flags.Func("priority-weights", "the weight of each priority level starting with priority 0 (e.g. '1,2,4,8')",
+		func(s string) error {
+			info.PriorityWeights = nil
+			for _, w := range strings.Split(s, ",") {
+				var weight int32
+				if err := int32Flag(&weight)(strings.TrimSpace(w)); err != nil {
+					return err
+				}
+				info.PriorityWeights = append(info.PriorityWeights, weight)
+			}
+			return nil
+		})
+	return &info
+}
+
+func queuesCreate(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("queues create", flag.ContinueOnError)
+	info := queueInfoFlags(flags)
+	if err := c.parse(flags, args, 1, "queues create [flags] <queue>"); err != nil {
+		return err
+	}
+	info.QueueName = flags.Arg(0)
+
+	if err := c.client.QueuesCreate(ctx, info); err != nil {
+		return err
+	}
+	return c.out.message("queue '%s' created", info.QueueName)
+}
+
+func queuesUpdate(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("queues update", flag.ContinueOnError)
+	info := queueInfoFlags(flags)
+	if err := c.parse(flags, args, 1, "queues update [flags] <queue>"); err != nil {
+		return err
+	}
+	info.QueueName = flags.Arg(0)
+
+	if err := c.client.QueuesUpdate(ctx, info); err != nil {
+		return err
+	}
+	return c.out.message("queue '%s' updated", info.QueueName)
+}
+
+func queuesDelete(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("queues delete", flag.ContinueOnError)
+	force := flags.Bool("force", false, "delete the queue even if it contains items")
+	if err := c.parse(flags, args, 1, "queues delete [flags] <queue>"); err != nil {
+		return err
+	}
+
+	if err := c.client.QueuesDelete(ctx, &pb.QueuesDeleteRequest{
+		QueueName: flags.Arg(0),
+		Force:     *force,
+	}); err != nil {
+		return err
+	}
+	return c.out.message("queue '%s' deleted", flags.Arg(0))
+}
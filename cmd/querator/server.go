@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/kapetan-io/querator/daemon"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+)
+
+const serverUsage = `Usage:
+  querator server [flags]
+
+Runs the Querator server until SIGTERM or SIGINT is received. The server is configured with
+a YAML config file, values in the file can be overridden by QUERATOR_* environment variables.
+See config.yaml in the repository for an example.
+
+On SIGTERM or SIGINT the server first drains, rejecting new produce and reserve requests while
+in flight requests complete, for up to -drain-timeout before shutting down.
+
+On SIGHUP the config file is reloaded, and changes to limits and timeouts along with newly
+added storage backends are applied without a restart.
+
+Flags:
+`
+
+// server runs the querator daemon until the context is cancelled
+func server(ctx context.Context, args []string, stderr io.Writer) error {
+	flags := flag.NewFlagSet("server", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, serverUsage)
+		flags.PrintDefaults()
+	}
+	config := flags.String("config", os.Getenv("QUERATOR_CONFIG"),
+		"the path to the YAML config file (env QUERATOR_CONFIG)")
+	drainTimeout := flags.Duration("drain-timeout", 30*clock.Second,
+		"how long to wait for the server to drain before shutdown, 0 disables draining")
+	shutdownTimeout := flags.Duration("shutdown-timeout", 30*clock.Second,
+		"how long to wait for in flight requests to complete during shutdown")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	conf, err := daemon.LoadConfigFile(*config)
+	if err != nil {
+		return err
+	}
+	log := slog.New(slog.NewTextHandler(stderr, nil))
+	conf.Logger = log
+
+	d, err := daemon.NewDaemon(ctx, conf)
+	if err != nil {
+		return fmt.Errorf("while starting server: %w", err)
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for done := false; !done; {
+		select {
+		case <-hup:
+			log.Info("Reloading config", "file", *config)
+			if err := d.Service().Reload(ctx); err != nil {
+				log.Error("while reloading config", "error", err)
+			}
+		case <-ctx.Done():
+			done = true
+		}
+	}
+
+	if *drainTimeout > 0 {
+		drain(d, *drainTimeout, log)
+	}
+	log.Info("Shutting down querator", "timeout", shutdownTimeout.String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	if err := d.Shutdown(ctx); err != nil {
+		return fmt.Errorf("while shutting down server: %w", err)
+	}
+	return nil
+}
+
+// drain places the server into drain mode and waits until it has drained or the timeout is reached
+func drain(d *daemon.Daemon, timeout clock.Duration, log *slog.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	log.Info("Draining querator", "timeout", timeout.String())
+	var res pb.AdminDrainResponse
+	if err := d.Service().Drain(ctx, &res); err != nil {
+		log.Error("while draining", "error", err)
+		return
+	}
+
+	for !res.Drained {
+		select {
+		case <-clock.After(100 * clock.Millisecond):
+		case <-ctx.Done():
+			log.Warn("drain timeout reached", "in_flight", res.InFlight)
+			return
+		}
+		if err := d.Service().DrainStatus(ctx, &res); err != nil {
+			log.Error("while checking drain status", "error", err)
+			return
+		}
+	}
+	log.Info("Querator drained", "reserved", res.Reserved)
+}
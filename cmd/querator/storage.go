@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+
+	que "github.com/kapetan-io/querator"
+	pb "github.com/kapetan-io/querator/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func storageList(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("storage list", flag.ContinueOnError)
+	limit := flags.Int("limit", 100, "the number of items requested per page")
+	pivot := flags.String("pivot", "", "the id of the item to start listing from")
+	if err := c.parse(flags, args, 1, "storage list [flags] <queue>"); err != nil {
+		return err
+	}
+
+	t := c.out.table("ID", "RESERVED", "ATTEMPTS", "RESERVE DEADLINE", "DEAD DEADLINE", "CREATED",
+		"KIND", "REFERENCE")
+	it := c.client.StorageQueueListIter(flags.Arg(0), &que.ListOptions{Limit: *limit, Pivot: *pivot})
+	for it.Next(ctx) {
+		item := it.Value()
+		t.row(item, item.Id, item.IsReserved, item.Attempts, item.ReserveDeadline, item.DeadDeadline,
+			item.CreatedAt, item.Kind, item.Reference)
+	}
+	if err := t.flush(); err != nil {
+		return err
+	}
+	return it.Err()
+}
+
+func storageAdd(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("storage add", flag.ContinueOnError)
+	file := flags.String("file", "", "read items from the file instead of stdin")
+	if err := c.parse(flags, args, 1, "storage add [flags] <queue>\n\n"+
+		"Each line of input is a json encoded StorageQueueItem as output by `storage list -output json`"); err != nil {
+		return err
+	}
+
+	in, closer, err := c.input(*file)
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	req := pb.StorageQueueAddRequest{QueueName: flags.Arg(0)}
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, 10*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var item pb.StorageQueueItem
+		if err := protojson.Unmarshal(scanner.Bytes(), &item); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		req.Items = append(req.Items, &item)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	var res pb.StorageQueueAddResponse
+	if err := c.client.StorageQueueAdd(ctx, &req, &res); err != nil {
+		return err
+	}
+
+	t := c.out.table("ID", "CREATED", "KIND", "REFERENCE")
+	for _, item := range res.Items {
+		t.row(item, item.Id, item.CreatedAt, item.Kind, item.Reference)
+	}
+	return t.flush()
+}
+
+func storageDelete(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("storage delete", flag.ContinueOnError)
+	if err := c.parse(flags, args, 2, "storage delete <queue> <id>..."); err != nil {
+		return err
+	}
+
+	if err := c.client.StorageQueueDelete(ctx, &pb.StorageQueueDeleteRequest{
+		QueueName: flags.Arg(0),
+		Ids:       flags.Args()[1:],
+	}); err != nil {
+		return err
+	}
+	return c.out.message("deleted %d items", flags.NArg()-1)
+}
@@ -0,0 +1,269 @@
+package querator_test
+
+import (
+	"context"
+	"fmt"
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/daemon"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	noEnv := func(string) (string, bool) { return "", false }
+	env := func(vars map[string]string) func(string) (string, bool) {
+		return func(key string) (string, bool) {
+			v, ok := vars[key]
+			return v, ok
+		}
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		conf, err := daemon.LoadConfig(strings.NewReader(""), noEnv)
+		require.NoError(t, err)
+		assert.Equal(t, daemon.DefaultListenAddress, conf.ListenAddress)
+		assert.Nil(t, conf.TLS)
+		require.Len(t, conf.StorageConfig.Backends, 1)
+		assert.IsType(t, &store.MemoryQueueStore{}, conf.StorageConfig.QueueStore)
+	})
+
+	t.Run("Backends", func(t *testing.T) {
+		dir := t.TempDir()
+		conf, err := daemon.LoadConfig(strings.NewReader(fmt.Sprintf(`
+listen-address: localhost:0
+instance-id: test-01
+max-produce-batch-size: 10
+max-reserve-batch-size: 20
+write-timeout: 2s
+read-timeout: 3s
+queue-store:
+  driver: BoltDB
+  config:
+    storage-dir: %s
+backends:
+  - name: bolt-00
+    driver: boltdb
+    affinity: 1
+    config:
+      storage-dir: %s
+  - name: memory-00
+    driver: InMemory
+`, dir, dir)), noEnv)
+		require.NoError(t, err)
+		assert.Equal(t, "test-01", conf.InstanceID)
+		assert.Equal(t, 10, conf.MaxProduceBatchSize)
+		assert.Equal(t, 20, conf.MaxReserveBatchSize)
+		assert.Equal(t, 2*clock.Second, conf.WriteTimeout)
+		assert.Equal(t, 3*clock.Second, conf.ReadTimeout)
+		require.Len(t, conf.StorageConfig.Backends, 2)
+		assert.Equal(t, "bolt-00", conf.StorageConfig.Backends[0].Name)
+		assert.IsType(t, &store.BoltPartitionStore{}, conf.StorageConfig.Backends[0].PartitionStore)
+		assert.Equal(t, float64(0), conf.StorageConfig.Backends[1].Affinity)
+
+		// The daemon runs with the config loaded
+		ctx, cancel := context.WithTimeout(context.Background(), 10*clock.Second)
+		defer cancel()
+		d, err := daemon.NewDaemon(ctx, conf)
+		require.NoError(t, err)
+		defer func() { _ = d.Shutdown(context.Background()) }()
+
+		c := d.MustClient()
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      "queue-00",
+			ReserveTimeout: "1m",
+			DeadTimeout:    "10m",
+			Partitions:     1,
+		}))
+		err = c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      "queue-00",
+			RequestTimeout: "1m",
+			Items:          randomProduceItems(11),
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "items is invalid; max_produce_batch_size is")
+	})
+
+	t.Run("Environment", func(t *testing.T) {
+		conf, err := daemon.LoadConfig(strings.NewReader(`
+listen-address: localhost:2319
+max-item-size: 1024
+`), env(map[string]string{
+			"QUERATOR_LISTEN_ADDRESS": "localhost:0",
+			"QUERATOR_MAX_ITEM_SIZE":  "2048",
+			"QUERATOR_READ_TIMEOUT":   "1m",
+			"QUERATOR_TLS_AUTO_TLS":   "true",
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "localhost:0", conf.ListenAddress)
+		assert.Equal(t, 2048, conf.MaxItemSize)
+		assert.Equal(t, clock.Minute, conf.ReadTimeout)
+		require.NotNil(t, conf.TLS)
+		assert.True(t, conf.TLS.AutoTLS)
+
+		// The server uses TLS
+		ctx, cancel := context.WithTimeout(context.Background(), 10*clock.Second)
+		defer cancel()
+		d, err := daemon.NewDaemon(ctx, conf)
+		require.NoError(t, err)
+		defer func() { _ = d.Shutdown(context.Background()) }()
+
+		var list pb.QueuesListResponse
+		require.NoError(t, d.MustClient().QueuesList(ctx, &list, nil))
+
+		_, err = daemon.LoadConfig(strings.NewReader(""), env(map[string]string{
+			"QUERATOR_MAX_ITEM_SIZE": "big",
+		}))
+		require.ErrorContains(t, err, "environment variable 'QUERATOR_MAX_ITEM_SIZE' is invalid")
+	})
+
+	t.Run("QuotasAndRateLimits", func(t *testing.T) {
+		conf, err := daemon.LoadConfig(strings.NewReader(`
+namespace-quotas:
+  team-a:
+    max-queues: 2
+    max-items: 100
+default-namespace-quota:
+  max-bytes: 1024
+rate-limits:
+  produce-per-queue:
+    rate: 20.5
+    burst: 10
+trace-exporter:
+  driver: stdout
+`), env(map[string]string{
+			"QUERATOR_DEFAULT_NAMESPACE_QUOTA_MAX_ITEMS":   "50",
+			"QUERATOR_RATE_LIMITS_RESERVE_PER_CLIENT_RATE": "5",
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]que.NamespaceQuota{"team-a": {MaxQueues: 2, MaxItems: 100}},
+			conf.NamespaceQuotas)
+		assert.Equal(t, que.NamespaceQuota{MaxItems: 50, MaxBytes: 1024}, conf.DefaultNamespaceQuota)
+		assert.Equal(t, que.RateLimit{Rate: 20.5, Burst: 10}, conf.RateLimits.ProducePerQueue)
+		assert.Equal(t, que.RateLimit{Rate: 5}, conf.RateLimits.ReservePerClient)
+		assert.NotNil(t, conf.TraceExporter)
+
+		// The environment replaces all the namespace quotas in the file
+		conf, err = daemon.LoadConfig(strings.NewReader(`
+namespace-quotas:
+  team-a:
+    max-queues: 2
+`), env(map[string]string{
+			"QUERATOR_NAMESPACE_QUOTAS": "{team-b: {max-items: 10}, team-c: {max-queues: 1}}",
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, map[string]que.NamespaceQuota{
+			"team-b": {MaxItems: 10},
+			"team-c": {MaxQueues: 1},
+		}, conf.NamespaceQuotas)
+		assert.Nil(t, conf.TraceExporter)
+
+		_, err = daemon.LoadConfig(strings.NewReader(""), env(map[string]string{
+			"QUERATOR_NAMESPACE_QUOTAS": "{team-b: {max-itemz: 10}}",
+		}))
+		require.ErrorContains(t, err, "environment variable 'QUERATOR_NAMESPACE_QUOTAS' is invalid")
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, test := range []struct {
+			name string
+			yaml string
+			errs []string
+		}{
+			{
+				name: "UnknownField",
+				yaml: "listen-adress: localhost:2319",
+				errs: []string{"field listen-adress not found"},
+			},
+			{
+				name: "Timeouts",
+				yaml: "write-timeout: 5\nread-timeout: -1s",
+				errs: []string{
+					"'write-timeout' is invalid; '5' must be a positive duration",
+					"'read-timeout' is invalid; '-1s' must be a positive duration",
+				},
+			},
+			{
+				name: "Limits",
+				yaml: "max-reserve-batch-size: -1",
+				errs: []string{"'max-reserve-batch-size' cannot be negative"},
+			},
+			{
+				name: "TLS",
+				yaml: "tls:\n  client-auth: always",
+				errs: []string{
+					"'tls.client-auth' is invalid; 'always' must be one of",
+					"'tls.cert-file' and 'tls.key-file' are required unless 'tls.auto-tls' is true",
+				},
+			},
+			{
+				name: "Backends",
+				yaml: `
+queue-store:
+  driver: Postgres
+backends:
+  - name: bolt-00
+    driver: BoltDB
+  - name: bolt-00
+    driver: BoltDB
+    config:
+      storage-dir: /does/not/exist
+  - driver: MongoDB
+    affinity: -1
+`,
+				errs: []string{
+					"'queue-store.driver' is invalid; 'Postgres' must be one of 'InMemory' or 'BoltDB'",
+					"'backends[0].config.storage-dir' is required for driver 'BoltDB'",
+					"'backends[1].name' is invalid; 'bolt-00' is already used by 'backends[0]'",
+					"'backends[1].config.storage-dir' is invalid",
+					"'backends[2].name' cannot be empty",
+					"'backends[2].affinity' cannot be negative",
+					"'backends[2].driver' is invalid; 'MongoDB' must be one of 'InMemory' or 'BoltDB'",
+				},
+			},
+			{
+				name: "QuotasAndRateLimits",
+				yaml: `
+namespace-quotas:
+  team-a:
+    max-items: -1
+default-namespace-quota:
+  max-queues: -1
+rate-limits:
+  complete-per-client:
+    burst: -1
+trace-exporter:
+  driver: jaeger
+`,
+				errs: []string{
+					"'namespace-quotas.team-a' cannot have negative limits",
+					"'default-namespace-quota' cannot have negative limits",
+					"'rate-limits.complete-per-client' cannot be negative",
+					"'trace-exporter.driver' is invalid; 'jaeger' must be one of 'none', 'stdout' or 'otlp'",
+				},
+			},
+		} {
+			t.Run(test.name, func(t *testing.T) {
+				_, err := daemon.LoadConfig(strings.NewReader(test.yaml), noEnv)
+				require.Error(t, err)
+				for _, e := range test.errs {
+					assert.ErrorContains(t, err, e)
+				}
+			})
+		}
+	})
+
+	t.Run("File", func(t *testing.T) {
+		_, err := daemon.LoadConfigFile("does-not-exist.yaml")
+		require.ErrorContains(t, err, "while reading config file")
+
+		// The example config in the repo is valid, except for the storage directory
+		_, err = daemon.LoadConfigFile("config.yaml")
+		require.ErrorContains(t, err, "config file 'config.yaml': invalid config: "+
+			"'queue-store.config.storage-dir' is invalid")
+	})
+}
@@ -0,0 +1,374 @@
+package querator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/duh-rpc/duh-go"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/set"
+	"github.com/segmentio/ksuid"
+	"log/slog"
+	"sync"
+)
+
+const (
+	DefaultConsumerBatchSize      = 10
+	DefaultConsumerRequestTimeout = 30 * clock.Second
+	DefaultConsumerRetryBackoff   = 500 * clock.Millisecond
+	DefaultConsumerRetryDelay     = 5 * clock.Second
+)
+
+// Handler is called by the Consumer for each item reserved. If the handler returns nil, the item
+// is marked as complete. If the handler returns an error, the item is not marked as complete and
+// will be offered to consumers again after ConsumerConfig.RetryDelay, or immediately if
+// ConsumerConfig.ReleaseOnError is true.
+//
+// The context provided expires at the reserve deadline of the item. If ConsumerConfig.ExtendInterval
+// is set, the reserve deadline is extended while the handler is running.
+type Handler func(ctx context.Context, item *pb.QueueReserveItem) error
+
+type ConsumerConfig struct {
+	// Client is the client used to reserve and complete items
+	Client *Client
+	// QueueName is the name of the queue to consume items from
+	QueueName string
+	// Handler is called for each item reserved from the queue
+	Handler Handler
+	// Concurrency is the number of workers which reserve and handle items. The default is 1.
+	Concurrency int
+	// BatchSize is the maximum number of items a single worker will reserve at a time. The
+	// default is 10.
+	BatchSize int
+	// ClientID is the prefix of the client id each worker uses to reserve items. Each worker
+	// is assigned the id `<ClientID>-<worker>` which is stable for the life of the Consumer.
+	// The default is a randomly generated id.
+	ClientID string
+	// RequestTimeout is how long a reserve request will wait for items to become available
+	// before the worker makes a new reserve request. The default is 30 seconds.
+	RequestTimeout clock.Duration
+	// RetryBackoff is how long a worker waits before retrying a request which failed. If the
+	// server provided a retry after hint, the worker waits for the hint instead.
+	// The default is 500 milliseconds.
+	RetryBackoff clock.Duration
+	// ExtendInterval if set, is how often the reservation of items which have not yet been completed is
+	// extended while the handler is running. This allows handlers to run longer than the reserve timeout
+	// of the queue. If the reservation cannot be extended, the context provided to the handler is
+	// cancelled when the reservation expires. The default is to never extend reservations.
+	ExtendInterval clock.Duration
+	// RetryDelay is how long an item for which the handler returned an error waits before it is offered
+	// to consumers again. The reservation of the item is released once RetryDelay has elapsed, or when
+	// the Consumer is shutdown. RetryDelay should be less than the reserve timeout of the queue, as the
+	// reservation cannot be released once it has expired. The default is 5 seconds.
+	RetryDelay clock.Duration
+	// ReleaseOnError if true, releases the reservation of items for which the handler returned an error
+	// instead of waiting for RetryDelay, such that the items are offered to consumers again immediately.
+	// The release is counted as an attempt to process the item.
+	ReleaseOnError bool
+	// Logger is used to log errors which occur while consuming. The default is slog.Default()
+	Logger duh.StandardLogger
+}
+
+// Consumer reserves items from a queue and calls the Handler for each item reserved. Items
+// for which the Handler returns nil are marked as complete.
+type Consumer struct {
+	// reserveCancel cancels any reserve request in flight, used during shutdown
+	reserveCancel context.CancelFunc
+	reserveCtx    context.Context
+	// workCancel cancels the context provided to handlers, used if shutdown times out
+	workCancel context.CancelFunc
+	workCtx    context.Context
+	conf       ConsumerConfig
+	wg         sync.WaitGroup
+}
+
+// NewConsumer creates a new Consumer and starts the workers consuming from the queue
+func NewConsumer(conf ConsumerConfig) (*Consumer, error) {
+	set.Default(&conf.Concurrency, 1)
+	set.Default(&conf.BatchSize, DefaultConsumerBatchSize)
+	set.Default(&conf.ClientID, ksuid.New().String())
+	set.Default(&conf.RequestTimeout, DefaultConsumerRequestTimeout)
+	set.Default(&conf.RetryBackoff, DefaultConsumerRetryBackoff)
+	set.Default(&conf.RetryDelay, DefaultConsumerRetryDelay)
+	set.Default(&conf.Logger, slog.Default())
+
+	if conf.Client == nil {
+		return nil, errors.New("conf.Client cannot be nil")
+	}
+	if conf.QueueName == "" {
+		return nil, errors.New("conf.QueueName cannot be empty")
+	}
+	if conf.Handler == nil {
+		return nil, errors.New("conf.Handler cannot be nil")
+	}
+
+	c := &Consumer{conf: conf}
+	c.reserveCtx, c.reserveCancel = context.WithCancel(context.Background())
+	c.workCtx, c.workCancel = context.WithCancel(context.Background())
+
+	for i := 0; i < conf.Concurrency; i++ {
+		c.wg.Add(1)
+		go c.worker(fmt.Sprintf("%s-%d", conf.ClientID, i))
+	}
+	return c, nil
+}
+
+// Shutdown stops the workers from reserving new items, and waits for all items already reserved to
+// be handled and completed. If the context is cancelled before the workers are done, the contexts
+// provided to the handlers are cancelled and Shutdown returns the context error.
+func (c *Consumer) Shutdown(ctx context.Context) error {
+	c.reserveCancel()
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		c.workCancel()
+		return nil
+	case <-ctx.Done():
+		c.workCancel()
+		return ctx.Err()
+	}
+}
+
+func (c *Consumer) worker(clientID string) {
+	defer c.wg.Done()
+
+	for {
+		var res pb.QueueReserveResponse
+		err := c.conf.Client.QueueReserve(c.reserveCtx, &pb.QueueReserveRequest{
+			RequestTimeout: c.conf.RequestTimeout.String(),
+			BatchSize:      int32(c.conf.BatchSize),
+			QueueName:      c.conf.QueueName,
+			ClientId:       clientID,
+		}, &res)
+		if c.reserveCtx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if isRequestTimeout(err) {
+				continue
+			}
+			c.conf.Logger.Error("while reserving items; retrying", "error", err,
+				"category", "consumer", "queueName", c.conf.QueueName, "clientId", clientID)
+			if !c.wait(c.reserveCtx, retryAfter(err, c.conf.RetryBackoff)) {
+				return
+			}
+			continue
+		}
+
+		c.handle(clientID, res.Items)
+	}
+}
+
+// handle calls the handler for each item and marks the items which were handled successfully as complete
+func (c *Consumer) handle(clientID string, items []*pb.QueueReserveItem) {
+	if len(items) == 0 {
+		return
+	}
+
+	ext := &extender{pending: CollectIDs(items)}
+	workCtx := c.workCtx
+	if c.conf.ExtendInterval != 0 {
+		var cancel context.CancelFunc
+		workCtx, cancel = context.WithCancel(c.workCtx)
+		done := make(chan struct{})
+		go func() {
+			c.extend(workCtx, cancel, clientID, ext, earliestDeadline(items))
+			close(done)
+		}()
+		defer func() {
+			cancel()
+			<-done
+		}()
+	}
+
+	var ids []string
+	for _, item := range items {
+		ctx, cancel := c.handlerContext(workCtx, item)
+		err := c.conf.Handler(ctx, item)
+		cancel()
+		if err != nil {
+			c.conf.Logger.Warn("handler returned an error; item will be retried", "error", err,
+				"category", "consumer", "queueName", c.conf.QueueName, "clientId", clientID, "id", item.Id)
+			// Items which failed are not extended, such that they can be retried as soon as possible
+			ext.remove(item.Id)
+			if c.conf.ReleaseOnError {
+				c.release(clientID, item.Id)
+			} else {
+				c.delay(clientID, item.Id)
+			}
+			continue
+		}
+		ids = append(ids, item.Id)
+	}
+
+	if len(ids) == 0 {
+		return
+	}
+
+	for {
+		err := c.conf.Client.QueueComplete(c.workCtx, &pb.QueueCompleteRequest{
+			RequestTimeout: c.conf.RequestTimeout.String(),
+			QueueName:      c.conf.QueueName,
+			Ids:            ids,
+		})
+		if err == nil {
+			return
+		}
+		if !isRetryRequest(err) {
+			c.conf.Logger.Error("while completing items", "error", err,
+				"category", "consumer", "queueName", c.conf.QueueName, "clientId", clientID)
+			return
+		}
+		if !c.wait(c.workCtx, retryAfter(err, c.conf.RetryBackoff)) {
+			return
+		}
+	}
+}
+
+// release releases the reservation of the item such that it is offered to consumers again immediately
+func (c *Consumer) release(clientID string, id string) {
+	for {
+		err := c.conf.Client.QueueRelease(c.workCtx, &pb.QueueReleaseRequest{
+			RequestTimeout: c.conf.RequestTimeout.String(),
+			QueueName:      c.conf.QueueName,
+			Ids:            []string{id},
+		})
+		if err == nil {
+			return
+		}
+		if !isRetryRequest(err) {
+			c.conf.Logger.Error("while releasing item", "error", err,
+				"category", "consumer", "queueName", c.conf.QueueName, "clientId", clientID, "id", id)
+			return
+		}
+		if !c.wait(c.workCtx, retryAfter(err, c.conf.RetryBackoff)) {
+			return
+		}
+	}
+}
+
+// delay releases the reservation of the item once RetryDelay has elapsed, such that it is offered to
+// consumers again at that time. If the Consumer is shutdown before RetryDelay has elapsed, the item is
+// released immediately.
+func (c *Consumer) delay(clientID string, id string) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		select {
+		case <-clock.After(c.conf.RetryDelay):
+		case <-c.reserveCtx.Done():
+		}
+		c.release(clientID, id)
+	}()
+}
+
+// handlerContext returns the context provided to the handler for the item
+func (c *Consumer) handlerContext(ctx context.Context, item *pb.QueueReserveItem) (context.Context,
+	context.CancelFunc) {
+	if c.conf.ExtendInterval != 0 {
+		// The parent context is cancelled by extend() if the reservation expires
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, item.ReserveDeadline.AsTime())
+}
+
+// extend extends the reservation of the pending items every ExtendInterval until the context is cancelled.
+// If the reservation expires before it could be extended, cancel is called.
+func (c *Consumer) extend(ctx context.Context, cancel context.CancelFunc, clientID string,
+	ext *extender, deadline clock.Time) {
+
+	extending := true
+	for {
+		wait := deadline.Sub(clock.Now())
+		if extending && c.conf.ExtendInterval < wait {
+			wait = c.conf.ExtendInterval
+		}
+		if !c.wait(ctx, wait) {
+			return
+		}
+		if !clock.Now().Before(deadline) {
+			c.conf.Logger.Warn("reservation expired before the handler returned", "category", "consumer",
+				"queueName", c.conf.QueueName, "clientId", clientID)
+			cancel()
+			return
+		}
+
+		ids := ext.ids()
+		if len(ids) == 0 {
+			extending = false
+			continue
+		}
+
+		var res pb.QueueExtendResponse
+		err := c.conf.Client.QueueExtend(ctx, &pb.QueueExtendRequest{
+			RequestTimeout: c.conf.RequestTimeout.String(),
+			QueueName:      c.conf.QueueName,
+			Ids:            ids,
+		}, &res)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			c.conf.Logger.Warn("while extending reservation", "error", err,
+				"category", "consumer", "queueName", c.conf.QueueName, "clientId", clientID)
+			// The reservation can no longer be extended, wait for it to expire
+			if !isRetryRequest(err) {
+				extending = false
+			}
+			continue
+		}
+		deadline = res.ReserveDeadline.AsTime()
+	}
+}
+
+// earliestDeadline returns the earliest reserve deadline of the items, as items produced with their
+// own reserve timeout may be reserved until different deadlines.
+func earliestDeadline(items []*pb.QueueReserveItem) clock.Time {
+	deadline := items[0].ReserveDeadline.AsTime()
+	for _, item := range items[1:] {
+		if d := item.ReserveDeadline.AsTime(); d.Before(deadline) {
+			deadline = d
+		}
+	}
+	return deadline
+}
+
+// extender tracks the ids of items which are still being handled and should have their reservation extended
+type extender struct {
+	mu      sync.Mutex
+	pending []string
+}
+
+func (e *extender) ids() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.pending...)
+}
+
+func (e *extender) remove(id string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i, p := range e.pending {
+		if p == id {
+			e.pending = append(e.pending[:i], e.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// wait blocks for the duration provided, returns false if the context was cancelled while waiting
+func (c *Consumer) wait(ctx context.Context, d clock.Duration) bool {
+	select {
+	case <-clock.After(d):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
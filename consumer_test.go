@@ -0,0 +1,264 @@
+package querator_test
+
+import (
+	"context"
+	"errors"
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+func TestConsumer(t *testing.T) {
+	bdb := boltTestSetup{Dir: t.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			testConsumer(t, tc.Setup, tc.TearDown)
+		})
+	}
+}
+
+func testConsumer(t *testing.T, setup NewStorageFunc, tearDown func()) {
+	_store := setup(clock.NewProvider())
+	defer tearDown()
+	d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store})
+	defer d.Shutdown(t)
+
+	createQueue := func(t *testing.T) string {
+		queueName := random.String("queue-", 10)
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      queueName,
+			ReserveTimeout: "1m",
+			DeadTimeout:    "10m",
+			Partitions:     1,
+		}))
+		return queueName
+	}
+
+	stats := func(t *testing.T, queueName string) *pb.QueueStatsResponse {
+		var stats pb.QueueStatsResponse
+		require.NoError(t, c.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: queueName}, &stats))
+		return &stats
+	}
+
+	t.Run("Consume", func(t *testing.T) {
+		queueName := createQueue(t)
+		produced := randomProduceItems(50)
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          produced,
+		}))
+
+		var mutex sync.Mutex
+		handled := make(map[string]int)
+		consumer, err := que.NewConsumer(que.ConsumerConfig{
+			Client:         c,
+			QueueName:      queueName,
+			Concurrency:    4,
+			BatchSize:      5,
+			ClientID:       "worker",
+			RequestTimeout: 100 * clock.Millisecond,
+			Logger:         log,
+			Handler: func(ctx context.Context, item *pb.QueueReserveItem) error {
+				mutex.Lock()
+				handled[string(item.Bytes)]++
+				mutex.Unlock()
+				return nil
+			},
+		})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			return stats(t, queueName).Total == 0
+		}, 5*clock.Second, 10*clock.Millisecond)
+		require.NoError(t, consumer.Shutdown(ctx))
+
+		// Every item was handled exactly once
+		require.Equal(t, len(produced), len(handled))
+		for _, item := range produced {
+			assert.Equal(t, 1, handled[string(item.Bytes)])
+		}
+	})
+
+	t.Run("HandlerError", func(t *testing.T) {
+		queueName := createQueue(t)
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          randomProduceItems(10),
+		}))
+
+		var mutex sync.Mutex
+		var count int
+		consumer, err := que.NewConsumer(que.ConsumerConfig{
+			Client:         c,
+			QueueName:      queueName,
+			RequestTimeout: 100 * clock.Millisecond,
+			Logger:         log,
+			Handler: func(ctx context.Context, item *pb.QueueReserveItem) error {
+				mutex.Lock()
+				defer mutex.Unlock()
+				count++
+				// Fail every other item
+				if count%2 == 0 {
+					return errors.New("failed")
+				}
+				return nil
+			},
+		})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			return stats(t, queueName).Total == 5
+		}, 5*clock.Second, 10*clock.Millisecond)
+		require.NoError(t, consumer.Shutdown(ctx))
+
+		// Items which failed are not completed, and are released when the consumer is shutdown
+		// instead of waiting for the retry delay
+		s := stats(t, queueName)
+		assert.Equal(t, int32(5), s.Total)
+		assert.Equal(t, int32(0), s.TotalReserved)
+	})
+
+	t.Run("RetryDelay", func(t *testing.T) {
+		queueName := createQueue(t)
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          randomProduceItems(1),
+		}))
+
+		// The item fails the first attempt, and is offered again once the retry delay has elapsed
+		// instead of after the reserve timeout of one minute
+		var mutex sync.Mutex
+		var handled []clock.Time
+		consumer, err := que.NewConsumer(que.ConsumerConfig{
+			Client:         c,
+			QueueName:      queueName,
+			RequestTimeout: 100 * clock.Millisecond,
+			RetryDelay:     500 * clock.Millisecond,
+			Logger:         log,
+			Handler: func(ctx context.Context, item *pb.QueueReserveItem) error {
+				mutex.Lock()
+				defer mutex.Unlock()
+				handled = append(handled, clock.Now())
+				if len(handled) == 1 {
+					return errors.New("failed")
+				}
+				return nil
+			},
+		})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			return stats(t, queueName).Total == 0
+		}, 5*clock.Second, 10*clock.Millisecond)
+		require.NoError(t, consumer.Shutdown(ctx))
+
+		require.Len(t, handled, 2)
+		assert.GreaterOrEqual(t, handled[1].Sub(handled[0]), 500*clock.Millisecond)
+	})
+
+	t.Run("Drain", func(t *testing.T) {
+		queueName := createQueue(t)
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          randomProduceItems(3),
+		}))
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		consumer, err := que.NewConsumer(que.ConsumerConfig{
+			Client:         c,
+			QueueName:      queueName,
+			BatchSize:      3,
+			RequestTimeout: 100 * clock.Millisecond,
+			Logger:         log,
+			Handler: func(ctx context.Context, item *pb.QueueReserveItem) error {
+				select {
+				case started <- struct{}{}:
+				default:
+				}
+				<-release
+				return nil
+			},
+		})
+		require.NoError(t, err)
+		<-started
+
+		done := make(chan error)
+		go func() {
+			done <- consumer.Shutdown(ctx)
+		}()
+
+		// Shutdown waits for the items already reserved to be handled
+		select {
+		case <-done:
+			t.Fatal("Shutdown() returned before in flight items were handled")
+		case <-clock.After(100 * clock.Millisecond):
+		}
+		close(release)
+		require.NoError(t, <-done)
+
+		// All items reserved before shutdown were completed
+		assert.Equal(t, int32(0), stats(t, queueName).Total)
+	})
+
+	t.Run("ShutdownTimeout", func(t *testing.T) {
+		queueName := createQueue(t)
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          randomProduceItems(1),
+		}))
+
+		started := make(chan struct{})
+		consumer, err := que.NewConsumer(que.ConsumerConfig{
+			Client:         c,
+			QueueName:      queueName,
+			RequestTimeout: 100 * clock.Millisecond,
+			Logger:         log,
+			Handler: func(ctx context.Context, item *pb.QueueReserveItem) error {
+				close(started)
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		})
+		require.NoError(t, err)
+		<-started
+
+		// The handler context is cancelled if the shutdown context expires before the handlers return
+		timeout, cancel := context.WithTimeout(ctx, 100*clock.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, consumer.Shutdown(timeout), context.DeadlineExceeded)
+	})
+}
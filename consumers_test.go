@@ -0,0 +1,298 @@
+package querator_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/duh-rpc/duh-go"
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumers(t *testing.T) {
+	bdb := boltTestSetup{Dir: t.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			testConsumers(t, tc.Setup, tc.TearDown)
+		})
+	}
+}
+
+func testConsumers(t *testing.T, setup NewStorageFunc, tearDown func()) {
+	cp := clock.NewProvider()
+	cp.Freeze(clock.Now())
+	_store := setup(cp)
+	defer tearDown()
+	d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store, Clock: cp})
+	defer d.Shutdown(t)
+
+	createQueue := func(t *testing.T) string {
+		queueName := random.String("queue-", 10)
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      queueName,
+			ReserveTimeout: "5m",
+			DeadTimeout:    "10m",
+			Partitions:     1,
+		}))
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          []*pb.QueueProduceItem{{Utf8: "a"}, {Utf8: "b"}, {Utf8: "c"}},
+		}))
+		return queueName
+	}
+
+	reserve := func(t *testing.T, queueName, clientID string, batchSize int32) []*pb.QueueReserveItem {
+		var res pb.QueueReserveResponse
+		require.NoError(t, c.QueueReserve(ctx, &pb.QueueReserveRequest{
+			ClientId:       clientID,
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			BatchSize:      batchSize,
+		}, &res))
+		return res.Items
+	}
+
+	consumers := func(t *testing.T, queueName string) []*pb.QueueConsumer {
+		var res pb.QueueConsumersResponse
+		require.NoError(t, c.QueueConsumers(ctx, &pb.QueueConsumersRequest{QueueName: queueName}, &res))
+		return res.Consumers
+	}
+
+	t.Run("TrackReservations", func(t *testing.T) {
+		queueName := createQueue(t)
+		clientID := random.String("client-", 10)
+
+		var hb pb.QueueHeartbeatResponse
+		require.NoError(t, c.QueueRegister(ctx, &pb.QueueRegisterRequest{
+			QueueName:        queueName,
+			ClientId:         clientID,
+			HeartbeatTimeout: "10s",
+		}, &hb))
+		registeredAt := cp.Now().UTC()
+		assert.Equal(t, registeredAt.Add(10*clock.Second), hb.HeartbeatDeadline.AsTime())
+
+		// Reserving items counts as activity and is tracked for the consumer, other clients are not listed
+		cp.Advance(5 * clock.Second)
+		items := reserve(t, queueName, clientID, 2)
+		require.Len(t, items, 2)
+		reserve(t, queueName, random.String("client-", 10), 1)
+
+		list := consumers(t, queueName)
+		require.Len(t, list, 1)
+		assert.Equal(t, clientID, list[0].ClientId)
+		assert.Equal(t, "10s", list[0].HeartbeatTimeout)
+		assert.Equal(t, int32(2), list[0].Reserved)
+		assert.Equal(t, registeredAt, list[0].RegisteredAt.AsTime())
+		assert.Equal(t, cp.Now().UTC(), list[0].LastActivity.AsTime())
+		assert.Equal(t, cp.Now().UTC().Add(10*clock.Second), list[0].HeartbeatDeadline.AsTime())
+
+		// Completed items are no longer held by the consumer
+		require.NoError(t, c.QueueComplete(ctx, &pb.QueueCompleteRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Ids:            []string{items[0].Id},
+		}))
+		list = consumers(t, queueName)
+		require.Len(t, list, 1)
+		assert.Equal(t, int32(1), list[0].Reserved)
+
+		cp.Advance(5 * clock.Second)
+		require.NoError(t, c.QueueHeartbeat(ctx, &pb.QueueHeartbeatRequest{
+			QueueName: queueName,
+			ClientId:  clientID,
+		}, &hb))
+		assert.Equal(t, cp.Now().UTC().Add(10*clock.Second), hb.HeartbeatDeadline.AsTime())
+	})
+
+	t.Run("ExtendItemReserveTimeout", func(t *testing.T) {
+		queueName := createQueue(t)
+		clientID := random.String("client-", 10)
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          []*pb.QueueProduceItem{{Utf8: "d", ReserveTimeout: "10s"}},
+		}))
+
+		require.NoError(t, c.QueueRegister(ctx, &pb.QueueRegisterRequest{
+			QueueName:        queueName,
+			ClientId:         clientID,
+			HeartbeatTimeout: "1m",
+		}, &pb.QueueHeartbeatResponse{}))
+		items := reserve(t, queueName, clientID, 4)
+		require.Len(t, items, 4)
+
+		// The item produced with its own reserve timeout is extended by that timeout
+		require.NoError(t, c.QueueExtend(ctx, &pb.QueueExtendRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Ids:            []string{items[0].Id, items[3].Id},
+		}, &pb.QueueExtendResponse{}))
+
+		// The consumer no longer holds the reservation which expired
+		cp.Advance(20 * clock.Second)
+		list := consumers(t, queueName)
+		require.Len(t, list, 1)
+		assert.Equal(t, int32(3), list[0].Reserved)
+	})
+
+	t.Run("HeartbeatTimeoutReleases", func(t *testing.T) {
+		queueName := createQueue(t)
+		clientID := random.String("client-", 10)
+
+		require.NoError(t, c.QueueRegister(ctx, &pb.QueueRegisterRequest{
+			QueueName: queueName,
+			ClientId:  clientID,
+		}, &pb.QueueHeartbeatResponse{}))
+		items := reserve(t, queueName, clientID, 3)
+		require.Len(t, items, 3)
+
+		// The consumer stops sending heartbeats long before the reservations expire
+		cp.Advance(31 * clock.Second)
+		assert.Len(t, consumers(t, queueName), 0)
+
+		err := c.QueueHeartbeat(ctx, &pb.QueueHeartbeatRequest{
+			QueueName: queueName,
+			ClientId:  clientID,
+		}, &pb.QueueHeartbeatResponse{})
+		var e duh.Error
+		require.True(t, errors.As(err, &e))
+		assert.Equal(t, "consumer is not registered; register the client id before sending heartbeats", e.Message())
+
+		// The released items can be reserved by another client, and the release was not an attempt
+		released := reserve(t, queueName, random.String("client-", 10), 3)
+		require.Len(t, released, 3)
+		assert.Equal(t, items[0].Id, released[0].Id)
+		assert.Equal(t, int32(0), released[0].Attempts)
+	})
+
+	t.Run("Deregister", func(t *testing.T) {
+		queueName := createQueue(t)
+		clientID := random.String("client-", 10)
+
+		require.NoError(t, c.QueueRegister(ctx, &pb.QueueRegisterRequest{
+			QueueName: queueName,
+			ClientId:  clientID,
+		}, &pb.QueueHeartbeatResponse{}))
+		items := reserve(t, queueName, clientID, 2)
+		require.Len(t, items, 2)
+
+		// Released items are no longer held by the consumer, and are not released again on deregister
+		require.NoError(t, c.QueueRelease(ctx, &pb.QueueReleaseRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Ids:            []string{items[1].Id},
+		}))
+		list := consumers(t, queueName)
+		require.Len(t, list, 1)
+		assert.Equal(t, int32(1), list[0].Reserved)
+
+		require.NoError(t, c.QueueDeregister(ctx, &pb.QueueDeregisterRequest{
+			QueueName: queueName,
+			ClientId:  clientID,
+		}))
+		assert.Len(t, consumers(t, queueName), 0)
+
+		var get pb.QueueGetResponse
+		require.NoError(t, c.QueueGet(ctx, &pb.QueueGetRequest{
+			QueueName: queueName,
+			Ids:       []string{items[0].Id, items[1].Id},
+		}, &get))
+		require.Len(t, get.Items, 2)
+		assert.False(t, get.Items[0].IsReserved)
+		assert.Equal(t, int32(0), get.Items[0].Attempts)
+		assert.Equal(t, int32(1), get.Items[1].Attempts)
+
+		// Deregistering an unknown consumer is not an error
+		require.NoError(t, c.QueueDeregister(ctx, &pb.QueueDeregisterRequest{
+			QueueName: queueName,
+			ClientId:  clientID,
+		}))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		queueName := createQueue(t)
+		for _, test := range []struct {
+			Name string
+			Err  error
+			Msg  string
+		}{
+			{
+				Name: "RegisterEmptyClientID",
+				Err: c.QueueRegister(ctx, &pb.QueueRegisterRequest{
+					QueueName: queueName,
+				}, &pb.QueueHeartbeatResponse{}),
+				Msg: "invalid client id; cannot be empty",
+			},
+			{
+				Name: "InvalidHeartbeatTimeout",
+				Err: c.QueueRegister(ctx, &pb.QueueRegisterRequest{
+					QueueName:        queueName,
+					ClientId:         random.String("client-", 10),
+					HeartbeatTimeout: "foo",
+				}, &pb.QueueHeartbeatResponse{}),
+				Msg: "heartbeat timeout is invalid; time: invalid duration \"foo\" - expected format: 30s, 5m or 15m",
+			},
+			{
+				Name: "NegativeHeartbeatTimeout",
+				Err: c.QueueRegister(ctx, &pb.QueueRegisterRequest{
+					QueueName:        queueName,
+					ClientId:         random.String("client-", 10),
+					HeartbeatTimeout: "-1s",
+				}, &pb.QueueHeartbeatResponse{}),
+				Msg: "heartbeat timeout is invalid; cannot be negative duration",
+			},
+			{
+				Name: "HeartbeatTimeoutTooLong",
+				Err: c.QueueRegister(ctx, &pb.QueueRegisterRequest{
+					QueueName:        queueName,
+					ClientId:         random.String("client-", 10),
+					HeartbeatTimeout: "16m",
+				}, &pb.QueueHeartbeatResponse{}),
+				Msg: "heartbeat timeout is invalid; maximum timeout is '15m' but '16m0s' requested",
+			},
+			{
+				Name: "HeartbeatNotRegistered",
+				Err: c.QueueHeartbeat(ctx, &pb.QueueHeartbeatRequest{
+					QueueName: queueName,
+					ClientId:  random.String("client-", 10),
+				}, &pb.QueueHeartbeatResponse{}),
+				Msg: "consumer is not registered; register the client id before sending heartbeats",
+			},
+		} {
+			t.Run(test.Name, func(t *testing.T) {
+				var e duh.Error
+				require.True(t, errors.As(test.Err, &e))
+				assert.Equal(t, test.Msg, e.Message())
+				assert.Equal(t, duh.CodeBadRequest, e.Code())
+			})
+		}
+	})
+}
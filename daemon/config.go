@@ -24,6 +24,12 @@ type Config struct {
 	// single `/queue.produce` request including the size of all fields in the marshalled protobuf.
 	// The default size is 1MB.
 	MaxProducePayloadSize int64
+
+	// ConfigFile is the path of the YAML config file the Config was loaded from. If provided and
+	// ServiceConfig.ConfigLoader is nil, Service.Reload() reloads the limits, timeouts, quotas, rate limits
+	// and backends from this file. Changes to the listen address, TLS, queue store, payload size or trace
+	// exporter require a restart.
+	ConfigFile string
 }
 
 func (c *Config) ClientTLS() *tls.Config {
@@ -58,5 +64,3 @@ func (c *Config) SetDefaults() error {
 	})
 	return err
 }
-
-// TODO: Load from config system
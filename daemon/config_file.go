@@ -0,0 +1,591 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/duh-rpc/duh-go"
+	"github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/internal/store"
+	"github.com/kapetan-io/tackle/clock"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DriverInMemory = "InMemory"
+	DriverBoltDB   = "BoltDB"
+
+	TraceExporterNone   = "none"
+	TraceExporterStdout = "stdout"
+	TraceExporterOTLP   = "otlp"
+
+	// EnvPrefix is the prefix of all environment variables which override values in the config file
+	EnvPrefix = "QUERATOR_"
+	// DefaultListenAddress is the address Querator listens on if no listen address is configured
+	DefaultListenAddress = "localhost:2319"
+)
+
+// File is the YAML representation of Config. See config.yaml in the root of the repository for an example.
+type File struct {
+	// ListenAddress is the address:port that Querator will listen on for public HTTP requests
+	ListenAddress string `yaml:"listen-address"`
+	// InstanceID is a unique id for this instance of Querator
+	InstanceID string `yaml:"instance-id"`
+	// TLS configures TLS for the public HTTP server, if omitted the server does not use TLS
+	TLS *FileTLS `yaml:"tls"`
+
+	// Batch and size limits, see querator.ServiceConfig for details
+	MaxProducePayloadSize int64 `yaml:"max-produce-payload-size"`
+	MaxReserveBatchSize   int   `yaml:"max-reserve-batch-size"`
+	MaxProduceBatchSize   int   `yaml:"max-produce-batch-size"`
+	MaxCompleteBatchSize  int   `yaml:"max-complete-batch-size"`
+	MaxRequestsPerQueue   int   `yaml:"max-requests-per-queue"`
+	MaxItemSize           int   `yaml:"max-item-size"`
+	MaxReserveSize        int   `yaml:"max-reserve-size"`
+
+	// Timeouts are durations in the format accepted by time.ParseDuration (e.g. '5s')
+	WriteTimeout string `yaml:"write-timeout"`
+	ReadTimeout  string `yaml:"read-timeout"`
+
+	// QueueStore is where queue information is stored, the default is InMemory
+	QueueStore FileStorage `yaml:"queue-store"`
+	// Backends are the named storage backends partitions are distributed across. The default
+	// is a single InMemory backend.
+	Backends []FileBackend `yaml:"backends"`
+
+	// NamespaceQuotas are the quotas enforced for each namespace by name, see querator.NamespaceQuota
+	NamespaceQuotas map[string]FileNamespaceQuota `yaml:"namespace-quotas"`
+	// DefaultNamespaceQuota is the quota enforced for any namespace not found in NamespaceQuotas
+	DefaultNamespaceQuota FileNamespaceQuota `yaml:"default-namespace-quota"`
+	// RateLimits are the rate limits enforced on requests to a queue, see querator.RateLimits
+	RateLimits FileRateLimits `yaml:"rate-limits"`
+	// TraceExporter is where spans are exported, the default is to create no spans
+	TraceExporter FileTraceExporter `yaml:"trace-exporter"`
+}
+
+type FileNamespaceQuota struct {
+	MaxQueues int   `yaml:"max-queues"`
+	MaxItems  int64 `yaml:"max-items"`
+	MaxBytes  int64 `yaml:"max-bytes"`
+}
+
+type FileRateLimits struct {
+	ProducePerQueue   FileRateLimit `yaml:"produce-per-queue"`
+	ProducePerClient  FileRateLimit `yaml:"produce-per-client"`
+	ReservePerQueue   FileRateLimit `yaml:"reserve-per-queue"`
+	ReservePerClient  FileRateLimit `yaml:"reserve-per-client"`
+	CompletePerQueue  FileRateLimit `yaml:"complete-per-queue"`
+	CompletePerClient FileRateLimit `yaml:"complete-per-client"`
+}
+
+type FileRateLimit struct {
+	// Rate is the number of requests per second which are permitted
+	Rate float64 `yaml:"rate"`
+	// Burst is the maximum number of requests permitted to exceed Rate in a short period of time
+	Burst int `yaml:"burst"`
+}
+
+type FileTraceExporter struct {
+	// Driver is the exporter implementation, one of 'none', 'stdout' or 'otlp'
+	Driver string `yaml:"driver"`
+	// Endpoint is the host:port of the OTLP HTTP collector, the default is 'localhost:4318'
+	Endpoint string `yaml:"endpoint"`
+	// Insecure disables TLS when connecting to the OTLP collector
+	Insecure bool `yaml:"insecure"`
+}
+
+type FileTLS struct {
+	CertFile           string `yaml:"cert-file"`
+	KeyFile            string `yaml:"key-file"`
+	CaFile             string `yaml:"ca-file"`
+	CaKeyFile          string `yaml:"ca-key-file"`
+	AutoTLS            bool   `yaml:"auto-tls"`
+	ClientAuth         string `yaml:"client-auth"`
+	ClientAuthCaFile   string `yaml:"client-auth-ca-file"`
+	ClientAuthKeyFile  string `yaml:"client-auth-key-file"`
+	ClientAuthCertFile string `yaml:"client-auth-cert-file"`
+	InsecureSkipVerify bool   `yaml:"insecure-skip-verify"`
+}
+
+type FileStorage struct {
+	// Driver is the storage implementation, one of 'InMemory' or 'BoltDB'
+	Driver string `yaml:"driver"`
+	// Config is the driver specific config. 'BoltDB' requires 'storage-dir'
+	Config map[string]string `yaml:"config"`
+}
+
+type FileBackend struct {
+	FileStorage `yaml:",inline"`
+	// Name is the unique name of the backend, partitions are assigned to backends by name
+	Name string `yaml:"name"`
+	// Affinity is the relative likelihood new partitions are assigned to this backend
+	Affinity float64 `yaml:"affinity"`
+}
+
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"":                   tls.NoClientCert,
+	"none":               tls.NoClientCert,
+	"request":            tls.RequestClientCert,
+	"require-any":        tls.RequireAnyClientCert,
+	"verify-if-given":    tls.VerifyClientCertIfGiven,
+	"require-and-verify": tls.RequireAndVerifyClientCert,
+}
+
+// LoadConfigFile reads the YAML config file at the path provided, applies any overrides found in the
+// environment and returns the resulting Config. If path is empty, only the environment is used.
+func LoadConfigFile(path string) (Config, error) {
+	conf, err := readConfigFile(path, false)
+	conf.ConfigFile = path
+	return conf, err
+}
+
+// loadConfigFile is the ServiceConfig.ConfigLoader used when the daemon was started from a config file.
+// Only the settings which can be reloaded are loaded, the running queue store, TLS config and trace
+// exporter are kept and partition stores are only created for backends which are new.
+func (c Config) loadConfigFile() (querator.ServiceConfig, error) {
+	conf, err := readConfigFile(c.ConfigFile, true)
+	if err != nil {
+		return querator.ServiceConfig{}, err
+	}
+	conf.StorageConfig.QueueStore = c.StorageConfig.QueueStore
+	conf.TraceExporter = c.TraceExporter
+	return conf.ServiceConfig, nil
+}
+
+func readConfigFile(path string, reload bool) (Config, error) {
+	var b []byte
+	if path != "" {
+		var err error
+		if b, err = os.ReadFile(path); err != nil {
+			return Config{}, fmt.Errorf("while reading config file: %w", err)
+		}
+	}
+
+	f, err := decodeFile(bytes.NewReader(b), os.LookupEnv)
+	if err != nil && path != "" {
+		return Config{}, fmt.Errorf("config file '%s': %w", path, err)
+	}
+	if err != nil {
+		return Config{}, err
+	}
+
+	conf, err := f.config(reload)
+	if err != nil && path != "" {
+		return Config{}, fmt.Errorf("config file '%s': %w", path, err)
+	}
+	return conf, err
+}
+
+// LoadConfig decodes the YAML config from the reader, applies overrides using lookupEnv and
+// returns the resulting Config. Unknown fields in the YAML are an error, such that typos
+// are not silently ignored.
+func LoadConfig(r io.Reader, lookupEnv func(string) (string, bool)) (Config, error) {
+	f, err := decodeFile(r, lookupEnv)
+	if err != nil {
+		return Config{}, err
+	}
+	return f.Config()
+}
+
+func decodeFile(r io.Reader, lookupEnv func(string) (string, bool)) (File, error) {
+	var f File
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return File{}, fmt.Errorf("while parsing yaml: %w", err)
+	}
+
+	if err := f.applyEnv(lookupEnv); err != nil {
+		return File{}, err
+	}
+	return f, nil
+}
+
+// applyEnv overrides the values in the file with values found in the environment. The environment
+// variable for each field is the EnvPrefix followed by the yaml field name in upper case with
+// dashes replaced by underscores. For example `QUERATOR_LISTEN_ADDRESS` or `QUERATOR_TLS_CERT_FILE`.
+// `QUERATOR_NAMESPACE_QUOTAS` replaces all the namespace quotas with a YAML flow mapping such as
+// `{team-a: {max-items: 1000}}`. Backends cannot be configured via the environment.
+func (f *File) applyEnv(lookupEnv func(string) (string, bool)) error {
+	if f.TLS == nil {
+		f.TLS = &FileTLS{}
+		defer func() {
+			// Only enable TLS if the environment provided a TLS config
+			if *f.TLS == (FileTLS{}) {
+				f.TLS = nil
+			}
+		}()
+	}
+
+	vars := []struct {
+		name  string
+		value any
+	}{
+		{"LISTEN_ADDRESS", &f.ListenAddress},
+		{"INSTANCE_ID", &f.InstanceID},
+		{"MAX_PRODUCE_PAYLOAD_SIZE", &f.MaxProducePayloadSize},
+		{"MAX_RESERVE_BATCH_SIZE", &f.MaxReserveBatchSize},
+		{"MAX_PRODUCE_BATCH_SIZE", &f.MaxProduceBatchSize},
+		{"MAX_COMPLETE_BATCH_SIZE", &f.MaxCompleteBatchSize},
+		{"MAX_REQUESTS_PER_QUEUE", &f.MaxRequestsPerQueue},
+		{"MAX_ITEM_SIZE", &f.MaxItemSize},
+		{"MAX_RESERVE_SIZE", &f.MaxReserveSize},
+		{"WRITE_TIMEOUT", &f.WriteTimeout},
+		{"READ_TIMEOUT", &f.ReadTimeout},
+		{"TLS_CERT_FILE", &f.TLS.CertFile},
+		{"TLS_KEY_FILE", &f.TLS.KeyFile},
+		{"TLS_CA_FILE", &f.TLS.CaFile},
+		{"TLS_CA_KEY_FILE", &f.TLS.CaKeyFile},
+		{"TLS_AUTO_TLS", &f.TLS.AutoTLS},
+		{"TLS_CLIENT_AUTH", &f.TLS.ClientAuth},
+		{"TLS_CLIENT_AUTH_CA_FILE", &f.TLS.ClientAuthCaFile},
+		{"TLS_CLIENT_AUTH_KEY_FILE", &f.TLS.ClientAuthKeyFile},
+		{"TLS_CLIENT_AUTH_CERT_FILE", &f.TLS.ClientAuthCertFile},
+		{"TLS_INSECURE_SKIP_VERIFY", &f.TLS.InsecureSkipVerify},
+		{"NAMESPACE_QUOTAS", &f.NamespaceQuotas},
+		{"DEFAULT_NAMESPACE_QUOTA_MAX_QUEUES", &f.DefaultNamespaceQuota.MaxQueues},
+		{"DEFAULT_NAMESPACE_QUOTA_MAX_ITEMS", &f.DefaultNamespaceQuota.MaxItems},
+		{"DEFAULT_NAMESPACE_QUOTA_MAX_BYTES", &f.DefaultNamespaceQuota.MaxBytes},
+		{"RATE_LIMITS_PRODUCE_PER_QUEUE_RATE", &f.RateLimits.ProducePerQueue.Rate},
+		{"RATE_LIMITS_PRODUCE_PER_QUEUE_BURST", &f.RateLimits.ProducePerQueue.Burst},
+		{"RATE_LIMITS_PRODUCE_PER_CLIENT_RATE", &f.RateLimits.ProducePerClient.Rate},
+		{"RATE_LIMITS_PRODUCE_PER_CLIENT_BURST", &f.RateLimits.ProducePerClient.Burst},
+		{"RATE_LIMITS_RESERVE_PER_QUEUE_RATE", &f.RateLimits.ReservePerQueue.Rate},
+		{"RATE_LIMITS_RESERVE_PER_QUEUE_BURST", &f.RateLimits.ReservePerQueue.Burst},
+		{"RATE_LIMITS_RESERVE_PER_CLIENT_RATE", &f.RateLimits.ReservePerClient.Rate},
+		{"RATE_LIMITS_RESERVE_PER_CLIENT_BURST", &f.RateLimits.ReservePerClient.Burst},
+		{"RATE_LIMITS_COMPLETE_PER_QUEUE_RATE", &f.RateLimits.CompletePerQueue.Rate},
+		{"RATE_LIMITS_COMPLETE_PER_QUEUE_BURST", &f.RateLimits.CompletePerQueue.Burst},
+		{"RATE_LIMITS_COMPLETE_PER_CLIENT_RATE", &f.RateLimits.CompletePerClient.Rate},
+		{"RATE_LIMITS_COMPLETE_PER_CLIENT_BURST", &f.RateLimits.CompletePerClient.Burst},
+		{"TRACE_EXPORTER_DRIVER", &f.TraceExporter.Driver},
+		{"TRACE_EXPORTER_ENDPOINT", &f.TraceExporter.Endpoint},
+		{"TRACE_EXPORTER_INSECURE", &f.TraceExporter.Insecure},
+	}
+
+	for _, v := range vars {
+		name := EnvPrefix + v.name
+		s, ok := lookupEnv(name)
+		if !ok || s == "" {
+			continue
+		}
+
+		var err error
+		switch t := v.value.(type) {
+		case *string:
+			*t = s
+		case *int:
+			*t, err = strconv.Atoi(s)
+		case *int64:
+			*t, err = strconv.ParseInt(s, 10, 64)
+		case *float64:
+			*t, err = strconv.ParseFloat(s, 64)
+		case *bool:
+			*t, err = strconv.ParseBool(s)
+		case *map[string]FileNamespaceQuota:
+			*t = nil
+			dec := yaml.NewDecoder(strings.NewReader(s))
+			dec.KnownFields(true)
+			err = dec.Decode(t)
+		}
+		if err != nil {
+			return fmt.Errorf("environment variable '%s' is invalid: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Config validates the file and returns the Config it describes. All validation errors are
+// returned, not just the first one found.
+func (f *File) Config() (Config, error) {
+	return f.config(false)
+}
+
+// config validates the file and returns the Config it describes. If reload is true, the Config is
+// intended for Service.UpdateConfig(), as such the queue store, TLS certificates and trace exporter
+// are not created and each backend provides store.Backend.NewPartitionStore instead of a PartitionStore.
+func (f *File) config(reload bool) (Config, error) {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	conf := Config{
+		ListenAddress:         f.ListenAddress,
+		MaxProducePayloadSize: f.MaxProducePayloadSize,
+	}
+	conf.InstanceID = f.InstanceID
+	conf.MaxReserveBatchSize = f.MaxReserveBatchSize
+	conf.MaxProduceBatchSize = f.MaxProduceBatchSize
+	conf.MaxCompleteBatchSize = f.MaxCompleteBatchSize
+	conf.MaxRequestsPerQueue = f.MaxRequestsPerQueue
+	conf.MaxItemSize = f.MaxItemSize
+	conf.MaxReserveSize = f.MaxReserveSize
+	conf.StorageConfig.Clock = clock.NewProvider()
+	if conf.ListenAddress == "" {
+		conf.ListenAddress = DefaultListenAddress
+	}
+
+	for _, v := range []struct {
+		name  string
+		value int64
+	}{
+		{"max-produce-payload-size", f.MaxProducePayloadSize},
+		{"max-reserve-batch-size", int64(f.MaxReserveBatchSize)},
+		{"max-produce-batch-size", int64(f.MaxProduceBatchSize)},
+		{"max-complete-batch-size", int64(f.MaxCompleteBatchSize)},
+		{"max-requests-per-queue", int64(f.MaxRequestsPerQueue)},
+		{"max-item-size", int64(f.MaxItemSize)},
+		{"max-reserve-size", int64(f.MaxReserveSize)},
+	} {
+		if v.value < 0 {
+			invalid("'%s' cannot be negative", v.name)
+		}
+	}
+
+	// Namespace quotas
+	quota := func(field string, q FileNamespaceQuota) querator.NamespaceQuota {
+		if q.MaxQueues < 0 || q.MaxItems < 0 || q.MaxBytes < 0 {
+			invalid("'%s' cannot have negative limits", field)
+		}
+		return querator.NamespaceQuota{MaxQueues: q.MaxQueues, MaxItems: q.MaxItems, MaxBytes: q.MaxBytes}
+	}
+	if len(f.NamespaceQuotas) != 0 {
+		conf.NamespaceQuotas = make(map[string]querator.NamespaceQuota, len(f.NamespaceQuotas))
+		for name, q := range f.NamespaceQuotas {
+			conf.NamespaceQuotas[name] = quota(fmt.Sprintf("namespace-quotas.%s", name), q)
+		}
+	}
+	conf.DefaultNamespaceQuota = quota("default-namespace-quota", f.DefaultNamespaceQuota)
+
+	// Rate limits
+	for _, v := range []struct {
+		name  string
+		value FileRateLimit
+		dest  *querator.RateLimit
+	}{
+		{"produce-per-queue", f.RateLimits.ProducePerQueue, &conf.RateLimits.ProducePerQueue},
+		{"produce-per-client", f.RateLimits.ProducePerClient, &conf.RateLimits.ProducePerClient},
+		{"reserve-per-queue", f.RateLimits.ReservePerQueue, &conf.RateLimits.ReservePerQueue},
+		{"reserve-per-client", f.RateLimits.ReservePerClient, &conf.RateLimits.ReservePerClient},
+		{"complete-per-queue", f.RateLimits.CompletePerQueue, &conf.RateLimits.CompletePerQueue},
+		{"complete-per-client", f.RateLimits.CompletePerClient, &conf.RateLimits.CompletePerClient},
+	} {
+		if v.value.Rate < 0 || v.value.Burst < 0 {
+			invalid("'rate-limits.%s' cannot be negative", v.name)
+		}
+		*v.dest = querator.RateLimit{Rate: v.value.Rate, Burst: v.value.Burst}
+	}
+
+	for _, v := range []struct {
+		name  string
+		value string
+		dest  *clock.Duration
+	}{
+		{"write-timeout", f.WriteTimeout, &conf.WriteTimeout},
+		{"read-timeout", f.ReadTimeout, &conf.ReadTimeout},
+	} {
+		if v.value == "" {
+			continue
+		}
+		d, err := clock.ParseDuration(v.value)
+		if err != nil || d <= 0 {
+			invalid("'%s' is invalid; '%s' must be a positive duration (e.g. '5s')", v.name, v.value)
+			continue
+		}
+		*v.dest = d
+	}
+
+	if f.TLS != nil {
+		auth, ok := clientAuthTypes[f.TLS.ClientAuth]
+		if !ok {
+			invalid("'tls.client-auth' is invalid; '%s' must be one of 'none', 'request', 'require-any', "+
+				"'verify-if-given' or 'require-and-verify'", f.TLS.ClientAuth)
+		}
+		if !f.TLS.AutoTLS && (f.TLS.CertFile == "" || f.TLS.KeyFile == "") {
+			invalid("'tls.cert-file' and 'tls.key-file' are required unless 'tls.auto-tls' is true")
+		}
+		conf.TLS = &duh.TLSConfig{
+			CertFile:           f.TLS.CertFile,
+			KeyFile:            f.TLS.KeyFile,
+			CaFile:             f.TLS.CaFile,
+			CaKeyFile:          f.TLS.CaKeyFile,
+			AutoTLS:            f.TLS.AutoTLS,
+			ClientAuth:         auth,
+			ClientAuthCaFile:   f.TLS.ClientAuthCaFile,
+			ClientAuthKeyFile:  f.TLS.ClientAuthKeyFile,
+			ClientAuthCertFile: f.TLS.ClientAuthCertFile,
+			InsecureSkipVerify: f.TLS.InsecureSkipVerify,
+		}
+	}
+
+	// Queue Store
+	switch driver(f.QueueStore.Driver) {
+	case "", DriverInMemory:
+		if !reload {
+			conf.StorageConfig.QueueStore = store.NewMemoryQueueStore()
+		}
+	case DriverBoltDB:
+		dir, err := storageDir("queue-store", f.QueueStore.Config)
+		if err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if !reload {
+			conf.StorageConfig.QueueStore = store.NewBoltQueueStore(store.BoltConfig{
+				Clock:      conf.StorageConfig.Clock,
+				StorageDir: dir,
+			})
+		}
+	default:
+		invalid("'queue-store.driver' is invalid; '%s' must be one of '%s' or '%s'",
+			f.QueueStore.Driver, DriverInMemory, DriverBoltDB)
+	}
+
+	// Backends
+	names := make(map[string]int, len(f.Backends))
+	for i, b := range f.Backends {
+		field := fmt.Sprintf("backends[%d]", i)
+		if b.Name == "" {
+			invalid("'%s.name' cannot be empty", field)
+		} else if prev, ok := names[b.Name]; ok {
+			invalid("'%s.name' is invalid; '%s' is already used by 'backends[%d]'", field, b.Name, prev)
+		}
+		names[b.Name] = i
+		if b.Affinity < 0 {
+			invalid("'%s.affinity' cannot be negative", field)
+		}
+
+		backend := store.Backend{Name: b.Name, Affinity: b.Affinity}
+		storageConfig := conf.StorageConfig
+		switch driver(b.Driver) {
+		case DriverInMemory:
+			backend.Settings = fmt.Sprintf("driver=%s", DriverInMemory)
+			backend.NewPartitionStore = func() store.PartitionStore {
+				return store.NewMemoryPartitionStore(storageConfig)
+			}
+		case DriverBoltDB:
+			dir, err := storageDir(field, b.Config)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			backend.Settings = fmt.Sprintf("driver=%s storage-dir=%s", DriverBoltDB, dir)
+			backend.NewPartitionStore = func() store.PartitionStore {
+				return store.NewBoltPartitionStore(store.BoltConfig{
+					Clock:      storageConfig.Clock,
+					StorageDir: dir,
+				})
+			}
+		default:
+			invalid("'%s.driver' is invalid; '%s' must be one of '%s' or '%s'",
+				field, b.Driver, DriverInMemory, DriverBoltDB)
+			continue
+		}
+		conf.StorageConfig.Backends = append(conf.StorageConfig.Backends, backend)
+	}
+
+	if len(f.Backends) == 0 {
+		storageConfig := conf.StorageConfig
+		conf.StorageConfig.Backends = []store.Backend{
+			{
+				Name:     "memory-0",
+				Affinity: 1,
+				Settings: fmt.Sprintf("driver=%s", DriverInMemory),
+				NewPartitionStore: func() store.PartitionStore {
+					return store.NewMemoryPartitionStore(storageConfig)
+				},
+			},
+		}
+	}
+
+	if !reload {
+		for i := range conf.StorageConfig.Backends {
+			conf.StorageConfig.Backends[i].PartitionStore = conf.StorageConfig.Backends[i].NewPartitionStore()
+		}
+	}
+
+	// Trace exporter
+	switch strings.ToLower(f.TraceExporter.Driver) {
+	case "", TraceExporterNone, TraceExporterStdout, TraceExporterOTLP:
+	default:
+		invalid("'trace-exporter.driver' is invalid; '%s' must be one of '%s', '%s' or '%s'",
+			f.TraceExporter.Driver, TraceExporterNone, TraceExporterStdout, TraceExporterOTLP)
+	}
+
+	if len(errs) != 0 {
+		return Config{}, fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+
+	// TLS and the trace exporter cannot be changed without a restart
+	if reload {
+		return conf, nil
+	}
+
+	// Load the TLS certificates only once the rest of the config is valid
+	if conf.TLS != nil {
+		if err := duh.SetupTLS(conf.TLS); err != nil {
+			return Config{}, fmt.Errorf("while setting up TLS: %w", err)
+		}
+	}
+
+	exporter, err := f.TraceExporter.exporter()
+	if err != nil {
+		return Config{}, fmt.Errorf("while creating trace exporter: %w", err)
+	}
+	conf.TraceExporter = exporter
+	return conf, nil
+}
+
+// exporter returns the span exporter for the driver, or nil if no spans should be exported
+func (t FileTraceExporter) exporter() (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(t.Driver) {
+	case TraceExporterStdout:
+		return stdouttrace.New()
+	case TraceExporterOTLP:
+		var opts []otlptracehttp.Option
+		if t.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(t.Endpoint))
+		}
+		if t.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(context.Background(), opts...)
+	}
+	return nil, nil
+}
+
+// driver returns the canonical driver name, such that driver names are not case-sensitive
+func driver(name string) string {
+	for _, d := range []string{DriverInMemory, DriverBoltDB} {
+		if strings.EqualFold(name, d) {
+			return d
+		}
+	}
+	return name
+}
+
+func storageDir(field string, conf map[string]string) (string, error) {
+	dir := conf["storage-dir"]
+	if dir == "" {
+		return "", fmt.Errorf("'%s.config.storage-dir' is required for driver '%s'", field, DriverBoltDB)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", fmt.Errorf("'%s.config.storage-dir' is invalid: %w", field, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("'%s.config.storage-dir' is invalid; '%s' is not a directory", field, dir)
+	}
+	return dir, nil
+}
@@ -45,20 +45,28 @@ type Daemon struct {
 
 func NewDaemon(ctx context.Context, conf Config) (*Daemon, error) {
 	set.Default(&conf.Logger, slog.Default())
-
-	// TODO: Load from config file
+	if conf.ConfigFile != "" && conf.ConfigLoader == nil {
+		conf.ConfigLoader = conf.loadConfigFile
+	}
 
 	s, err := querator.NewService(querator.ServiceConfig{
-		MaxCompleteBatchSize: conf.MaxCompleteBatchSize,
-		MaxReserveBatchSize:  conf.MaxReserveBatchSize,
-		MaxProduceBatchSize:  conf.MaxProduceBatchSize,
-		MaxRequestsPerQueue:  conf.MaxRequestsPerQueue,
-		WriteTimeout:         conf.WriteTimeout,
-		ReadTimeout:          conf.ReadTimeout,
-		InstanceID:           conf.InstanceID,
-		StorageConfig:        conf.StorageConfig,
-		Logger:               conf.Logger,
-		Clock:                conf.Clock,
+		MaxCompleteBatchSize:  conf.MaxCompleteBatchSize,
+		MaxReserveBatchSize:   conf.MaxReserveBatchSize,
+		MaxProduceBatchSize:   conf.MaxProduceBatchSize,
+		MaxRequestsPerQueue:   conf.MaxRequestsPerQueue,
+		WriteTimeout:          conf.WriteTimeout,
+		ReadTimeout:           conf.ReadTimeout,
+		InstanceID:            conf.InstanceID,
+		StorageConfig:         conf.StorageConfig,
+		NamespaceQuotas:       conf.NamespaceQuotas,
+		DefaultNamespaceQuota: conf.DefaultNamespaceQuota,
+		RateLimits:            conf.RateLimits,
+		MaxItemSize:           conf.MaxItemSize,
+		MaxReserveSize:        conf.MaxReserveSize,
+		TraceExporter:         conf.TraceExporter,
+		Logger:                conf.Logger,
+		Clock:                 conf.Clock,
+		ConfigLoader:          conf.ConfigLoader,
 	})
 	if err != nil {
 		return nil, err
@@ -77,8 +85,9 @@ func (d *Daemon) Start(ctx context.Context) error {
 
 	handler := transport.NewHTTPHandler(d.service, promhttp.InstrumentMetricHandler(
 		registry, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
-	), d.conf.MaxProducePayloadSize, d.conf.Logger)
+	), d.conf.MaxProducePayloadSize, d.service.TracerProvider(), d.conf.Logger)
 	registry.MustRegister(handler)
+	registry.MustRegister(d.service)
 
 	if d.conf.ServerTLS() != nil {
 		if err := d.spawnHTTPS(ctx, handler); err != nil {
@@ -0,0 +1,210 @@
+package querator_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/duh-rpc/duh-go"
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedup(t *testing.T) {
+	bdb := boltTestSetup{Dir: t.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+		// Durable is true if the storage survives a restart of the daemon
+		Durable bool
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+			Durable: true,
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			testDedup(t, tc.Setup, tc.TearDown, tc.Durable)
+		})
+	}
+}
+
+func testDedup(t *testing.T, setup NewStorageFunc, tearDown func(), durable bool) {
+	cp := clock.NewProvider()
+	cp.Freeze(clock.Now())
+	_store := setup(cp)
+	defer tearDown()
+	d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store, Clock: cp})
+	defer func() { d.Shutdown(t) }()
+
+	createQueue := func(t *testing.T, dedupWindow string) string {
+		queueName := random.String("queue-", 10)
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      queueName,
+			ReserveTimeout: "1m",
+			DeadTimeout:    "10m",
+			DedupWindow:    dedupWindow,
+			Partitions:     1,
+		}))
+		return queueName
+	}
+
+	produce := func(t *testing.T, queueName string, dedupIDs ...string) {
+		var items []*pb.QueueProduceItem
+		for _, id := range dedupIDs {
+			items = append(items, &pb.QueueProduceItem{DedupId: id, Utf8: random.String("payload-", 10)})
+		}
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          items,
+		}))
+	}
+
+	listDedupIDs := func(t *testing.T, queueName string) []string {
+		var list pb.StorageQueueListResponse
+		require.NoError(t, c.StorageQueueList(ctx, queueName, &list, &que.ListOptions{Limit: 100}))
+		var ids []string
+		for _, item := range list.Items {
+			ids = append(ids, item.DedupId)
+		}
+		return ids
+	}
+
+	t.Run("Window", func(t *testing.T) {
+		queueName := createQueue(t, "1m")
+		var queues pb.QueuesListResponse
+		require.NoError(t, c.QueuesList(ctx, &queues, &que.ListOptions{Pivot: queueName, Limit: 1}))
+		require.Len(t, queues.Items, 1)
+		assert.Equal(t, "1m0s", queues.Items[0].DedupWindow)
+
+		produce(t, queueName, "one", "two")
+		// A retry of the same items is acknowledged but the items are not added again
+		produce(t, queueName, "one", "two", "three")
+		// Duplicates within the same request are also removed
+		produce(t, queueName, "four", "four", "")
+		assert.Equal(t, []string{"one", "two", "three", "four", ""}, listDedupIDs(t, queueName))
+
+		var stats pb.QueueStatsResponse
+		require.NoError(t, c.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: queueName}, &stats))
+		assert.Equal(t, int32(5), stats.Total)
+
+		// Items produced after the window has passed are not duplicates
+		cp.Advance(clock.Minute)
+		produce(t, queueName, "one", "four")
+		assert.Equal(t, []string{"one", "two", "three", "four", "", "one", "four"}, listDedupIDs(t, queueName))
+
+		// The dedup window restarts with the item produced after the window expired
+		cp.Advance(30 * clock.Second)
+		produce(t, queueName, "one", "two")
+		assert.Equal(t, []string{"one", "two", "three", "four", "", "one", "four", "two"},
+			listDedupIDs(t, queueName))
+	})
+
+	t.Run("DefaultWindow", func(t *testing.T) {
+		queueName := createQueue(t, "")
+		produce(t, queueName, "one")
+		cp.Advance(4 * clock.Minute)
+		produce(t, queueName, "one")
+		assert.Equal(t, []string{"one"}, listDedupIDs(t, queueName))
+
+		cp.Advance(clock.Minute)
+		produce(t, queueName, "one")
+		assert.Equal(t, []string{"one", "one"}, listDedupIDs(t, queueName))
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		queueName := createQueue(t, "1h")
+		produce(t, queueName, "one")
+		require.NoError(t, c.QueueClear(ctx, &pb.QueueClearRequest{
+			QueueName:   queueName,
+			Queue:       true,
+			Destructive: true,
+		}))
+		produce(t, queueName, "one")
+		assert.Equal(t, []string{"one"}, listDedupIDs(t, queueName))
+	})
+
+	t.Run("Restart", func(t *testing.T) {
+		if !durable {
+			t.Skip("storage does not survive a restart")
+		}
+		queueName := createQueue(t, "1h")
+		produce(t, queueName, "one")
+
+		d.Shutdown(t)
+		d, c, ctx = newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store, Clock: cp})
+
+		// The order is not asserted as ids generated after a restart within the same second might
+		// sort before ids generated before the restart
+		produce(t, queueName, "one", "two")
+		assert.ElementsMatch(t, []string{"one", "two"}, listDedupIDs(t, queueName))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		queueName := createQueue(t, "1m")
+		for _, test := range []struct {
+			Name string
+			Err  error
+			Msg  string
+		}{
+			{
+				Name: "DedupIDMaxLength",
+				Err: c.QueueProduce(ctx, &pb.QueueProduceRequest{
+					QueueName:      queueName,
+					RequestTimeout: "1m",
+					Items:          []*pb.QueueProduceItem{{DedupId: random.String("", 513)}},
+				}),
+				Msg: "item dedup id is invalid; cannot be greater than '512' characters",
+			},
+			{
+				Name: "InvalidDedupWindow",
+				Err: c.QueuesCreate(ctx, &pb.QueueInfo{
+					QueueName:      random.String("queue-", 10),
+					ReserveTimeout: "1m",
+					DeadTimeout:    "10m",
+					DedupWindow:    "foo",
+					Partitions:     1,
+				}),
+				Msg: "dedup window is invalid; time: invalid duration \"foo\" - expected format: 5m, 1h or 24h",
+			},
+			{
+				Name: "NegativeDedupWindow",
+				Err: c.QueuesCreate(ctx, &pb.QueueInfo{
+					QueueName:      random.String("queue-", 10),
+					ReserveTimeout: "1m",
+					DeadTimeout:    "10m",
+					DedupWindow:    "-1m",
+					Partitions:     1,
+				}),
+				Msg: "dedup window is invalid; cannot be negative duration",
+			},
+		} {
+			t.Run(test.Name, func(t *testing.T) {
+				var e duh.Error
+				require.True(t, errors.As(test.Err, &e))
+				assert.Equal(t, test.Msg, e.Message())
+				assert.Equal(t, duh.CodeBadRequest, e.Code())
+			})
+		}
+	})
+}
@@ -0,0 +1,165 @@
+package querator_test
+
+import (
+	"net/http"
+	"testing"
+
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/querator/transport"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrain(t *testing.T) {
+	bdb := boltTestSetup{Dir: t.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			testDrain(t, tc.Setup, tc.TearDown)
+		})
+	}
+}
+
+func testDrain(t *testing.T, setup NewStorageFunc, tearDown func()) {
+	_store := setup(clock.NewProvider())
+	defer tearDown()
+	d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store})
+	defer d.Shutdown(t)
+
+	var status pb.AdminDrainResponse
+	require.NoError(t, c.AdminDrainStatus(ctx, &status))
+	assert.False(t, status.Draining)
+	assert.False(t, status.Drained)
+
+	createQueue := func(name string) {
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      name,
+			ReserveTimeout: "1m",
+			DeadTimeout:    "10m",
+			Partitions:     1,
+		}))
+	}
+	busy, empty := random.String("queue-", 10), random.String("queue-", 10)
+	createQueue(busy)
+	createQueue(empty)
+
+	require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+		QueueName:      busy,
+		RequestTimeout: "1m",
+		Items:          randomProduceItems(2),
+	}))
+	var reserved pb.QueueReserveResponse
+	require.NoError(t, c.QueueReserve(ctx, &pb.QueueReserveRequest{
+		ClientId:       random.String("client-", 10),
+		QueueName:      busy,
+		RequestTimeout: "1m",
+		BatchSize:      1,
+	}, &reserved))
+	require.Len(t, reserved.Items, 1)
+
+	// Long poll a reserve on the empty queue
+	type result struct {
+		res pb.QueueReserveResponse
+		err error
+	}
+	polled := make(chan *result)
+	go func() {
+		var r result
+		r.err = c.QueueReserve(ctx, &pb.QueueReserveRequest{
+			ClientId:       random.String("client-", 10),
+			QueueName:      empty,
+			RequestTimeout: "1m",
+			BatchSize:      10,
+		}, &r.res)
+		polled <- &r
+	}()
+	require.Eventually(t, func() bool {
+		var stats pb.QueueStatsResponse
+		require.NoError(t, c.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: empty}, &stats))
+		return stats.ReserveBlocked == 1
+	}, 5*clock.Second, 10*clock.Millisecond)
+
+	require.NoError(t, c.AdminDrain(ctx, &status))
+	assert.True(t, status.Draining)
+	assert.Equal(t, int32(2), status.Queues)
+	assert.Equal(t, int32(1), status.Reserved)
+
+	t.Run("LongPollReturnsEmpty", func(t *testing.T) {
+		select {
+		case r := <-polled:
+			require.NoError(t, r.err)
+			assert.Len(t, r.res.Items, 0)
+		case <-clock.After(5 * clock.Second):
+			t.Fatal("long poll reserve did not return")
+		}
+	})
+
+	t.Run("RejectsProduceAndReserve", func(t *testing.T) {
+		err := c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      busy,
+			RequestTimeout: "1m",
+			Items:          randomProduceItems(1),
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), que.MsgServiceDraining)
+
+		var res pb.QueueReserveResponse
+		err = c.QueueReserve(ctx, &pb.QueueReserveRequest{
+			ClientId:       random.String("client-", 10),
+			QueueName:      busy,
+			RequestTimeout: "1m",
+			BatchSize:      1,
+		}, &res)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), que.MsgServiceDraining)
+	})
+
+	t.Run("CompletesReservations", func(t *testing.T) {
+		require.NoError(t, c.QueueComplete(ctx, &pb.QueueCompleteRequest{
+			QueueName:      busy,
+			RequestTimeout: "1m",
+			Ids:            que.CollectIDs(reserved.Items),
+		}))
+
+		require.NoError(t, c.AdminDrainStatus(ctx, &status))
+		assert.True(t, status.Draining)
+		assert.True(t, status.Drained)
+		assert.Equal(t, int32(0), status.InFlight)
+		assert.Equal(t, int32(0), status.Reserved)
+	})
+
+	t.Run("NotReady", func(t *testing.T) {
+		code, health := getHealth(t, d, transport.PathReadiness)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		require.NotEmpty(t, health.Checks)
+		assert.Equal(t, que.MsgServiceDraining, health.Checks[0].Message)
+
+		code, _ = getHealth(t, d, transport.PathLiveness)
+		assert.Equal(t, http.StatusOK, code)
+	})
+}
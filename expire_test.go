@@ -0,0 +1,261 @@
+package querator_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/duh-rpc/duh-go"
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpire(t *testing.T) {
+	bdb := boltTestSetup{Dir: t.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			testExpire(t, tc.Setup, tc.TearDown)
+		})
+	}
+}
+
+func testExpire(t *testing.T, setup NewStorageFunc, tearDown func()) {
+	cp := clock.NewProvider()
+	cp.Freeze(clock.Now())
+	_store := setup(cp)
+	defer tearDown()
+	d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{
+		StorageConfig: _store,
+		Clock:         cp,
+		NamespaceQuotas: map[string]que.NamespaceQuota{
+			"limited": {MaxItems: 3},
+		},
+	})
+	defer d.Shutdown(t)
+
+	createQueue := func(t *testing.T, expireTimeout string) string {
+		queueName := random.String("queue-", 10)
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      queueName,
+			ReserveTimeout: "1m",
+			DeadTimeout:    "10m",
+			ExpireTimeout:  expireTimeout,
+			Partitions:     1,
+		}))
+		return queueName
+	}
+
+	reserve := func(t *testing.T, queueName string) []string {
+		var res pb.QueueReserveResponse
+		require.NoError(t, c.QueueReserve(ctx, &pb.QueueReserveRequest{
+			ClientId:       random.String("client-", 10),
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			BatchSize:      10,
+		}, &res))
+		var payloads []string
+		for _, item := range res.Items {
+			payloads = append(payloads, string(item.Bytes))
+		}
+		return payloads
+	}
+
+	stats := func(t *testing.T, queueName string) *pb.QueueStatsResponse {
+		var res pb.QueueStatsResponse
+		require.NoError(t, c.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: queueName}, &res))
+		return &res
+	}
+
+	t.Run("QueueAndItemExpiry", func(t *testing.T) {
+		queueName := createQueue(t, "1m")
+		var list pb.QueuesListResponse
+		require.NoError(t, c.QueuesList(ctx, &list, &que.ListOptions{Pivot: queueName, Limit: 1}))
+		require.Len(t, list.Items, 1)
+		assert.Equal(t, "1m0s", list.Items[0].ExpireTimeout)
+
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items: []*pb.QueueProduceItem{
+				{Utf8: "a"},
+				{Utf8: "b", ExpireTimeout: "10s"},
+				{Utf8: "c", ExpireTimeout: "5m"},
+				{Utf8: "d", OrderingKey: "account-1"},
+				{Utf8: "e", OrderingKey: "account-1", ExpireTimeout: "5m"},
+			},
+		}))
+
+		var items pb.StorageQueueListResponse
+		require.NoError(t, c.StorageQueueList(ctx, queueName, &items, &que.ListOptions{Limit: 10}))
+		require.Len(t, items.Items, 5)
+		assert.Equal(t, cp.Now().UTC().Add(clock.Minute), items.Items[0].ExpireDeadline.AsTime())
+		assert.Equal(t, cp.Now().UTC().Add(10*clock.Second), items.Items[1].ExpireDeadline.AsTime())
+
+		// Expired items are counted by stats before they are removed
+		cp.Advance(clock.Minute)
+		assert.Equal(t, int32(5), stats(t, queueName).Total)
+		assert.Equal(t, int32(3), stats(t, queueName).TotalExpired)
+
+		// Expired items are not visible to peek and are never reserved, an expired item
+		// does not prevent the next item with the same ordering key from being reserved.
+		var peek pb.QueuePeekResponse
+		require.NoError(t, c.QueuePeek(ctx, &pb.QueuePeekRequest{QueueName: queueName, BatchSize: 10}, &peek))
+		require.Len(t, peek.Items, 2)
+		assert.Equal(t, "c", string(peek.Items[0].Bytes))
+		assert.Equal(t, cp.Now().UTC().Add(4*clock.Minute), peek.Items[0].ExpireDeadline.AsTime())
+
+		assert.Equal(t, []string{"c", "e"}, reserve(t, queueName))
+
+		// Reserve removes the expired items from the queue
+		s := stats(t, queueName)
+		assert.Equal(t, int32(2), s.Total)
+		assert.Equal(t, int32(3), s.TotalExpired)
+		assert.Contains(t, scrapeMetrics(t, d), fmt.Sprintf(
+			`queue_items_total{namespace="",op="expire",queue="%s"} 3`, queueName))
+	})
+
+	t.Run("NoExpiry", func(t *testing.T) {
+		queueName := createQueue(t, "")
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          []*pb.QueueProduceItem{{Utf8: "a"}, {Utf8: "b", ExpireTimeout: "1m"}},
+		}))
+
+		cp.Advance(5 * clock.Minute)
+		assert.Equal(t, []string{"a"}, reserve(t, queueName))
+		s := stats(t, queueName)
+		assert.Equal(t, int32(1), s.Total)
+		assert.Equal(t, int32(1), s.TotalExpired)
+	})
+
+	t.Run("ExpiredFreeQuota", func(t *testing.T) {
+		limited := namespaceClient(t, d, "limited")
+		queueName := random.String("queue-", 10)
+		require.NoError(t, limited.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      queueName,
+			ReserveTimeout: "1m",
+			DeadTimeout:    "10m",
+			Partitions:     1,
+		}))
+		require.NoError(t, limited.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items: []*pb.QueueProduceItem{
+				{Utf8: "a", ExpireTimeout: "10s"},
+				{Utf8: "b", ExpireTimeout: "10s"},
+				{Utf8: "c"},
+			},
+		}))
+		err := limited.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          []*pb.QueueProduceItem{{Utf8: "d"}},
+		})
+		var e duh.Error
+		require.True(t, errors.As(err, &e))
+		assert.Equal(t, duh.CodeRetryRequest, e.Code())
+
+		// The expired items removed by reserve no longer count towards the namespace quota
+		cp.Advance(clock.Minute)
+		var res pb.QueueReserveResponse
+		require.NoError(t, limited.QueueReserve(ctx, &pb.QueueReserveRequest{
+			ClientId:       random.String("client-", 10),
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			BatchSize:      10,
+		}, &res))
+		require.Len(t, res.Items, 1)
+		assert.Equal(t, "c", string(res.Items[0].Bytes))
+
+		require.NoError(t, limited.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          []*pb.QueueProduceItem{{Utf8: "d"}, {Utf8: "e"}},
+		}))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		queueName := createQueue(t, "")
+		for _, test := range []struct {
+			Name string
+			Err  error
+			Msg  string
+		}{
+			{
+				Name: "InvalidItemExpireTimeout",
+				Err: c.QueueProduce(ctx, &pb.QueueProduceRequest{
+					QueueName:      queueName,
+					RequestTimeout: "1m",
+					Items:          []*pb.QueueProduceItem{{ExpireTimeout: "foo"}},
+				}),
+				Msg: "item expire timeout is invalid; time: invalid duration \"foo\" - expected format: 30s, 5m or 1h",
+			},
+			{
+				Name: "NegativeItemExpireTimeout",
+				Err: c.QueueProduce(ctx, &pb.QueueProduceRequest{
+					QueueName:      queueName,
+					RequestTimeout: "1m",
+					Items:          []*pb.QueueProduceItem{{ExpireTimeout: "-1m"}},
+				}),
+				Msg: "item expire timeout is invalid; '-1m' must be greater than zero",
+			},
+			{
+				Name: "InvalidExpireTimeout",
+				Err: c.QueuesCreate(ctx, &pb.QueueInfo{
+					QueueName:      random.String("queue-", 10),
+					ReserveTimeout: "1m",
+					DeadTimeout:    "10m",
+					ExpireTimeout:  "foo",
+					Partitions:     1,
+				}),
+				Msg: "expire timeout is invalid; time: invalid duration \"foo\" - expected format: 30s, 5m or 1h",
+			},
+			{
+				Name: "NegativeExpireTimeout",
+				Err: c.QueuesCreate(ctx, &pb.QueueInfo{
+					QueueName:      random.String("queue-", 10),
+					ReserveTimeout: "1m",
+					DeadTimeout:    "10m",
+					ExpireTimeout:  "-1m",
+					Partitions:     1,
+				}),
+				Msg: "expire timeout is invalid; cannot be negative duration",
+			},
+		} {
+			t.Run(test.Name, func(t *testing.T) {
+				var e duh.Error
+				require.True(t, errors.As(test.Err, &e))
+				assert.Equal(t, test.Msg, e.Message())
+				assert.Equal(t, duh.CodeBadRequest, e.Code())
+			})
+		}
+	})
+}
@@ -0,0 +1,320 @@
+package querator_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/duh-rpc/duh-go"
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtend(t *testing.T) {
+	bdb := boltTestSetup{Dir: t.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			testExtend(t, tc.Setup, tc.TearDown)
+		})
+	}
+}
+
+func testExtend(t *testing.T, setup NewStorageFunc, tearDown func()) {
+	t.Run("Extend", func(t *testing.T) {
+		var queueName = random.String("queue-", 10)
+		cp := clock.NewProvider()
+		cp.Freeze(clock.Now())
+		_store := setup(cp)
+		defer tearDown()
+		d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store, Clock: cp})
+		defer d.Shutdown(t)
+
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      queueName,
+			ReserveTimeout: "1m",
+			DeadTimeout:    "10m",
+			Partitions:     1,
+		}))
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          randomProduceItems(4),
+		}))
+
+		var reserved pb.QueueReserveResponse
+		require.NoError(t, c.QueueReserve(ctx, &pb.QueueReserveRequest{
+			ClientId:       random.String("client-", 10),
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			BatchSize:      2,
+		}, &reserved))
+		require.Len(t, reserved.Items, 2)
+		ids := que.CollectIDs(reserved.Items)
+
+		extend := func(ids []string, timeout string) (*pb.QueueExtendResponse, error) {
+			var res pb.QueueExtendResponse
+			err := c.QueueExtend(ctx, &pb.QueueExtendRequest{
+				QueueName:      queueName,
+				RequestTimeout: "1m",
+				ReserveTimeout: timeout,
+				Ids:            ids,
+			}, &res)
+			return &res, err
+		}
+
+		t.Run("DefaultsToQueueReserveTimeout", func(t *testing.T) {
+			cp.Advance(30 * clock.Second)
+			res, err := extend(ids, "")
+			require.NoError(t, err)
+			assert.Equal(t, cp.Now().UTC().Add(clock.Minute), res.ReserveDeadline.AsTime())
+
+			var list pb.StorageQueueListResponse
+			require.NoError(t, c.StorageQueueList(ctx, queueName, &list, nil))
+			for _, item := range list.Items {
+				if item.IsReserved {
+					assert.Equal(t, res.ReserveDeadline.AsTime(), item.ReserveDeadline.AsTime())
+				}
+			}
+		})
+
+		t.Run("ReserveTimeout", func(t *testing.T) {
+			res, err := extend(ids, "5m")
+			require.NoError(t, err)
+			assert.Equal(t, cp.Now().UTC().Add(5*clock.Minute), res.ReserveDeadline.AsTime())
+
+			// The reservation is still valid after the original reserve timeout has passed
+			cp.Advance(2 * clock.Minute)
+			_, err = extend(ids, "")
+			require.NoError(t, err)
+		})
+
+		t.Run("NotReserved", func(t *testing.T) {
+			var list pb.StorageQueueListResponse
+			require.NoError(t, c.StorageQueueList(ctx, queueName, &list, nil))
+			var notReserved string
+			for _, item := range list.Items {
+				if !item.IsReserved {
+					notReserved = item.Id
+				}
+			}
+			require.NotEmpty(t, notReserved)
+
+			// None of the ids are extended if any id cannot be extended
+			before := cp.Now()
+			cp.Advance(10 * clock.Second)
+			_, err := extend([]string{ids[0], notReserved}, "5m")
+			require.Error(t, err)
+			var e duh.Error
+			require.True(t, errors.As(err, &e))
+			assert.Equal(t, duh.CodeBadRequest, e.Code())
+			assert.Contains(t, e.Message(), "item(s) cannot be extended;")
+			assert.Contains(t, e.Message(), "is not marked as reserved")
+
+			require.NoError(t, c.StorageQueueList(ctx, queueName, &list, nil))
+			for _, item := range list.Items {
+				if item.Id == ids[0] {
+					assert.Equal(t, before.UTC().Add(clock.Minute), item.ReserveDeadline.AsTime())
+				}
+			}
+		})
+
+		t.Run("Expired", func(t *testing.T) {
+			cp.Advance(2 * clock.Minute)
+			_, err := extend(ids, "")
+			require.Error(t, err)
+			var e duh.Error
+			require.True(t, errors.As(err, &e))
+			assert.Equal(t, duh.CodeBadRequest, e.Code())
+			assert.Contains(t, e.Message(), "reservation has expired")
+		})
+
+		t.Run("Errors", func(t *testing.T) {
+			for _, tc := range []struct {
+				Name string
+				Req  *pb.QueueExtendRequest
+				Msg  string
+			}{
+				{
+					Name: "IdsCannotBeEmpty",
+					Req: &pb.QueueExtendRequest{
+						QueueName:      queueName,
+						RequestTimeout: "1m",
+					},
+					Msg: "ids is invalid; list of ids cannot be empty",
+				},
+				{
+					Name: "RequestTimeoutRequired",
+					Req: &pb.QueueExtendRequest{
+						QueueName: queueName,
+						Ids:       ids,
+					},
+					Msg: "request timeout is required; '5m' is recommended, 15m is the maximum",
+				},
+				{
+					Name: "ReserveTimeoutInvalid",
+					Req: &pb.QueueExtendRequest{
+						QueueName:      queueName,
+						RequestTimeout: "1m",
+						ReserveTimeout: "foo",
+						Ids:            ids,
+					},
+					Msg: "reserve timeout is invalid; time: invalid duration \"foo\"",
+				},
+				{
+					Name: "ReserveTimeoutTooLong",
+					Req: &pb.QueueExtendRequest{
+						QueueName:      queueName,
+						RequestTimeout: "1m",
+						ReserveTimeout: "11m",
+						Ids:            ids,
+					},
+					Msg: "reserve timeout is too long; 11m0s cannot be greater than the dead timeout 10m0s",
+				},
+				{
+					Name: "InvalidIds",
+					Req: &pb.QueueExtendRequest{
+						QueueName:      queueName,
+						RequestTimeout: "1m",
+						Ids:            []string{"invalid-id"},
+					},
+					Msg: "invalid storage id; 'invalid-id'",
+				},
+			} {
+				t.Run(tc.Name, func(t *testing.T) {
+					var res pb.QueueExtendResponse
+					err := c.QueueExtend(ctx, tc.Req, &res)
+					require.Error(t, err)
+					var e duh.Error
+					require.True(t, errors.As(err, &e))
+					assert.Contains(t, e.Message(), tc.Msg)
+					assert.Equal(t, duh.CodeBadRequest, e.Code())
+				})
+			}
+		})
+	})
+
+	t.Run("ItemReserveTimeout", func(t *testing.T) {
+		var queueName = random.String("queue-", 10)
+		cp := clock.NewProvider()
+		cp.Freeze(clock.Now())
+		_store := setup(cp)
+		defer tearDown()
+		d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store, Clock: cp})
+		defer d.Shutdown(t)
+
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      queueName,
+			ReserveTimeout: "1m",
+			DeadTimeout:    "10m",
+			Partitions:     1,
+		}))
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          []*pb.QueueProduceItem{{Utf8: "a"}, {Utf8: "b", ReserveTimeout: "10s"}},
+		}))
+
+		var reserved pb.QueueReserveResponse
+		require.NoError(t, c.QueueReserve(ctx, &pb.QueueReserveRequest{
+			ClientId:       random.String("client-", 10),
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			BatchSize:      2,
+		}, &reserved))
+		require.Len(t, reserved.Items, 2)
+
+		// The earliest of the new deadlines is returned
+		cp.Advance(5 * clock.Second)
+		var res pb.QueueExtendResponse
+		require.NoError(t, c.QueueExtend(ctx, &pb.QueueExtendRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Ids:            que.CollectIDs(reserved.Items),
+		}, &res))
+		assert.Equal(t, cp.Now().UTC().Add(10*clock.Second), res.ReserveDeadline.AsTime())
+	})
+
+	t.Run("Consumer", func(t *testing.T) {
+		var queueName = random.String("queue-", 10)
+		_store := setup(clock.NewProvider())
+		defer tearDown()
+		d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store})
+		defer d.Shutdown(t)
+
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      queueName,
+			ReserveTimeout: "500ms",
+			DeadTimeout:    "10m",
+			Partitions:     1,
+		}))
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          randomProduceItems(2),
+		}))
+
+		// The handler runs longer than the reserve timeout of the queue
+		handled := make(chan error, 2)
+		consumer, err := que.NewConsumer(que.ConsumerConfig{
+			Client:         c,
+			QueueName:      queueName,
+			BatchSize:      2,
+			RequestTimeout: 100 * clock.Millisecond,
+			ExtendInterval: 100 * clock.Millisecond,
+			Logger:         log,
+			Handler: func(ctx context.Context, item *pb.QueueReserveItem) error {
+				select {
+				case <-clock.After(750 * clock.Millisecond):
+					handled <- nil
+				case <-ctx.Done():
+					handled <- ctx.Err()
+				}
+				return ctx.Err()
+			},
+		})
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			select {
+			case err := <-handled:
+				require.NoError(t, err)
+			case <-clock.After(5 * clock.Second):
+				t.Fatal("handler was not called")
+			}
+		}
+
+		require.Eventually(t, func() bool {
+			var stats pb.QueueStatsResponse
+			require.NoError(t, c.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: queueName}, &stats))
+			return stats.Total == 0
+		}, 5*clock.Second, 10*clock.Millisecond)
+		require.NoError(t, consumer.Shutdown(ctx))
+	})
+}
@@ -0,0 +1,151 @@
+package querator_test
+
+import (
+	"fmt"
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/querator/transport"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHealthCheck(t *testing.T) {
+	bdb := boltTestSetup{Dir: t.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			testHealthCheck(t, tc.Setup, tc.TearDown)
+		})
+	}
+}
+
+func testHealthCheck(t *testing.T, setup NewStorageFunc, tearDown func()) {
+	t.Run("Ready", func(t *testing.T) {
+		_store := setup(clock.NewProvider())
+		defer tearDown()
+		d, _, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store})
+		defer d.Shutdown(t)
+
+		var res pb.HealthCheckResponse
+		require.NoError(t, d.d.Service().HealthCheck(ctx, &res))
+		assert.Equal(t, que.HealthPass, res.Status)
+		require.Len(t, res.Checks, 3)
+		assert.Equal(t, "service", res.Checks[0].Name)
+		assert.Equal(t, "queue-store", res.Checks[1].Name)
+		assert.Equal(t, "backend/"+_store.Backends[0].Name, res.Checks[2].Name)
+		for _, c := range res.Checks {
+			assert.Equal(t, que.HealthPass, c.Status, c.Name)
+		}
+
+		code, health := getHealth(t, d, transport.PathReadiness)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, que.HealthPass, health.Status)
+		assert.Len(t, health.Checks, 3)
+
+		code, health = getHealth(t, d, transport.PathLiveness)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, que.HealthPass, health.Status)
+	})
+
+	t.Run("BackendUnreachable", func(t *testing.T) {
+		_store := setup(clock.NewProvider())
+		defer tearDown()
+
+		dir := filepath.Join(t.TempDir(), "unreachable")
+		require.NoError(t, os.Mkdir(dir, 0777))
+		_store.Backends = append(_store.Backends, store.Backend{
+			PartitionStore: store.NewBoltPartitionStore(store.BoltConfig{StorageDir: dir}),
+			Name:           "unreachable",
+		})
+		d, _, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store})
+		defer d.Shutdown(t)
+
+		code, _ := getHealth(t, d, transport.PathReadiness)
+		assert.Equal(t, http.StatusOK, code)
+
+		// Simulate the backend storage becoming unavailable
+		require.NoError(t, os.RemoveAll(dir))
+
+		var res pb.HealthCheckResponse
+		err := d.d.Service().HealthCheck(ctx, &res)
+		require.Error(t, err)
+		assert.Equal(t, "health check failed; 'backend/unreachable' failed", err.Error())
+		assert.Equal(t, que.HealthFail, res.Status)
+		require.Len(t, res.Checks, 4)
+		assert.Equal(t, que.HealthPass, res.Checks[2].Status)
+		assert.Equal(t, que.HealthFail, res.Checks[3].Status)
+		assert.Contains(t, res.Checks[3].Message, "while accessing storage dir")
+
+		code, health := getHealth(t, d, transport.PathReadiness)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, que.HealthFail, health.Status)
+
+		// The process is still alive
+		code, _ = getHealth(t, d, transport.PathLiveness)
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("Shutdown", func(t *testing.T) {
+		_store := setup(clock.NewProvider())
+		defer tearDown()
+		d, _, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store})
+		defer d.Shutdown(t)
+
+		// The HTTP server continues to serve requests until the service has shutdown
+		require.NoError(t, d.d.Service().Shutdown(ctx))
+
+		var res pb.HealthCheckResponse
+		err := d.d.Service().HealthCheck(ctx, &res)
+		require.Error(t, err)
+		assert.Equal(t, "health check failed; 'service' failed", err.Error())
+		require.Len(t, res.Checks, 1)
+		assert.Equal(t, que.MsgServiceInShutdown, res.Checks[0].Message)
+
+		code, health := getHealth(t, d, transport.PathReadiness)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, que.HealthFail, health.Status)
+	})
+}
+
+func getHealth(t *testing.T, d *testDaemon, path string) (int, *pb.HealthCheckResponse) {
+	t.Helper()
+
+	resp, err := http.Get(fmt.Sprintf("http://%s%s", d.d.Listener.Addr().String(), path))
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	var res pb.HealthCheckResponse
+	require.NoError(t, protojson.Unmarshal(b, &res), string(b))
+	return resp.StatusCode, &res
+}
@@ -0,0 +1,185 @@
+package internal
+
+import (
+	"sort"
+
+	"github.com/kapetan-io/querator/internal/types"
+	"github.com/kapetan-io/tackle/clock"
+)
+
+// consumer is a client registered as a consumer of the queue
+type consumer struct {
+	info types.ConsumerInfo
+	// reserved maps the ids of items reserved by the consumer which have not been completed or
+	// released to the reserve deadline of the item
+	reserved map[string]clock.Time
+}
+
+// consumers tracks the consumers registered with a Logical queue along with the items each consumer
+// has reserved. It is owned by the sync loop and is not thread safe.
+type consumers struct {
+	byClient map[string]*consumer
+	// owners maps the id of each tracked item to the ClientID of the consumer which reserved it
+	owners map[string]string
+}
+
+// register adds the consumer or refreshes the consumer if the client has already registered
+func (c *consumers) register(clientID string, timeout clock.Duration, now clock.Time) types.ConsumerInfo {
+	if c.byClient == nil {
+		c.byClient = make(map[string]*consumer)
+		c.owners = make(map[string]string)
+	}
+
+	con, ok := c.byClient[clientID]
+	if !ok {
+		con = &consumer{
+			info:     types.ConsumerInfo{ClientID: clientID, RegisteredAt: now},
+			reserved: make(map[string]clock.Time),
+		}
+		c.byClient[clientID] = con
+	}
+	con.info.HeartbeatTimeout = timeout
+	con.touch(now)
+	return con.info
+}
+
+// heartbeat refreshes the heartbeat deadline of the consumer, returns false if the client is not registered
+func (c *consumers) heartbeat(clientID string, now clock.Time) (types.ConsumerInfo, bool) {
+	con, ok := c.byClient[clientID]
+	if !ok {
+		return types.ConsumerInfo{}, false
+	}
+	con.touch(now)
+	return con.info, true
+}
+
+// deregister removes the consumer, returning the ids of the items the consumer still has reserved
+func (c *consumers) deregister(clientID string, now clock.Time) [][]byte {
+	con, ok := c.byClient[clientID]
+	if !ok {
+		return nil
+	}
+	delete(c.byClient, clientID)
+	return c.untrack(con, now)
+}
+
+// reserve records the items reserved by the client if the client is a registered consumer.
+// A reservation counts as a heartbeat.
+func (c *consumers) reserve(clientID string, items []*types.Item, now clock.Time) {
+	// An item might be reserved again after the reservation held by a consumer expired, in which
+	// case the consumer no longer owns the item regardless of who reserved it this time.
+	c.done(itemIDs(items)...)
+
+	con, ok := c.byClient[clientID]
+	if !ok {
+		return
+	}
+	con.touch(now)
+	for _, item := range items {
+		con.reserved[string(item.ID)] = item.ReserveDeadline
+		c.owners[string(item.ID)] = clientID
+	}
+}
+
+// extend updates the reserve deadline of the ids provided, deadlines are in the same order as ids
+func (c *consumers) extend(ids [][]byte, deadlines []clock.Time) {
+	for i, id := range ids {
+		owner, ok := c.owners[string(id)]
+		if !ok {
+			continue
+		}
+		if con, ok := c.byClient[owner]; ok {
+			con.reserved[string(id)] = deadlines[i]
+		}
+	}
+}
+
+// done stops tracking the ids provided, as they have been completed or released
+func (c *consumers) done(ids ...[]byte) {
+	for _, id := range ids {
+		owner, ok := c.owners[string(id)]
+		if !ok {
+			continue
+		}
+		delete(c.owners, string(id))
+		if con, ok := c.byClient[owner]; ok {
+			delete(con.reserved, string(id))
+		}
+	}
+}
+
+// expire removes all consumers whose heartbeat deadline has passed, returning the ids
+// of the items the expired consumers still have reserved keyed by ClientID.
+func (c *consumers) expire(now clock.Time) map[string][][]byte {
+	var expired map[string][][]byte
+	for clientID, con := range c.byClient {
+		if now.Before(con.info.HeartbeatDeadline) {
+			continue
+		}
+		if expired == nil {
+			expired = make(map[string][][]byte)
+		}
+		delete(c.byClient, clientID)
+		expired[clientID] = c.untrack(con, now)
+	}
+	return expired
+}
+
+// next returns how long until the next consumer heartbeat deadline, or zero if there are no consumers
+func (c *consumers) next(now clock.Time) clock.Duration {
+	var soon clock.Time
+	for _, con := range c.byClient {
+		if soon.IsZero() || con.info.HeartbeatDeadline.Before(soon) {
+			soon = con.info.HeartbeatDeadline
+		}
+	}
+	if soon.IsZero() {
+		return clock.Duration(0)
+	}
+	return soon.Sub(now)
+}
+
+// list returns information about each consumer ordered by ClientID
+func (c *consumers) list(now clock.Time) []types.ConsumerInfo {
+	results := make([]types.ConsumerInfo, 0, len(c.byClient))
+	for _, con := range c.byClient {
+		info := con.info
+		for _, deadline := range con.reserved {
+			// Reservations which have expired are no longer held by the consumer
+			if now.Before(deadline) {
+				info.Reserved++
+			}
+		}
+		results = append(results, info)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ClientID < results[j].ClientID
+	})
+	return results
+}
+
+// untrack removes the ownership of all the items reserved by the consumer, returning the ids
+// of the items whose reservations have not yet expired.
+func (c *consumers) untrack(con *consumer, now clock.Time) [][]byte {
+	ids := make([][]byte, 0, len(con.reserved))
+	for id, deadline := range con.reserved {
+		delete(c.owners, id)
+		if now.Before(deadline) {
+			ids = append(ids, []byte(id))
+		}
+	}
+	return ids
+}
+
+func itemIDs(items []*types.Item) [][]byte {
+	ids := make([][]byte, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func (con *consumer) touch(now clock.Time) {
+	con.info.LastActivity = now
+	con.info.HeartbeatDeadline = now.Add(con.info.HeartbeatTimeout)
+}
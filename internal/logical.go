@@ -6,13 +6,20 @@ import (
 	"github.com/duh-rpc/duh-go"
 	"github.com/kapetan-io/querator/internal/store"
 	"github.com/kapetan-io/querator/internal/types"
+	pb "github.com/kapetan-io/querator/proto"
 	"github.com/kapetan-io/querator/transport"
 	"github.com/kapetan-io/tackle/clock"
 	"github.com/kapetan-io/tackle/set"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"hash/fnv"
 	"log/slog"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 const (
@@ -26,47 +33,110 @@ const (
 	MethodQueueClear
 	MethodUpdateInfo
 	MethodUpdatePartitions
+	MethodRefreshUsage
+	MethodDrain
+	MethodQueuePeek
+	MethodQueueGet
+	MethodConsumerRegister
+	MethodConsumerHeartbeat
+	MethodConsumerDeregister
+	MethodConsumerList
 
 	DefaultMaxReserveBatchSize  = 1_000
 	DefaultMaxProduceBatchSize  = 1_000
 	DefaultMaxCompleteBatchSize = 1_000
 	DefaultMaxRequestsPerQueue  = 500
+	DefaultMaxItemSize          = 512 * 1024
+	DefaultDedupWindow          = 5 * clock.Minute
+	DefaultMaxReserveSize       = 4 * 1024 * 1024
+	DefaultHeartbeatTimeout     = 30 * clock.Second
+	maxHeartbeatTimeout         = 15 * clock.Minute
+	// statsRefreshTimeout is how long CachedStats() will wait for the sync loop to fetch new stats
+	statsRefreshTimeout = 5 * clock.Second
 
 	MsgRequestTimeout    = "request timeout; no items are in the queue, try again"
 	MsgDuplicateClientID = "duplicate client id; a client cannot make multiple reserve requests to the same queue"
 	MsgQueueInShutdown   = "queue is shutting down"
 	MsgQueueOverLoaded   = "queue is overloaded; try again later"
+	MsgServiceDraining   = "service is draining; retry the request with another instance"
+	MsgNotRegistered     = "consumer is not registered; register the client id before sending heartbeats"
 )
 
 var (
-	ErrQueueShutdown  = transport.NewRequestFailed(MsgQueueInShutdown)
-	ErrRequestTimeout = transport.NewRetryRequest(MsgRequestTimeout)
-	ErrInternalRetry  = transport.NewRetryRequest("internal error, try your request again")
+	ErrQueueShutdown   = transport.NewRequestFailed(MsgQueueInShutdown)
+	ErrRequestTimeout  = transport.NewRetryRequest(MsgRequestTimeout)
+	ErrServiceDraining = transport.NewRetryRequest(MsgServiceDraining)
+	ErrInternalRetry   = transport.NewRetryRequest("internal error, try your request again")
 )
 
 type LogicalConfig struct {
 	types.QueueInfo
 
+	// Limits are the initial limits and timeouts enforced by the queue, they can be changed while
+	// the queue is running via UpdateLimits()
+	Limits
+
 	// If defined, is the logger used by the queue
 	Logger duh.StandardLogger
-	// TODO: Make these configurable at the Service level
+	// Clock is the clock provider used to calculate the current time
+	Clock *clock.Provider
+	// Tracer is used to create spans for each batch processed by the queue
+	Tracer trace.Tracer
+	// Metrics are the prometheus metrics the queue records to
+	Metrics *QueueMetrics
+	// The initial partitions provided to the LogicalQueue at initialization.
+	Partitions []store.Partition
+}
+
+// Limits are the limits and timeouts enforced by a Logical queue
+type Limits struct {
 	// WriteTimeout The time it should take for a single batched write to complete
 	WriteTimeout clock.Duration
 	// ReadTimeout The time it should take for a single batched read to complete
 	ReadTimeout clock.Duration
-	// MaxReserveBatchSize is the maximum number of items a client can request in a single reserve request
+	// MaxReserveBatchSize is the maximum number of items a client can request in a single reserve request.
+	// It also limits the number of items returned by a single peek request.
 	MaxReserveBatchSize int
 	// MaxProduceBatchSize is the maximum number of items a client can produce in a single produce request
 	MaxProduceBatchSize int
-	// MaxCompleteBatchSize is the maximum number of ids a client can mark complete in a single complete request
+	// MaxCompleteBatchSize is the maximum number of ids a client can mark complete in a single complete request.
+	// It also limits the number of ids in a single extend, release or get request.
 	MaxCompleteBatchSize int
 	// MaxRequestsPerQueue is the maximum number of client requests a queue can handle before it returns an
-	// queue overloaded message
+	// queue overloaded message. It can only be lowered while the queue is running, an increase beyond the
+	// value the queue was started with takes effect the next time the queue is started.
 	MaxRequestsPerQueue int
-	// Clock is the clock provider used to calculate the current time
-	Clock *clock.Provider
-	// The initial partitions provided to the LogicalQueue at initialization.
-	Partitions []store.Partition
+	// MaxReserveSize is the maximum number of item bytes returned by a single reserve request
+	MaxReserveSize int
+}
+
+// validate returns an error if any of the limits are invalid
+func (l *Limits) validate() error {
+	for _, v := range []struct {
+		name  string
+		value int64
+	}{
+		{"write timeout", int64(l.WriteTimeout)},
+		{"read timeout", int64(l.ReadTimeout)},
+		{"max_reserve_batch_size", int64(l.MaxReserveBatchSize)},
+		{"max_produce_batch_size", int64(l.MaxProduceBatchSize)},
+		{"max_complete_batch_size", int64(l.MaxCompleteBatchSize)},
+		{"max_requests_per_queue", int64(l.MaxRequestsPerQueue)},
+		{"max_reserve_size", int64(l.MaxReserveSize)},
+	} {
+		if v.value < 0 {
+			return transport.NewInvalidOption("%s is invalid; cannot be negative", v.name)
+		}
+	}
+	return nil
+}
+
+func (l *Limits) setDefaults() {
+	set.Default(&l.MaxReserveBatchSize, DefaultMaxReserveBatchSize)
+	set.Default(&l.MaxProduceBatchSize, DefaultMaxProduceBatchSize)
+	set.Default(&l.MaxCompleteBatchSize, DefaultMaxCompleteBatchSize)
+	set.Default(&l.MaxRequestsPerQueue, DefaultMaxRequestsPerQueue)
+	set.Default(&l.MaxReserveSize, DefaultMaxReserveSize)
 }
 
 // TODO: Modify the Logical to Handle many partitions
@@ -83,30 +153,64 @@ type Logical struct {
 	reserveQueueCh  chan *types.ReserveRequest
 	produceQueueCh  chan *types.ProduceRequest
 	completeQueueCh chan *types.CompleteRequest
+	extendQueueCh   chan *types.ExtendRequest
+	releaseQueueCh  chan *types.ReleaseRequest
 
 	shutdownCh     chan *types.ShutdownRequest
 	queueRequestCh chan *QueueRequest
 	wg             sync.WaitGroup
 	conf           LogicalConfig
+	metrics        queueMetrics
 	inFlight       atomic.Int32
 	inShutdown     atomic.Bool
+	// draining is true once Drain() is called, new produce and reserve requests are rejected
+	draining atomic.Bool
+	// info is a copy of conf.QueueInfo which is safe to read outside the sync loop
+	info atomic.Pointer[types.QueueInfo]
+	// limits are the limits currently enforced by the queue, see UpdateLimits()
+	limits atomic.Pointer[Limits]
+
+	// usageItems and usageBytes are the number of items and payload bytes currently held in
+	// storage by this queue. They are used by the QueuesManager to enforce namespace quotas.
+	usageItems atomic.Int64
+	usageBytes atomic.Int64
+	// usageStale is true when the usage counts might no longer reflect what is in storage
+	// (IE: after items are completed or deleted) and must be refreshed via RefreshUsage()
+	usageStale atomic.Bool
+	// usageLoaded is false until the first call to RefreshUsage(), as the usage counts do not
+	// include the items which were held in storage before the queue was started
+	usageLoaded atomic.Bool
+
+	// stats are the most recent QueueStats fetched by CachedStats()
+	stats atomic.Pointer[cachedStats]
+	// refreshingStats is true while CachedStats() is fetching new stats from the sync loop
+	refreshingStats atomic.Bool
+}
+
+type cachedStats struct {
+	types.QueueStats
+	FetchedAt clock.Time
 }
 
 func SpawnLogicalQueue(conf LogicalConfig) (*Logical, error) {
 	set.Default(&conf.Logger, slog.Default())
-	set.Default(&conf.MaxReserveBatchSize, DefaultMaxReserveBatchSize)
-	set.Default(&conf.MaxProduceBatchSize, DefaultMaxProduceBatchSize)
-	set.Default(&conf.MaxCompleteBatchSize, DefaultMaxCompleteBatchSize)
-	set.Default(&conf.MaxRequestsPerQueue, DefaultMaxRequestsPerQueue)
+	conf.Limits.setDefaults()
 	set.Default(&conf.Clock, clock.NewProvider())
+	set.Default(&conf.Tracer, noop.NewTracerProvider().Tracer(""))
+	set.Default(&conf.Metrics, NewQueueMetrics())
 
 	l := &Logical{
 		// Logical requests are any request that doesn't require special batch processing
 		queueRequestCh: make(chan *QueueRequest),
 		// Shutdowns require special handling in the sync loop
 		shutdownCh: make(chan *types.ShutdownRequest),
+		metrics:    conf.Metrics.forQueue(conf.Namespace, conf.Name),
 		conf:       conf,
 	}
+	// Usage is unknown until the first call to RefreshUsage()
+	l.usageStale.Store(true)
+	l.info.Store(&conf.QueueInfo)
+	l.limits.Store(&conf.Limits)
 
 	// These are request queues that queue requests from clients until the sync loop has
 	// time to process them. When they get processed, every request in the queue is handled
@@ -114,6 +218,8 @@ func SpawnLogicalQueue(conf LogicalConfig) (*Logical, error) {
 	l.reserveQueueCh = make(chan *types.ReserveRequest, conf.MaxRequestsPerQueue)
 	l.produceQueueCh = make(chan *types.ProduceRequest, conf.MaxRequestsPerQueue)
 	l.completeQueueCh = make(chan *types.CompleteRequest, conf.MaxRequestsPerQueue)
+	l.extendQueueCh = make(chan *types.ExtendRequest, conf.MaxRequestsPerQueue)
+	l.releaseQueueCh = make(chan *types.ReleaseRequest, conf.MaxRequestsPerQueue)
 
 	l.wg.Add(1)
 	go l.synchronizationLoop()
@@ -127,6 +233,9 @@ func (l *Logical) Produce(ctx context.Context, req *types.ProduceRequest) error
 	if l.inShutdown.Load() {
 		return ErrQueueShutdown
 	}
+	if l.draining.Load() {
+		return ErrServiceDraining
+	}
 	l.inFlight.Add(1)
 	defer l.inFlight.Add(-1)
 
@@ -148,9 +257,32 @@ func (l *Logical) Produce(ctx context.Context, req *types.ProduceRequest) error
 		return transport.NewInvalidOption("items cannot be empty; at least one item is required")
 	}
 
-	if len(req.Items) > l.conf.MaxProduceBatchSize {
+	limits := l.limits.Load()
+	if len(req.Items) > limits.MaxProduceBatchSize {
 		return transport.NewInvalidOption("items is invalid; max_produce_batch_size is"+
-			" %d but received %d", l.conf.MaxProduceBatchSize, len(req.Items))
+			" %d but received %d", limits.MaxProduceBatchSize, len(req.Items))
+	}
+
+	info := l.info.Load()
+	for _, item := range req.Items {
+		if item.DeadTimeout > info.DeadTimeout {
+			return transport.NewInvalidOption("item dead timeout is too long; %s cannot be greater than the "+
+				"queue dead timeout %s", item.DeadTimeout.String(), info.DeadTimeout.String())
+		}
+
+		deadTimeout := info.DeadTimeout
+		if item.DeadTimeout != clock.Duration(0) {
+			deadTimeout = item.DeadTimeout
+		}
+		if item.ReserveTimeout > deadTimeout {
+			return transport.NewInvalidOption("item reserve timeout is too long; %s cannot be greater than the "+
+				"dead timeout %s", item.ReserveTimeout.String(), deadTimeout.String())
+		}
+
+		if info.MaxAttempts != 0 && item.MaxAttempts > info.MaxAttempts {
+			return transport.NewInvalidOption("item max attempts is invalid; %d cannot be greater than the "+
+				"queue max attempts %d", item.MaxAttempts, info.MaxAttempts)
+		}
 	}
 
 	req.RequestDeadline = l.conf.Clock.Now().UTC().Add(req.RequestTimeout)
@@ -158,6 +290,9 @@ func (l *Logical) Produce(ctx context.Context, req *types.ProduceRequest) error
 	req.Context = ctx
 
 	fmt.Printf("Produce Len: %d \n", len(l.produceQueueCh))
+	if len(l.produceQueueCh) >= limits.MaxRequestsPerQueue {
+		return transport.NewRetryRequest(MsgQueueOverLoaded)
+	}
 	select {
 	case l.produceQueueCh <- req:
 	default:
@@ -188,6 +323,9 @@ func (l *Logical) Reserve(ctx context.Context, req *types.ReserveRequest) error
 	if l.inShutdown.Load() {
 		return ErrQueueShutdown
 	}
+	if l.draining.Load() {
+		return ErrServiceDraining
+	}
 	l.inFlight.Add(1)
 	defer l.inFlight.Add(-1)
 
@@ -199,9 +337,10 @@ func (l *Logical) Reserve(ctx context.Context, req *types.ReserveRequest) error
 		return transport.NewInvalidOption("invalid batch size; must be greater than zero")
 	}
 
-	if req.NumRequested > l.conf.MaxReserveBatchSize {
+	limits := l.limits.Load()
+	if req.NumRequested > limits.MaxReserveBatchSize {
 		return transport.NewInvalidOption("invalid batch size; max_reserve_batch_size is %d, "+
-			"but %d was requested", l.conf.MaxProduceBatchSize, req.NumRequested)
+			"but %d was requested", limits.MaxReserveBatchSize, req.NumRequested)
 	}
 
 	if req.RequestTimeout == clock.Duration(0) {
@@ -219,9 +358,13 @@ func (l *Logical) Reserve(ctx context.Context, req *types.ReserveRequest) error
 	}
 
 	req.RequestDeadline = l.conf.Clock.Now().UTC().Add(req.RequestTimeout)
+	req.MaxBytes = limits.MaxReserveSize
 	req.ReadyCh = make(chan struct{})
 	req.Context = ctx
 
+	if len(l.reserveQueueCh) >= limits.MaxRequestsPerQueue {
+		return transport.NewRetryRequest(MsgQueueOverLoaded)
+	}
 	select {
 	case l.reserveQueueCh <- req:
 	default:
@@ -247,9 +390,10 @@ func (l *Logical) Complete(ctx context.Context, req *types.CompleteRequest) erro
 		return transport.NewInvalidOption("ids is invalid; list of ids cannot be empty")
 	}
 
-	if len(req.Ids) > l.conf.MaxCompleteBatchSize {
+	limits := l.limits.Load()
+	if len(req.Ids) > limits.MaxCompleteBatchSize {
 		return transport.NewInvalidOption("ids is invalid; max_complete_batch_size is"+
-			" %d but received %d", l.conf.MaxCompleteBatchSize, len(req.Ids))
+			" %d but received %d", limits.MaxCompleteBatchSize, len(req.Ids))
 	}
 
 	if req.RequestTimeout > maxRequestTimeout {
@@ -265,6 +409,9 @@ func (l *Logical) Complete(ctx context.Context, req *types.CompleteRequest) erro
 	req.ReadyCh = make(chan struct{})
 	req.Context = ctx
 
+	if len(l.completeQueueCh) >= limits.MaxRequestsPerQueue {
+		return transport.NewRetryRequest(MsgQueueOverLoaded)
+	}
 	select {
 	case l.completeQueueCh <- req:
 	default:
@@ -276,6 +423,112 @@ func (l *Logical) Complete(ctx context.Context, req *types.CompleteRequest) erro
 	return req.Err
 }
 
+// Extend is called by clients who wish to extend the reservation of items they are still working on. The call
+// will block until the reservations have been extended or until the request is cancelled via the passed
+// context or RequestTimeout is reached.
+func (l *Logical) Extend(ctx context.Context, req *types.ExtendRequest) error {
+	if l.inShutdown.Load() {
+		return ErrQueueShutdown
+	}
+	l.inFlight.Add(1)
+	defer l.inFlight.Add(-1)
+
+	if len(req.Ids) == 0 {
+		return transport.NewInvalidOption("ids is invalid; list of ids cannot be empty")
+	}
+
+	limits := l.limits.Load()
+	if len(req.Ids) > limits.MaxCompleteBatchSize {
+		return transport.NewInvalidOption("ids is invalid; max_complete_batch_size is"+
+			" %d but received %d", limits.MaxCompleteBatchSize, len(req.Ids))
+	}
+
+	if req.RequestTimeout > maxRequestTimeout {
+		return transport.NewInvalidOption("request timeout is invalid; maximum timeout is '15m' but '%s' "+
+			"requested", req.RequestTimeout.String())
+	}
+
+	if req.RequestTimeout == clock.Duration(0) {
+		return transport.NewInvalidOption("request timeout is required; '5m' is recommended, 15m is the maximum")
+	}
+
+	info := l.info.Load()
+	if req.ReserveTimeout > info.DeadTimeout {
+		return transport.NewInvalidOption("reserve timeout is too long; %s cannot be greater than the "+
+			"dead timeout %s", req.ReserveTimeout.String(), info.DeadTimeout.String())
+	}
+
+	req.RequestDeadline = l.conf.Clock.Now().UTC().Add(req.RequestTimeout)
+	req.ReadyCh = make(chan struct{})
+	req.Context = ctx
+
+	if len(l.extendQueueCh) >= limits.MaxRequestsPerQueue {
+		return transport.NewRetryRequest(MsgQueueOverLoaded)
+	}
+	select {
+	case l.extendQueueCh <- req:
+	default:
+		return transport.NewRetryRequest(MsgQueueOverLoaded)
+	}
+
+	// Wait until the request has been processed
+	<-req.ReadyCh
+	return req.Err
+}
+
+// Release is called by clients who wish to give up the reservation of items without waiting for the
+// reservation to expire. Released items keep their position in the queue and can be reserved again
+// immediately. The call will block until the items have been released or until the request is cancelled
+// via the passed context or RequestTimeout is reached.
+func (l *Logical) Release(ctx context.Context, req *types.ReleaseRequest) error {
+	if l.inShutdown.Load() {
+		return ErrQueueShutdown
+	}
+	l.inFlight.Add(1)
+	defer l.inFlight.Add(-1)
+
+	if len(req.Ids) == 0 {
+		return transport.NewInvalidOption("ids is invalid; list of ids cannot be empty")
+	}
+
+	limits := l.limits.Load()
+	if len(req.Ids) > limits.MaxCompleteBatchSize {
+		return transport.NewInvalidOption("ids is invalid; max_complete_batch_size is"+
+			" %d but received %d", limits.MaxCompleteBatchSize, len(req.Ids))
+	}
+
+	if req.RequestTimeout > maxRequestTimeout {
+		return transport.NewInvalidOption("request timeout is invalid; maximum timeout is '15m' but '%s' "+
+			"requested", req.RequestTimeout.String())
+	}
+
+	if req.RequestTimeout == clock.Duration(0) {
+		return transport.NewInvalidOption("request timeout is required; '5m' is recommended, 15m is the maximum")
+	}
+
+	req.RequestDeadline = l.conf.Clock.Now().UTC().Add(req.RequestTimeout)
+	req.ReadyCh = make(chan struct{})
+	req.Context = ctx
+
+	if len(l.releaseQueueCh) >= limits.MaxRequestsPerQueue {
+		return transport.NewRetryRequest(MsgQueueOverLoaded)
+	}
+	select {
+	case l.releaseQueueCh <- req:
+	default:
+		return transport.NewRetryRequest(MsgQueueOverLoaded)
+	}
+
+	// Wait until the request has been processed
+	<-req.ReadyCh
+	return req.Err
+}
+
+// Info returns the current QueueInfo of the queue
+func (l *Logical) Info() types.QueueInfo {
+	return *l.info.Load()
+}
+
 // QueueStats retrieves stats about the queue and items in storage
 func (l *Logical) QueueStats(ctx context.Context, stats *types.QueueStats) error {
 	r := QueueRequest{
@@ -285,6 +538,34 @@ func (l *Logical) QueueStats(ctx context.Context, stats *types.QueueStats) error
 	return l.queueRequest(ctx, &r)
 }
 
+// CachedStats returns the most recently fetched stats of the queue without waiting on the sync loop. If the
+// stats are older than maxAge, new stats are fetched in the background and are returned by the next call.
+// Returns false if the stats have not yet been fetched.
+func (l *Logical) CachedStats(maxAge clock.Duration) (types.QueueStats, bool) {
+	cached := l.stats.Load()
+	if cached == nil || l.conf.Clock.Now().UTC().Sub(cached.FetchedAt) >= maxAge {
+		if l.refreshingStats.CompareAndSwap(false, true) {
+			go l.refreshStats()
+		}
+	}
+	if cached == nil {
+		return types.QueueStats{}, false
+	}
+	return cached.QueueStats, true
+}
+
+func (l *Logical) refreshStats() {
+	defer l.refreshingStats.Store(false)
+	ctx, cancel := context.WithTimeout(context.Background(), statsRefreshTimeout)
+	defer cancel()
+
+	var stats types.QueueStats
+	if err := l.QueueStats(ctx, &stats); err != nil {
+		return
+	}
+	l.stats.Store(&cachedStats{QueueStats: stats, FetchedAt: l.conf.Clock.Now().UTC()})
+}
+
 // Pause pauses processing of produce, reserve, complete and defer operations until the pause is cancelled.
 // It is only used for testing and not exposed to the user via API, as such it is not considered apart of
 // the public API.
@@ -320,6 +601,38 @@ func (l *Logical) UpdateInfo(ctx context.Context, info types.QueueInfo) error {
 	return l.queueRequest(ctx, &r)
 }
 
+// UpdateLimits changes the limits and timeouts enforced by the queue. Requests already accepted by
+// the queue are not affected by the change. MaxRequestsPerQueue can only be lowered while the queue
+// is running, as the request channels are sized when the queue is spawned.
+func (l *Logical) UpdateLimits(limits Limits) {
+	limits.setDefaults()
+	if limits.MaxRequestsPerQueue > cap(l.produceQueueCh) {
+		limits.MaxRequestsPerQueue = cap(l.produceQueueCh)
+	}
+	l.limits.Store(&limits)
+}
+
+// Limits returns the limits currently enforced by the queue
+func (l *Logical) Limits() Limits {
+	return *l.limits.Load()
+}
+
+// Drain stops the queue from accepting new produce and reserve requests. Requests already accepted
+// are processed as normal, except reserve requests waiting for items to be produced are returned
+// without items. Complete requests are still accepted, such that consumers may complete their
+// reservations before the queue is shutdown.
+func (l *Logical) Drain(ctx context.Context) error {
+	r := QueueRequest{
+		Method: MethodDrain,
+	}
+	return l.queueRequest(ctx, &r)
+}
+
+// InFlight returns the number of client requests currently being processed by the queue
+func (l *Logical) InFlight() int {
+	return int(l.inFlight.Load())
+}
+
 // UpdatePartitions is called whenever the list of partitions this Logical Queue is responsible for changes.
 // It is called during initialization of Logical struct and is intended to be called whenever the QueueManager
 // rebalances partitions
@@ -331,6 +644,26 @@ func (l *Logical) UpdatePartitions(ctx context.Context, p []store.Partition) err
 	return l.queueRequest(ctx, &r)
 }
 
+// Usage returns the number of items and payload bytes this queue holds in storage. If stale is
+// true, the counts may be higher than what is actually in storage and RefreshUsage() should be called.
+func (l *Logical) Usage() (items, bytes int64, stale bool) {
+	return l.usageItems.Load(), l.usageBytes.Load(), l.usageStale.Load()
+}
+
+// UsageLoaded returns false if the usage counts returned by Usage() have not yet been loaded from
+// the partitions, in which case the counts do not include the items held before the queue started.
+func (l *Logical) UsageLoaded() bool {
+	return l.usageLoaded.Load()
+}
+
+// RefreshUsage updates the usage counts returned by Usage() by inspecting the partitions
+func (l *Logical) RefreshUsage(ctx context.Context) error {
+	r := QueueRequest{
+		Method: MethodRefreshUsage,
+	}
+	return l.queueRequest(ctx, &r)
+}
+
 // -------------------------------------------------
 // Methods to manage queue storage
 // -------------------------------------------------
@@ -350,6 +683,115 @@ func (l *Logical) StorageQueueList(ctx context.Context, items *[]*types.Item, op
 	return l.queueRequest(ctx, &r)
 }
 
+// Peek returns up to limit items in the order they would be reserved, without reserving them
+func (l *Logical) Peek(ctx context.Context, items *[]*types.Item, limit int) error {
+	if limit <= 0 {
+		return transport.NewInvalidOption("invalid batch size; must be greater than zero")
+	}
+
+	limits := l.limits.Load()
+	if limit > limits.MaxReserveBatchSize {
+		return transport.NewInvalidOption("invalid batch size; max_reserve_batch_size is %d, "+
+			"but %d was requested", limits.MaxReserveBatchSize, limit)
+	}
+
+	r := QueueRequest{
+		Method: MethodQueuePeek,
+		Request: StorageRequest{
+			Items:   items,
+			Options: types.ListOptions{Limit: limit},
+		},
+	}
+	return l.queueRequest(ctx, &r)
+}
+
+// Get returns the items for the ids provided without reserving them
+func (l *Logical) Get(ctx context.Context, ids []types.ItemID, items *[]*types.Item) error {
+	if len(ids) == 0 {
+		return transport.NewInvalidOption("ids is invalid; list of ids cannot be empty")
+	}
+
+	limits := l.limits.Load()
+	if len(ids) > limits.MaxCompleteBatchSize {
+		return transport.NewInvalidOption("ids is invalid; max_complete_batch_size is"+
+			" %d but received %d", limits.MaxCompleteBatchSize, len(ids))
+	}
+
+	r := QueueRequest{
+		Method: MethodQueueGet,
+		Request: StorageRequest{
+			Items: items,
+			IDs:   ids,
+		},
+	}
+	return l.queueRequest(ctx, &r)
+}
+
+// Register registers the client as a consumer of the queue. Registered consumers must send a heartbeat
+// or reserve items before HeartbeatTimeout elapses, else the consumer is considered gone and any items
+// it has reserved are released so they can be reserved by other consumers. Registering a client which is
+// already registered refreshes the registration. See doc/adr/0019-consumer-registration.md
+func (l *Logical) Register(ctx context.Context, req *types.ConsumerRequest) error {
+	if strings.TrimSpace(req.ClientID) == "" {
+		return transport.NewInvalidOption("invalid client id; cannot be empty")
+	}
+
+	if req.HeartbeatTimeout == clock.Duration(0) {
+		req.HeartbeatTimeout = DefaultHeartbeatTimeout
+	}
+
+	if req.HeartbeatTimeout < 0 {
+		return transport.NewInvalidOption("heartbeat timeout is invalid; cannot be negative duration")
+	}
+
+	if req.HeartbeatTimeout > maxHeartbeatTimeout {
+		return transport.NewInvalidOption("heartbeat timeout is invalid; maximum timeout is '15m' but '%s' "+
+			"requested", req.HeartbeatTimeout.String())
+	}
+
+	r := QueueRequest{
+		Method:  MethodConsumerRegister,
+		Request: req,
+	}
+	return l.queueRequest(ctx, &r)
+}
+
+// Heartbeat informs the queue the registered consumer is still alive
+func (l *Logical) Heartbeat(ctx context.Context, req *types.ConsumerRequest) error {
+	if strings.TrimSpace(req.ClientID) == "" {
+		return transport.NewInvalidOption("invalid client id; cannot be empty")
+	}
+
+	r := QueueRequest{
+		Method:  MethodConsumerHeartbeat,
+		Request: req,
+	}
+	return l.queueRequest(ctx, &r)
+}
+
+// Deregister removes the consumer from the queue, any items the consumer has reserved are released
+// without counting the release as an attempt. Deregistering a client which is not registered is not an error.
+func (l *Logical) Deregister(ctx context.Context, req *types.ConsumerRequest) error {
+	if strings.TrimSpace(req.ClientID) == "" {
+		return transport.NewInvalidOption("invalid client id; cannot be empty")
+	}
+
+	r := QueueRequest{
+		Method:  MethodConsumerDeregister,
+		Request: req,
+	}
+	return l.queueRequest(ctx, &r)
+}
+
+// Consumers returns the consumers currently registered with the queue ordered by ClientID
+func (l *Logical) Consumers(ctx context.Context, consumers *[]types.ConsumerInfo) error {
+	r := QueueRequest{
+		Method:  MethodConsumerList,
+		Request: consumers,
+	}
+	return l.queueRequest(ctx, &r)
+}
+
 func (l *Logical) StorageQueueAdd(ctx context.Context, items *[]*types.Item) error {
 	// TODO: Test for empty list
 	r := QueueRequest{
@@ -395,19 +837,41 @@ func (l *Logical) synchronizationLoop() {
 		Completes: types.Batch[types.CompleteRequest]{
 			Requests: make([]*types.CompleteRequest, 0, 5_000),
 		},
+		Extends: types.Batch[types.ExtendRequest]{
+			Requests: make([]*types.ExtendRequest, 0, 5_000),
+		},
+		Releases: types.Batch[types.ReleaseRequest]{
+			Requests: make([]*types.ReleaseRequest, 0, 5_000),
+		},
 	}
 
 	for {
 		fmt.Printf("sync.loop\n")
 		select {
 		case req := <-l.produceQueueCh:
+			start := time.Now()
 			l.handleProduceRequests(&state, req)
+			l.metrics.syncLoop.Observe(time.Since(start).Seconds())
 
 		case req := <-l.reserveQueueCh:
+			start := time.Now()
 			l.handleReserveRequests(&state, req)
+			l.metrics.syncLoop.Observe(time.Since(start).Seconds())
 
 		case req := <-l.completeQueueCh:
+			start := time.Now()
 			l.handleCompleteRequests(&state, req)
+			l.metrics.syncLoop.Observe(time.Since(start).Seconds())
+
+		case req := <-l.extendQueueCh:
+			start := time.Now()
+			l.handleExtendRequests(&state, req)
+			l.metrics.syncLoop.Observe(time.Since(start).Seconds())
+
+		case req := <-l.releaseQueueCh:
+			start := time.Now()
+			l.handleReleaseRequests(&state, req)
+			l.metrics.syncLoop.Observe(time.Since(start).Seconds())
 
 		case req := <-l.queueRequestCh:
 			l.handleQueueRequests(&state, req)
@@ -455,6 +919,9 @@ EMPTY:
 	//  waiting reserve requests if our queue is caught up.
 	//  (Check for cancel or expire reserve requests first)
 
+	// FUTURE: Once produce requests are routed to more than one partition, items with an ordering key
+	//  must be produced to the partition returned by orderingPartition() to preserve per key FIFO.
+
 	// FUTURE: Buffer the produced items at the top of the queue into memory, so we don't need
 	//  to query them from the database when we reserve items later. Doing so avoids the ListReservable()
 	//  step, in addition, we can back fill reservable items into memory when synchronizationLoop() isn't
@@ -471,9 +938,28 @@ EMPTY:
 			close(req.ReadyCh)
 			continue
 		}
-		// Assign a DeadTimeout to each item
+		dedupWindow := l.conf.DedupWindow
+		if dedupWindow == clock.Duration(0) {
+			dedupWindow = DefaultDedupWindow
+		}
+		// Assign a DeadDeadline, ExpireDeadline and MaxAttempts to each item, unless the item provided its own
 		for _, item := range req.Items {
-			item.DeadDeadline = l.conf.Clock.Now().UTC().Add(l.conf.DeadTimeout)
+			if item.DeadTimeout != clock.Duration(0) {
+				item.DeadDeadline = l.conf.Clock.Now().UTC().Add(item.DeadTimeout)
+			} else {
+				item.DeadDeadline = l.conf.Clock.Now().UTC().Add(l.conf.DeadTimeout)
+			}
+			if item.ExpireTimeout != clock.Duration(0) {
+				item.ExpireDeadline = l.conf.Clock.Now().UTC().Add(item.ExpireTimeout)
+			} else if l.conf.ExpireTimeout != clock.Duration(0) {
+				item.ExpireDeadline = l.conf.Clock.Now().UTC().Add(l.conf.ExpireTimeout)
+			}
+			if item.MaxAttempts == 0 {
+				item.MaxAttempts = l.conf.MaxAttempts
+			}
+			if item.DedupID != "" {
+				item.DedupDeadline = l.conf.Clock.Now().UTC().Add(dedupWindow)
+			}
 		}
 		// The writeTimeout should be equal to the request with the least amount of request timeout left.
 		timeLeft := l.conf.Clock.Now().UTC().Sub(req.RequestDeadline)
@@ -484,16 +970,26 @@ EMPTY:
 
 	// If we allow a calculated write timeout to be a few milliseconds, then the store.Add()
 	// is almost guaranteed to fail, so we ensure the write timeout is something reasonable.
-	if writeTimeout < l.conf.WriteTimeout {
+	if writeTimeout < l.limits.Load().WriteTimeout {
 		// WriteTimeout comes from the storage implementation as the user who configured the
 		// storage option should know a reasonable timeout value for the configuration chosen.
-		writeTimeout = l.conf.WriteTimeout
+		writeTimeout = l.limits.Load().WriteTimeout
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	l.metrics.batchSize.WithLabelValues(OpProduce).Observe(float64(len(state.Producers.Requests)))
+	ctx, span := l.startBatchSpan("Logical.Produce", len(state.Producers.Requests),
+		func(link func(context.Context)) {
+			for _, req := range state.Producers.Requests {
+				link(req.Context)
+			}
+		})
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, writeTimeout)
 	if err := l.conf.Partitions[0].Produce(ctx, state.Producers); err != nil {
 		l.conf.Logger.Error("while calling Partition.Produce()", "error", err,
 			"category", "queue", "queueName", l.conf.Name)
+		span.SetStatus(codes.Error, err.Error())
 		cancel()
 		// Let clients that are timed out, know we are done with them.
 		for _, req := range state.Producers.Requests {
@@ -512,6 +1008,8 @@ EMPTY:
 
 	// Tell the waiting clients the items have been produced
 	for _, req := range state.Producers.Requests {
+		l.addUsage(req.Items)
+		l.metrics.items.WithLabelValues(OpProduce).Add(float64(len(req.Items)))
 		close(req.ReadyCh)
 	}
 	state.Producers.Reset()
@@ -545,20 +1043,35 @@ EMPTY:
 
 	// If we allow a calculated write timeout to be a few milliseconds, then the store.Add()
 	// is almost guaranteed to fail, so we ensure the write timeout is something reasonable.
-	if writeTimeout < l.conf.WriteTimeout {
+	if writeTimeout < l.limits.Load().WriteTimeout {
 		// WriteTimeout comes from the storage implementation as the user who configured the
 		// storage option should know a reasonable timeout value for the configuration chosen.
-		writeTimeout = l.conf.WriteTimeout
+		writeTimeout = l.limits.Load().WriteTimeout
 	}
 
 	// Send the batch that each request wants to the store. If there are items that can be reserved the
 	// store will assign items to each batch request.
-	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
-	if err := l.conf.Partitions[0].Reserve(ctx, state.Reservations, store.ReserveOptions{
-		ReserveDeadline: l.conf.Clock.Now().UTC().Add(l.conf.ReserveTimeout),
+	l.metrics.batchSize.WithLabelValues(OpReserve).Observe(float64(len(state.Reservations.Requests)))
+	ctx, span := l.startBatchSpan("Logical.Reserve", len(state.Reservations.Requests),
+		func(link func(context.Context)) {
+			for _, req := range state.Reservations.Requests {
+				if req != nil {
+					link(req.Context)
+				}
+			}
+		})
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, writeTimeout)
+	now := l.conf.Clock.Now().UTC()
+	if err := l.conf.Partitions[0].Reserve(ctx, &state.Reservations, store.ReserveOptions{
+		ReserveDeadline: now.Add(l.conf.ReserveTimeout),
+		ReservedAt:      now,
+		PriorityWeights: l.priorityWeights(),
 	}); err != nil {
 		l.conf.Logger.Error("while calling Partition.Reserve()", "error", err,
 			"category", "queue", "queueName", l.conf.Name)
+		span.SetStatus(codes.Error, err.Error())
 		cancel()
 		// We get here if there was an internal error with the data store
 		// TODO: If no new reserve requests come in, this may never try again. We need the maintenance
@@ -567,12 +1080,22 @@ EMPTY:
 	}
 	cancel()
 
+	// Expired items removed by the partition are no longer counted in the usage
+	if state.Reservations.Expired != 0 {
+		l.metrics.items.WithLabelValues(OpExpire).Add(float64(state.Reservations.Expired))
+		l.usageStale.Store(true)
+		state.Reservations.Expired = 0
+	}
+
 	// Inform clients they have reservations ready or if there was an error
 	for i, req := range state.Reservations.Requests {
 		if req == nil {
 			continue
 		}
-		if len(req.Items) != 0 || req.Err != nil {
+		// While draining, requests are not left waiting for items which will never be produced
+		if len(req.Items) != 0 || req.Err != nil || l.draining.Load() {
+			state.Consumers.reserve(req.ClientID, req.Items, now)
+			l.metrics.items.WithLabelValues(OpReserve).Add(float64(len(req.Items)))
 			state.Reservations.MarkNil(i)
 			close(req.ReadyCh)
 		}
@@ -614,35 +1137,205 @@ EMPTY:
 
 	// If we allow a calculated write timeout to be a few milliseconds, then the store.Add()
 	// is almost guaranteed to fail, so we ensure the write timeout is something reasonable.
-	if writeTimeout < l.conf.WriteTimeout {
+	if writeTimeout < l.limits.Load().WriteTimeout {
 		// WriteTimeout comes from the storage implementation as the user who configured the
 		// storage option should know a reasonable timeout value for the configuration chosen.
-		writeTimeout = l.conf.WriteTimeout
+		writeTimeout = l.limits.Load().WriteTimeout
 	}
 
+	l.metrics.batchSize.WithLabelValues(OpComplete).Observe(float64(len(state.Completes.Requests)))
+	ctx, span := l.startBatchSpan("Logical.Complete", len(state.Completes.Requests),
+		func(link func(context.Context)) {
+			for _, req := range state.Completes.Requests {
+				link(req.Context)
+			}
+		})
+	defer span.End()
+
 	var err error
-	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	ctx, cancel := context.WithTimeout(ctx, writeTimeout)
 	if err = l.conf.Partitions[0].Complete(ctx, state.Completes); err != nil {
 		l.conf.Logger.Error("while calling Partition.Complete()", "error", err,
 			"category", "queue", "queueName", l.conf.Name)
+		span.SetStatus(codes.Error, err.Error())
 	}
 	cancel()
+	l.usageStale.Store(true)
 
 	// Tell the waiting clients that items have been marked as complete
 	for _, req := range state.Completes.Requests {
 		if err != nil {
 			req.Err = ErrInternalRetry
 		}
+		if req.Err == nil {
+			l.metrics.items.WithLabelValues(OpComplete).Add(float64(req.Completed()))
+			l.consumersDone(state, req)
+		}
 		close(req.ReadyCh)
 	}
 	state.Completes.Reset()
 }
 
+func (l *Logical) handleExtendRequests(state *QueueState, req *types.ExtendRequest) {
+	// Consume all requests in the channel, so we can process them in a batch
+	state.Extends.Add(req)
+EMPTY:
+	for {
+		select {
+		case req := <-l.extendQueueCh:
+			state.Extends.Add(req)
+		default:
+			break EMPTY
+		}
+	}
+
+	writeTimeout := maxRequestTimeout
+	for _, req := range state.Extends.Requests {
+		// Cancel any extend requests that have timed out
+		if l.conf.Clock.Now().UTC().After(req.RequestDeadline) {
+			req.Err = ErrRequestTimeout
+			state.Extends.Remove(req)
+			close(req.ReadyCh)
+			continue
+		}
+		// The new deadline is calculated when the request is processed, not when it was received
+		req.ExtendedAt = l.conf.Clock.Now().UTC()
+		if req.ReserveTimeout == clock.Duration(0) {
+			req.ReserveDeadline = req.ExtendedAt.Add(l.conf.ReserveTimeout)
+		} else {
+			req.ReserveDeadline = req.ExtendedAt.Add(req.ReserveTimeout)
+		}
+
+		// The writeTimeout should be equal to the request with the least amount of request timeout left.
+		timeLeft := req.RequestDeadline.Sub(l.conf.Clock.Now().UTC())
+		if timeLeft < writeTimeout {
+			writeTimeout = timeLeft
+		}
+	}
+
+	// If we allow a calculated write timeout to be a few milliseconds, then the store.Extend()
+	// is almost guaranteed to fail, so we ensure the write timeout is something reasonable.
+	if writeTimeout < l.limits.Load().WriteTimeout {
+		writeTimeout = l.limits.Load().WriteTimeout
+	}
+
+	l.metrics.batchSize.WithLabelValues(OpExtend).Observe(float64(len(state.Extends.Requests)))
+	ctx, span := l.startBatchSpan("Logical.Extend", len(state.Extends.Requests),
+		func(link func(context.Context)) {
+			for _, req := range state.Extends.Requests {
+				link(req.Context)
+			}
+		})
+	defer span.End()
+
+	var err error
+	ctx, cancel := context.WithTimeout(ctx, writeTimeout)
+	if err = l.conf.Partitions[0].Extend(ctx, state.Extends); err != nil {
+		l.conf.Logger.Error("while calling Partition.Extend()", "error", err,
+			"category", "queue", "queueName", l.conf.Name)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	cancel()
+
+	// Tell the waiting clients that the reservations have been extended
+	for _, req := range state.Extends.Requests {
+		if err != nil {
+			req.Err = ErrInternalRetry
+		}
+		if req.Err == nil {
+			l.metrics.items.WithLabelValues(OpExtend).Add(float64(len(req.Ids)))
+			state.Consumers.extend(req.Ids, req.ReserveDeadlines)
+		}
+		close(req.ReadyCh)
+	}
+	state.Extends.Reset()
+}
+
+func (l *Logical) handleReleaseRequests(state *QueueState, req *types.ReleaseRequest) {
+	// Consume all requests in the channel, so we can process them in a batch
+	state.Releases.Add(req)
+EMPTY:
+	for {
+		select {
+		case req := <-l.releaseQueueCh:
+			state.Releases.Add(req)
+		default:
+			break EMPTY
+		}
+	}
+
+	writeTimeout := maxRequestTimeout
+	for _, req := range state.Releases.Requests {
+		// Cancel any release requests that have timed out
+		if l.conf.Clock.Now().UTC().After(req.RequestDeadline) {
+			req.Err = ErrRequestTimeout
+			state.Releases.Remove(req)
+			close(req.ReadyCh)
+			continue
+		}
+		// The writeTimeout should be equal to the request with the least amount of request timeout left.
+		timeLeft := req.RequestDeadline.Sub(l.conf.Clock.Now().UTC())
+		if timeLeft < writeTimeout {
+			writeTimeout = timeLeft
+		}
+	}
+
+	// If we allow a calculated write timeout to be a few milliseconds, then the store.Release()
+	// is almost guaranteed to fail, so we ensure the write timeout is something reasonable.
+	if writeTimeout < l.limits.Load().WriteTimeout {
+		writeTimeout = l.limits.Load().WriteTimeout
+	}
+
+	l.metrics.batchSize.WithLabelValues(OpRelease).Observe(float64(len(state.Releases.Requests)))
+	ctx, span := l.startBatchSpan("Logical.Release", len(state.Releases.Requests),
+		func(link func(context.Context)) {
+			for _, req := range state.Releases.Requests {
+				link(req.Context)
+			}
+		})
+	defer span.End()
+
+	var err error
+	ctx, cancel := context.WithTimeout(ctx, writeTimeout)
+	if err = l.conf.Partitions[0].Release(ctx, state.Releases); err != nil {
+		l.conf.Logger.Error("while calling Partition.Release()", "error", err,
+			"category", "queue", "queueName", l.conf.Name)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	cancel()
+
+	// Tell the waiting clients that the items have been released
+	var released bool
+	for _, req := range state.Releases.Requests {
+		if err != nil {
+			req.Err = ErrInternalRetry
+		}
+		if req.Err == nil {
+			l.metrics.items.WithLabelValues(OpRelease).Add(float64(len(req.Ids)))
+			state.Consumers.done(req.Ids...)
+			released = true
+		}
+		close(req.ReadyCh)
+	}
+	state.Releases.Reset()
+
+	// If there are reservations waiting, then process reservations allowing them to pick up the
+	// items just released.
+	if released && state.Reservations.Total != 0 {
+		l.handleReserveRequests(state, nil)
+	}
+}
+
 // stateCleanUp is responsible for cleaning the QueueState by removing clients that have timed out,
 // and finding the next reserve request that will time out and wetting the wakeup timer.
 func (l *Logical) stateCleanUp(state *QueueState) {
 	fmt.Printf("stateCleanUp\n")
+	l.expireConsumers(state)
 	next := l.nextTimeout(&state.Reservations)
+	// Wake up in time to release the reservations of consumers which stop sending heartbeats
+	if hb := state.Consumers.next(l.conf.Clock.Now().UTC()); hb > 0 && (next == 0 || hb < next) {
+		next = hb
+	}
 	if next.Nanoseconds() != 0 {
 		l.conf.Logger.Debug("next maintenance window",
 			"duration", next.String(), "queue", l.conf.Name)
@@ -700,7 +1393,8 @@ func (l *Logical) queueRequest(ctx context.Context, r *QueueRequest) error {
 
 func (l *Logical) handleQueueRequests(state *QueueState, req *QueueRequest) {
 	switch req.Method {
-	case MethodStorageQueueList, MethodStorageQueueAdd, MethodStorageQueueDelete:
+	case MethodStorageQueueList, MethodStorageQueueAdd, MethodStorageQueueDelete,
+		MethodQueuePeek, MethodQueueGet:
 		l.handleStorageRequests(req)
 	case MethodQueueStats:
 		l.handleStats(state, req)
@@ -711,16 +1405,114 @@ func (l *Logical) handleQueueRequests(state *QueueState, req *QueueRequest) {
 	case MethodUpdateInfo:
 		info := req.Request.(types.QueueInfo)
 		l.conf.QueueInfo = info
+		l.info.Store(&info)
 		close(req.ReadyCh)
 	case MethodUpdatePartitions:
 		p := req.Request.([]store.Partition)
 		l.conf.Partitions = p
+		l.usageStale.Store(true)
 		close(req.ReadyCh)
+	case MethodRefreshUsage:
+		l.handleRefreshUsage(req)
+	case MethodDrain:
+		l.handleDrain(state, req)
+	case MethodConsumerRegister, MethodConsumerHeartbeat, MethodConsumerDeregister, MethodConsumerList:
+		l.handleConsumers(state, req)
 	default:
 		panic(fmt.Sprintf("unknown queue request method '%d'", req.Method))
 	}
 }
 
+func (l *Logical) handleConsumers(state *QueueState, req *QueueRequest) {
+	now := l.conf.Clock.Now().UTC()
+	// Expire consumers first, such that a heartbeat from a consumer which has already
+	// expired fails and a listing never includes expired consumers.
+	l.expireConsumers(state)
+
+	switch req.Method {
+	case MethodConsumerRegister:
+		cr := req.Request.(*types.ConsumerRequest)
+		info := state.Consumers.register(cr.ClientID, cr.HeartbeatTimeout, now)
+		cr.HeartbeatDeadline = info.HeartbeatDeadline
+	case MethodConsumerHeartbeat:
+		cr := req.Request.(*types.ConsumerRequest)
+		info, ok := state.Consumers.heartbeat(cr.ClientID, now)
+		if !ok {
+			req.Err = transport.NewInvalidOption(MsgNotRegistered)
+			break
+		}
+		cr.HeartbeatDeadline = info.HeartbeatDeadline
+	case MethodConsumerDeregister:
+		cr := req.Request.(*types.ConsumerRequest)
+		l.releaseConsumer(state, cr.ClientID, state.Consumers.deregister(cr.ClientID, now))
+	case MethodConsumerList:
+		*req.Request.(*[]types.ConsumerInfo) = state.Consumers.list(now)
+	}
+	close(req.ReadyCh)
+}
+
+// expireConsumers releases the items reserved by consumers which have not sent a heartbeat
+// before their heartbeat deadline.
+func (l *Logical) expireConsumers(state *QueueState) {
+	for clientID, ids := range state.Consumers.expire(l.conf.Clock.Now().UTC()) {
+		l.conf.Logger.Warn("consumer heartbeat timeout; releasing reserved items",
+			"clientID", clientID, "count", len(ids), "category", "queue", "queueName", l.conf.Name)
+		l.releaseConsumer(state, clientID, ids)
+	}
+}
+
+// releaseConsumer releases the items reserved by a consumer which has gone away, the release is not
+// counted as an attempt since the items were never processed. Each id is released independently
+// such that an item whose reservation was completed or has expired does not prevent the others
+// from being released.
+func (l *Logical) releaseConsumer(state *QueueState, clientID string, ids [][]byte) {
+	if len(ids) == 0 {
+		return
+	}
+
+	batch := types.Batch[types.ReleaseRequest]{
+		Requests: make([]*types.ReleaseRequest, 0, len(ids)),
+	}
+	for _, id := range ids {
+		batch.Add(&types.ReleaseRequest{Ids: [][]byte{id}, SkipAttempt: true})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.limits.Load().WriteTimeout)
+	defer cancel()
+	if err := l.conf.Partitions[0].Release(ctx, batch); err != nil {
+		l.conf.Logger.Error("while calling Partition.Release()", "error", err, "clientID", clientID,
+			"category", "queue", "queueName", l.conf.Name)
+		return
+	}
+
+	var released int
+	for _, req := range batch.Requests {
+		if req.Err == nil {
+			released++
+		}
+	}
+	l.metrics.items.WithLabelValues(OpRelease).Add(float64(released))
+
+	// If there are reservations waiting, then process reservations allowing them to pick up the
+	// items just released.
+	if released != 0 && state.Reservations.Total != 0 {
+		l.handleReserveRequests(state, nil)
+	}
+}
+
+// consumersDone stops tracking the items completed by the request
+func (l *Logical) consumersDone(state *QueueState, req *types.CompleteRequest) {
+	if !req.Detailed {
+		state.Consumers.done(req.Ids...)
+		return
+	}
+	for i, result := range req.Results {
+		if result.Status == pb.CompleteStatus_COMPLETE_OK {
+			state.Consumers.done(req.Ids[i])
+		}
+	}
+}
+
 func (l *Logical) handleClear(_ *QueueState, req *QueueRequest) {
 	// NOTE: When clearing a queue, ensure we flush any cached items. As of this current
 	// version (V0), there is no cached data to sync, but this will likely change in the future.
@@ -731,6 +1523,7 @@ func (l *Logical) handleClear(_ *QueueState, req *QueueRequest) {
 		if err := l.conf.Partitions[0].Clear(req.Context, cr.Destructive); err != nil {
 			req.Err = err
 		}
+		l.usageStale.Store(true)
 	}
 	// TODO(thrawn01): Support clearing defer and scheduled
 	close(req.ReadyCh)
@@ -747,17 +1540,43 @@ func (l *Logical) handleStorageRequests(req *QueueRequest) {
 	case MethodStorageQueueAdd:
 		if err := l.conf.Partitions[0].Add(req.Context, *sr.Items); err != nil {
 			req.Err = err
+			break
 		}
+		l.addUsage(*sr.Items)
 	case MethodStorageQueueDelete:
 		if err := l.conf.Partitions[0].Delete(req.Context, sr.IDs); err != nil {
 			req.Err = err
 		}
+		l.usageStale.Store(true)
+	case MethodQueuePeek:
+		if err := l.conf.Partitions[0].Peek(req.Context, sr.Items, store.PeekOptions{
+			Limit:           sr.Options.Limit,
+			PriorityWeights: l.priorityWeights(),
+		}); err != nil {
+			req.Err = err
+		}
+	case MethodQueueGet:
+		if err := l.conf.Partitions[0].Get(req.Context, sr.IDs, sr.Items); err != nil {
+			req.Err = err
+		}
 	default:
 		panic(fmt.Sprintf("unknown storage request method '%d'", req.Method))
 	}
 	close(req.ReadyCh)
 }
 
+// priorityWeights returns the priority weights of the queue, or nil if higher priority levels should
+// be drained before lower priority levels are reserved, which is the case if the weights are empty or
+// all zero.
+func (l *Logical) priorityWeights() []int {
+	for _, w := range l.conf.PriorityWeights {
+		if w != 0 {
+			return l.conf.PriorityWeights
+		}
+	}
+	return nil
+}
+
 func (l *Logical) handleStats(state *QueueState, r *QueueRequest) {
 	qs := r.Request.(*types.QueueStats)
 	// TODO: return all Partition stats
@@ -772,6 +1591,21 @@ func (l *Logical) handleStats(state *QueueState, r *QueueRequest) {
 	close(r.ReadyCh)
 }
 
+func (l *Logical) handleRefreshUsage(r *QueueRequest) {
+	var qs types.QueueStats
+	// TODO: Include usage from all partitions
+	if err := l.conf.Partitions[0].Stats(r.Context, &qs); err != nil {
+		r.Err = err
+		close(r.ReadyCh)
+		return
+	}
+	l.usageItems.Store(int64(qs.Total))
+	l.usageBytes.Store(qs.TotalBytes)
+	l.usageStale.Store(false)
+	l.usageLoaded.Store(true)
+	close(r.ReadyCh)
+}
+
 // handlePause places Logical into a special loop where operations none of the // produce,
 // reserve, complete, defer operations will be processed until we leave the loop.
 func (l *Logical) handlePause(state *QueueState, r *QueueRequest) {
@@ -804,6 +1638,21 @@ func (l *Logical) handlePause(state *QueueState, r *QueueRequest) {
 	}
 }
 
+func (l *Logical) handleDrain(state *QueueState, r *QueueRequest) {
+	l.draining.Store(true)
+
+	// Reservations waiting for items are returned empty, as no new items will be produced
+	for i, req := range state.Reservations.Requests {
+		if req == nil {
+			continue
+		}
+		state.Reservations.MarkNil(i)
+		close(req.ReadyCh)
+	}
+	state.Reservations.FilterNils()
+	close(r.ReadyCh)
+}
+
 func (l *Logical) handleShutdown(state *QueueState, req *types.ShutdownRequest) {
 	fmt.Printf("handleShutdown\n")
 	l.stateCleanUp(state)
@@ -830,6 +1679,12 @@ func (l *Logical) handleShutdown(state *QueueState, req *types.ShutdownRequest)
 			fmt.Printf("handleShutdown.Reserve\n")
 			r.Err = ErrQueueShutdown
 			close(r.ReadyCh)
+		case r := <-l.extendQueueCh:
+			r.Err = ErrQueueShutdown
+			close(r.ReadyCh)
+		case r := <-l.releaseQueueCh:
+			r.Err = ErrQueueShutdown
+			close(r.ReadyCh)
 		case <-l.conf.Clock.After(100 * clock.Millisecond):
 			// all time for the closed requests handlers to exit
 		case <-req.Context.Done():
@@ -871,7 +1726,6 @@ func (l *Logical) nextTimeout(r *types.ReserveBatch) clock.Duration {
 
 		// If client has gone away
 		if req.Context.Err() != nil {
-			r.Total -= req.NumRequested
 			req.Err = req.Context.Err()
 			close(req.ReadyCh)
 			r.MarkNil(i)
@@ -899,6 +1753,47 @@ func (l *Logical) nextTimeout(r *types.ReserveBatch) clock.Duration {
 	return soon.RequestDeadline.Sub(l.conf.Clock.Now().UTC())
 }
 
+// startBatchSpan starts a new root span for a batch of requests processed by the sync loop. Since a single
+// batch serves many requests, the batch span is linked to the span of each request it serves.
+func (l *Logical) startBatchSpan(name string, size int,
+	each func(link func(context.Context))) (context.Context, trace.Span) {
+
+	var links []trace.Link
+	each(func(ctx context.Context) {
+		if link := trace.LinkFromContext(ctx); link.SpanContext.IsValid() {
+			links = append(links, link)
+		}
+	})
+
+	return l.conf.Tracer.Start(context.Background(), name,
+		trace.WithNewRoot(),
+		trace.WithLinks(links...),
+		trace.WithAttributes(
+			attribute.String("querator.namespace", l.conf.Namespace),
+			attribute.String("querator.queue", l.conf.Name),
+			attribute.Int("querator.batch.size", size),
+		))
+}
+
+// addUsage adds the items provided to the usage counts returned by Usage()
+func (l *Logical) addUsage(items []*types.Item) {
+	var size int64
+	for _, item := range items {
+		size += int64(len(item.Payload))
+	}
+	l.usageItems.Add(int64(len(items)))
+	l.usageBytes.Add(size)
+}
+
+// orderingPartition returns the index of the partition which items with the ordering key provided are
+// produced to. The mapping is stable, such that every item with the same key is produced to the same
+// partition as long as the number of partitions does not change.
+func orderingPartition(key string, partitions int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(partitions))
+}
+
 // addIfUnique adds a ReserveRequest to the batch. Returns false if the ReserveRequest.ClientID is a duplicate
 // and the request was not added to the batch
 func addIfUnique(r *types.ReserveBatch, req *types.ReserveRequest) {
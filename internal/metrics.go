@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"strconv"
+)
+
+const (
+	OpProduce  = "produce"
+	OpReserve  = "reserve"
+	OpComplete = "complete"
+	OpExtend   = "extend"
+	OpRelease  = "release"
+	OpExpire   = "expire"
+)
+
+// QueueMetrics are the prometheus metrics collected for each queue and partition. All metrics
+// are labeled with the namespace and name of the queue, such that the metrics for a queue
+// can be removed when the queue is deleted. The depth, reserved and oldest age gauges are
+// additionally labeled with the partition they were read from.
+type QueueMetrics struct {
+	// items is the number of items operated on by the queue, labeled by operation
+	items *prometheus.CounterVec
+	// batchSize is the number of client requests handled in a single batch by the sync loop
+	batchSize *prometheus.HistogramVec
+	// syncLoop is the time it takes for a single iteration of the sync loop
+	syncLoop *prometheus.HistogramVec
+	// storage is the latency of each call to a Partition method
+	storage *prometheus.HistogramVec
+
+	depth     *prometheus.Desc
+	reserved  *prometheus.Desc
+	oldestAge *prometheus.Desc
+}
+
+func NewQueueMetrics() *QueueMetrics {
+	return &QueueMetrics{
+		items: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "queue_items_total",
+			Help: "The number of items produced, reserved, completed, extended, released or expired by the queue",
+		}, []string{"namespace", "queue", "op"}),
+		batchSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "queue_batch_size",
+			Help:    "The number of client requests handled in a single batch",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+		}, []string{"namespace", "queue", "op"}),
+		syncLoop: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "queue_sync_loop_duration_seconds",
+			Help:    "The time it takes to complete a single iteration of the queue sync loop",
+			Buckets: prometheus.ExponentialBuckets(0.0001, 4, 10),
+		}, []string{"namespace", "queue"}),
+		storage: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "partition_storage_duration_seconds",
+			Help:    "The latency of calls to the partition storage backend",
+			Buckets: prometheus.ExponentialBuckets(0.0001, 4, 10),
+		}, []string{"namespace", "queue", "partition", "storage", "method"}),
+		depth: prometheus.NewDesc("queue_depth",
+			"The number of items in the partition", []string{"namespace", "queue", "partition"}, nil),
+		reserved: prometheus.NewDesc("queue_reserved",
+			"The number of items in the partition which are reserved", []string{"namespace", "queue", "partition"}, nil),
+		oldestAge: prometheus.NewDesc("queue_oldest_item_age_seconds",
+			"The age of the oldest item in the partition", []string{"namespace", "queue", "partition"}, nil),
+	}
+}
+
+// Storage returns the storage latency observer for the partition provided. The returned
+// observer is labeled by the Partition method called.
+func (m *QueueMetrics) Storage(namespace, queue string, partition int, storage string) prometheus.ObserverVec {
+	return m.storage.MustCurryWith(prometheus.Labels{
+		"namespace": namespace,
+		"queue":     queue,
+		"partition": strconv.Itoa(partition),
+		"storage":   storage,
+	})
+}
+
+// Remove removes all the metrics for the queue provided
+func (m *QueueMetrics) Remove(namespace, queue string) {
+	labels := prometheus.Labels{"namespace": namespace, "queue": queue}
+	m.items.DeletePartialMatch(labels)
+	m.batchSize.DeletePartialMatch(labels)
+	m.syncLoop.DeletePartialMatch(labels)
+	m.storage.DeletePartialMatch(labels)
+}
+
+// Describe fetches prometheus metrics to be registered
+func (m *QueueMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.items.Describe(ch)
+	m.batchSize.Describe(ch)
+	m.syncLoop.Describe(ch)
+	m.storage.Describe(ch)
+	ch <- m.depth
+	ch <- m.reserved
+	ch <- m.oldestAge
+}
+
+// Collect fetches metrics from the server for use by prometheus
+func (m *QueueMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.items.Collect(ch)
+	m.batchSize.Collect(ch)
+	m.syncLoop.Collect(ch)
+	m.storage.Collect(ch)
+}
+
+// queueMetrics are the metrics for a single queue, curried with the labels of the queue
+type queueMetrics struct {
+	items     *prometheus.CounterVec
+	batchSize prometheus.ObserverVec
+	syncLoop  prometheus.Observer
+}
+
+func (m *QueueMetrics) forQueue(namespace, queue string) queueMetrics {
+	labels := prometheus.Labels{"namespace": namespace, "queue": queue}
+	return queueMetrics{
+		items:     m.items.MustCurryWith(labels),
+		batchSize: m.batchSize.MustCurryWith(labels),
+		syncLoop:  m.syncLoop.With(labels),
+	}
+}
@@ -10,12 +10,29 @@ import (
 	"github.com/kapetan-io/querator/transport"
 	"github.com/kapetan-io/tackle/clock"
 	"github.com/kapetan-io/tackle/set"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace/noop"
 	"log/slog"
+	"strconv"
 	"sync"
 	"sync/atomic"
 )
 
-const MsgServiceInShutdown = "service is shutting down"
+const (
+	MsgServiceInShutdown  = "service is shutting down"
+	MsgNamespaceQuotaFull = "namespace quota exceeded"
+	MsgHealthCheckFailed  = "health check failed"
+	MsgReloadFailed       = "reload failed"
+)
+
+// Names of the components reported by HealthCheck(), backends are reported as `backend/<name>`
+const (
+	HealthCheckService    = "service"
+	HealthCheckQueueStore = "queue-store"
+)
+
+// collectMaxAge is how old the stats of a queue may be before Collect() fetches new stats
+const collectMaxAge = 10 * clock.Second
 
 var ErrServiceShutdown = transport.NewRequestFailed(MsgServiceInShutdown)
 
@@ -23,18 +40,40 @@ type QueuesManagerConfig struct {
 	StorageConfig store.StorageConfig
 	Logger        duh.StandardLogger
 	LogicalConfig LogicalConfig
+	// NamespaceQuotas is a map of namespace names to the quota enforced for that namespace
+	NamespaceQuotas map[string]types.NamespaceQuota
+	// DefaultNamespaceQuota is the quota enforced for any namespace not found in NamespaceQuotas
+	DefaultNamespaceQuota types.NamespaceQuota
+}
+
+// ReloadConfig is the subset of QueuesManagerConfig which can be changed while the service is running
+type ReloadConfig struct {
+	// Limits are the limits and timeouts enforced by each logical queue
+	Limits Limits
+	// NamespaceQuotas is a map of namespace names to the quota enforced for that namespace
+	NamespaceQuotas map[string]types.NamespaceQuota
+	// DefaultNamespaceQuota is the quota enforced for any namespace not found in NamespaceQuotas
+	DefaultNamespaceQuota types.NamespaceQuota
+	// Backends are the partition backends, see QueuesManager.Reload() for details
+	Backends []store.Backend
 }
 
 // QueuesManager manages queues in use, and information about that queue.
 type QueuesManager struct {
-	queues     map[string]*Logical
+	queues map[string]*Logical
+	// idle is the usage of queues which are not running, keyed by namespace and then by queue key. A
+	// namespace is only present once the usage of all its queues which are not running has been read.
+	idle       map[string]map[string]idleUsage
 	conf       QueuesManagerConfig
 	inShutdown atomic.Bool
+	draining   atomic.Bool
 	mutex      sync.Mutex
 }
 
 func NewQueuesManager(conf QueuesManagerConfig) (*QueuesManager, error) {
 	set.Default(&conf.LogicalConfig.Clock, clock.NewProvider())
+	set.Default(&conf.LogicalConfig.Tracer, noop.NewTracerProvider().Tracer(""))
+	set.Default(&conf.LogicalConfig.Metrics, NewQueueMetrics())
 	set.Default(&conf.Logger, slog.Default())
 
 	if conf.StorageConfig.QueueStore == nil {
@@ -47,15 +86,185 @@ func NewQueuesManager(conf QueuesManagerConfig) (*QueuesManager, error) {
 
 	qm := &QueuesManager{
 		queues: make(map[string]*Logical),
+		idle:   make(map[string]map[string]idleUsage),
 		conf:   conf,
 	}
 
 	return qm, nil
 }
 
-// TODO: Implement a healthcheck method call, which will ensure access to StorageConfig is working
+// HealthCheck verifies the QueueStore and each of the partition backends are reachable, returning
+// the result of each check. Checks which do not complete before the context is cancelled are reported
+// as failed. If the service is shutting down, only the failed service check is returned.
+func (qm *QueuesManager) HealthCheck(ctx context.Context) []types.HealthCheck {
+	if qm.inShutdown.Load() {
+		return []types.HealthCheck{{Name: HealthCheckService, Err: ErrServiceShutdown}}
+	}
+
+	type result struct {
+		err error
+		idx int
+	}
+	checks := []types.HealthCheck{{Name: HealthCheckService}, {Name: HealthCheckQueueStore}}
+	if qm.draining.Load() {
+		checks[0].Err = ErrServiceDraining
+	}
+	qm.mutex.Lock()
+	backends := qm.conf.StorageConfig.Backends
+	qm.mutex.Unlock()
+
+	pings := []func(context.Context) error{
+		func(ctx context.Context) error {
+			// Access to the QueueStore is serialized by the mutex
+			defer qm.mutex.Unlock()
+			qm.mutex.Lock()
+			if qm.inShutdown.Load() {
+				return ErrServiceShutdown
+			}
+			return qm.conf.StorageConfig.QueueStore.Ping(ctx)
+		},
+	}
+	for _, b := range backends {
+		checks = append(checks, types.HealthCheck{Name: "backend/" + b.Name})
+		pings = append(pings, b.PartitionStore.Ping)
+	}
+
+	results := make(chan result, len(pings))
+	for i, ping := range pings {
+		go func(idx int, ping func(context.Context) error) {
+			results <- result{idx: idx, err: ping(ctx)}
+		}(i+1, ping)
+	}
 
-func (qm *QueuesManager) Get(ctx context.Context, name string) (*Logical, error) {
+	pending := make(map[int]struct{}, len(pings))
+	for i := range pings {
+		pending[i+1] = struct{}{}
+	}
+	for len(pending) != 0 {
+		select {
+		case r := <-results:
+			checks[r.idx].Err = r.err
+			delete(pending, r.idx)
+		case <-ctx.Done():
+			for idx := range pending {
+				checks[idx].Err = fmt.Errorf("check did not complete: %w", ctx.Err())
+			}
+			return checks
+		}
+	}
+	return checks
+}
+
+// Reload applies the limits, namespace quotas and backends provided to the running service. New
+// limits are propagated to all running logical queues. Backends are matched by name, existing
+// backends are updated with the new affinity while new backends are verified reachable and then
+// registered. Removing a backend which may hold partitions, or changing the settings of an existing
+// backend is not supported and returns an error. Nothing is applied unless the entire config is valid.
+func (qm *QueuesManager) Reload(ctx context.Context, conf ReloadConfig) error {
+	if qm.inShutdown.Load() {
+		return ErrServiceShutdown
+	}
+	defer qm.mutex.Unlock()
+	qm.mutex.Lock()
+
+	if err := conf.Limits.validate(); err != nil {
+		return err
+	}
+	conf.Limits.setDefaults()
+
+	current := make(map[string]int, len(qm.conf.StorageConfig.Backends))
+	for i, b := range qm.conf.StorageConfig.Backends {
+		current[b.Name] = i
+	}
+
+	backends := make([]store.Backend, len(qm.conf.StorageConfig.Backends))
+	copy(backends, qm.conf.StorageConfig.Backends)
+	seen := make(map[string]struct{}, len(conf.Backends))
+	for _, b := range conf.Backends {
+		seen[b.Name] = struct{}{}
+		if i, ok := current[b.Name]; ok {
+			if b.Settings != backends[i].Settings {
+				return transport.NewInvalidOption("backend '%s' is invalid; settings cannot be changed while "+
+					"the service is running, '%s' was changed to '%s'", b.Name, backends[i].Settings, b.Settings)
+			}
+			// Keep the existing PartitionStore, as partitions in use were created by it
+			backends[i].Affinity = b.Affinity
+			continue
+		}
+		if b.PartitionStore == nil && b.NewPartitionStore != nil {
+			b.PartitionStore = b.NewPartitionStore()
+		}
+		if b.PartitionStore == nil {
+			return transport.NewInvalidOption("backend '%s' is invalid; partition store cannot be nil", b.Name)
+		}
+		if err := b.PartitionStore.Ping(ctx); err != nil {
+			return transport.NewInvalidOption("backend '%s' is invalid; %s", b.Name, err)
+		}
+		backends = append(backends, b)
+	}
+	for name := range current {
+		if _, ok := seen[name]; !ok {
+			return transport.NewInvalidOption("backend '%s' cannot be removed while the service is running", name)
+		}
+	}
+
+	for _, q := range qm.queues {
+		q.UpdateLimits(conf.Limits)
+	}
+	qm.conf.LogicalConfig.Limits = conf.Limits
+	qm.conf.NamespaceQuotas = conf.NamespaceQuotas
+	qm.conf.DefaultNamespaceQuota = conf.DefaultNamespaceQuota
+	qm.conf.StorageConfig.Backends = backends
+	return nil
+}
+
+// Drain places the service into drain mode in preparation for shutdown. All running queues, and any
+// queues started while draining, reject new produce and reserve requests with ErrServiceDraining
+// while in flight requests are allowed to complete. See Logical.Drain() for details. Use DrainStatus()
+// to monitor the progress of the drain. Drain mode cannot be cancelled.
+func (qm *QueuesManager) Drain(ctx context.Context) error {
+	if qm.inShutdown.Load() {
+		return ErrServiceShutdown
+	}
+	f := errors.Fields{"category", "querator", "func", "QueuesManager.Drain"}
+	defer qm.mutex.Unlock()
+	qm.mutex.Lock()
+
+	qm.draining.Store(true)
+	for _, q := range qm.queues {
+		if err := q.Drain(ctx); err != nil {
+			return f.Errorf("Logical.Drain(): %w", err)
+		}
+	}
+	return nil
+}
+
+// DrainStatus reports the progress of draining the running queues
+func (qm *QueuesManager) DrainStatus(ctx context.Context, status *types.DrainStatus) error {
+	if qm.inShutdown.Load() {
+		return ErrServiceShutdown
+	}
+	f := errors.Fields{"category", "querator", "func", "QueuesManager.DrainStatus"}
+	qm.mutex.Lock()
+	queues := make([]*Logical, 0, len(qm.queues))
+	for _, q := range qm.queues {
+		queues = append(queues, q)
+	}
+	qm.mutex.Unlock()
+
+	*status = types.DrainStatus{Draining: qm.draining.Load(), Queues: len(queues)}
+	for _, q := range queues {
+		var stats types.QueueStats
+		if err := q.QueueStats(ctx, &stats); err != nil {
+			return f.Errorf("Logical.QueueStats(): %w", err)
+		}
+		status.InFlight += q.InFlight()
+		status.Reserved += stats.TotalReserved
+	}
+	return nil
+}
+
+func (qm *QueuesManager) Get(ctx context.Context, namespace, name string) (*Logical, error) {
 	if qm.inShutdown.Load() {
 		return nil, ErrServiceShutdown
 	}
@@ -63,14 +272,14 @@ func (qm *QueuesManager) Get(ctx context.Context, name string) (*Logical, error)
 	qm.mutex.Lock()
 
 	// If queue is already running
-	q, ok := qm.queues[name]
+	q, ok := qm.queues[types.QueueKey(namespace, name)]
 	if ok {
 		return q, nil
 	}
 
 	// Look for the queue in storage
 	var queue types.QueueInfo
-	if err := qm.conf.StorageConfig.QueueStore.Get(ctx, name, &queue); err != nil {
+	if err := qm.conf.StorageConfig.QueueStore.Get(ctx, namespace, name, &queue); err != nil {
 		if errors.Is(err, store.ErrQueueNotExist) {
 			return nil, transport.NewInvalidOption("queue does not exist; no such queue named '%s'", name)
 		}
@@ -88,6 +297,19 @@ func (qm *QueuesManager) Create(ctx context.Context, info types.QueueInfo) (*Log
 	defer qm.mutex.Unlock()
 	qm.mutex.Lock()
 
+	quota := qm.quota(info.Namespace)
+	if quota.MaxQueues != 0 {
+		queues := make([]types.QueueInfo, 0, quota.MaxQueues)
+		if err := qm.conf.StorageConfig.QueueStore.List(ctx, info.Namespace, &queues,
+			types.ListOptions{Limit: quota.MaxQueues}); err != nil {
+			return nil, f.Errorf("QueueStore.List(): %w", err)
+		}
+		if len(queues) >= quota.MaxQueues {
+			return nil, transport.NewRetryRequest("%s; namespace '%s' is limited to %d queues",
+				MsgNamespaceQuotaFull, info.Namespace, quota.MaxQueues)
+		}
+	}
+
 	// When creating a new Queue, info.PartitionInfo should have no details, but should
 	// include the number of partitions requested. The manager will decide where to
 	// place the partitions depending on the storage backend configurations. As such
@@ -96,6 +318,7 @@ func (qm *QueuesManager) Create(ctx context.Context, info types.QueueInfo) (*Log
 	for i := 0; i < info.Partitions; i++ {
 		// TODO: Spread the partitions across the storage backends according to affinity
 		p := types.PartitionInfo{
+			Namespace:   info.Namespace,
 			StorageName: qm.conf.StorageConfig.Backends[0].Name,
 			QueueName:   info.Name,
 			Partition:   i,
@@ -119,7 +342,7 @@ func (qm *QueuesManager) Create(ctx context.Context, info types.QueueInfo) (*Log
 	}
 
 	// Assertion that we are not crazy
-	if _, ok := qm.queues[info.Name]; ok {
+	if _, ok := qm.queues[info.Key()]; ok {
 		// TODO(thrawn01): Consider a preforming a queue.UpdateInfo() if this happens instead of a panic.
 		//  It's possible the data store where we keep queue info is out of sync with our actual state, in
 		//  this case, it's probably better for us to update the queues when this happens.
@@ -152,40 +375,206 @@ func (qm *QueuesManager) startLogicalQueue(ctx context.Context, info types.Queue
 	// TODO: If there is only one client, and multiple Logical Queues, then we
 	//  should reduce the number of Logical Queues automatically. Using a congestion detection algorithm
 	//  similar to https://www.usenix.org/conference/nsdi19/presentation/ousterhout
-	// TODO: We need to figure out how clients register themselves as consumers before allowing
-	//  reservation calls.
+	// NOTE: Clients may register themselves as consumers via /queue.register, consumers are tracked by
+	//  the Logical queue they register with. See doc/adr/0019-consumer-registration.md
 
 	// Get all the partitions we want associated with this logical queue instance
 	p := qm.conf.StorageConfig.Backends[0].PartitionStore.Get(info.PartitionInfo[0])
+	pi := info.PartitionInfo[0]
+	p = store.NewInstrumentedPartition(p, pi, qm.conf.LogicalConfig.Tracer,
+		qm.conf.LogicalConfig.Metrics.Storage(info.Namespace, info.Name, pi.Partition, pi.StorageName))
 
 	l, err := SpawnLogicalQueue(LogicalConfig{
-		MaxProduceBatchSize:  qm.conf.LogicalConfig.MaxProduceBatchSize,
-		MaxReserveBatchSize:  qm.conf.LogicalConfig.MaxReserveBatchSize,
-		MaxCompleteBatchSize: qm.conf.LogicalConfig.MaxCompleteBatchSize,
-		MaxRequestsPerQueue:  qm.conf.LogicalConfig.MaxRequestsPerQueue,
-		WriteTimeout:         qm.conf.LogicalConfig.WriteTimeout,
-		ReadTimeout:          qm.conf.LogicalConfig.ReadTimeout,
-		Partitions:           []store.Partition{p},
-		Logger:               qm.conf.Logger,
-		QueueInfo:            info,
+		Limits:     qm.conf.LogicalConfig.Limits,
+		Partitions: []store.Partition{p},
+		Tracer:     qm.conf.LogicalConfig.Tracer,
+		Metrics:    qm.conf.LogicalConfig.Metrics,
+		Clock:      qm.conf.LogicalConfig.Clock,
+		Logger:     qm.conf.Logger,
+		QueueInfo:  info,
 	})
 	if err != nil {
 		return nil, f.Wrap(err)
 	}
 
+	if qm.draining.Load() {
+		if err := l.Drain(ctx); err != nil {
+			return nil, f.Wrap(err)
+		}
+	}
+
 	// TODO: This should become a list of queues which hold logical queues
-	qm.queues[info.Name] = l
+	qm.queues[info.Key()] = l
+	// The usage of the queue is now tracked by the running queue
+	delete(qm.idle[info.Namespace], info.Key())
 	return l, nil
 }
 
-func (qm *QueuesManager) List(ctx context.Context, items *[]types.QueueInfo, opts types.ListOptions) error {
+// CheckQuota returns an error if adding the number of items and bytes provided to a queue in the
+// namespace would exceed the quota for that namespace. Usage is counted from the queues in the
+// namespace which are running, and from the partitions of the queues which have not been started
+// since the service started.
+func (qm *QueuesManager) CheckQuota(ctx context.Context, namespace string, items, bytes int64) error {
+	qm.mutex.Lock()
+	quota := qm.quota(namespace)
+	qm.mutex.Unlock()
+	if quota.MaxItems == 0 && quota.MaxBytes == 0 {
+		return nil
+	}
+
+	queues, idle, err := qm.namespaceUsage(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	var stale bool
+	usedItems, usedBytes := items+idle.items, bytes+idle.bytes
+	for _, q := range queues {
+		if !q.UsageLoaded() {
+			if err := q.RefreshUsage(ctx); err != nil {
+				return err
+			}
+		}
+		i, b, s := q.Usage()
+		usedItems += i
+		usedBytes += b
+		stale = stale || s
+	}
+
+	if quotaExceeded(quota, usedItems, usedBytes) && stale {
+		// Our usage counts may include items which have since been completed
+		// or deleted. Refresh the counts before deciding to reject the request.
+		usedItems, usedBytes = items+idle.items, bytes+idle.bytes
+		for _, q := range queues {
+			if _, _, s := q.Usage(); s {
+				if err := q.RefreshUsage(ctx); err != nil {
+					return err
+				}
+			}
+			i, b, _ := q.Usage()
+			usedItems += i
+			usedBytes += b
+		}
+	}
+
+	if quota.MaxItems != 0 && usedItems > quota.MaxItems {
+		return transport.NewRetryRequest("%s; namespace '%s' is limited to %d items",
+			MsgNamespaceQuotaFull, namespace, quota.MaxItems)
+	}
+	if quota.MaxBytes != 0 && usedBytes > quota.MaxBytes {
+		return transport.NewRetryRequest("%s; namespace '%s' is limited to %d bytes",
+			MsgNamespaceQuotaFull, namespace, quota.MaxBytes)
+	}
+	return nil
+}
+
+// idleUsage is the number of items and bytes held by the partitions of a queue which is not running
+type idleUsage struct {
+	items int64
+	bytes int64
+}
+
+// namespaceUsage returns the running queues within the namespace, along with the total usage of the
+// queues in the namespace which are not running. Queues are not started in order to count their usage,
+// as a namespace may hold many queues which are not in use. Instead, the partitions of the queues which
+// are not running are read the first time the usage of the namespace is requested. Since only a running
+// queue can change the items in its partitions, the usage read remains accurate until the queue starts.
+func (qm *QueuesManager) namespaceUsage(ctx context.Context, namespace string) ([]*Logical, idleUsage, error) {
+	if qm.inShutdown.Load() {
+		return nil, idleUsage{}, ErrServiceShutdown
+	}
+	defer qm.mutex.Unlock()
+	qm.mutex.Lock()
+
+	if _, ok := qm.idle[namespace]; !ok {
+		usage, err := qm.readIdleUsage(ctx, namespace)
+		if err != nil {
+			return nil, idleUsage{}, err
+		}
+		qm.idle[namespace] = usage
+	}
+
+	var total idleUsage
+	for _, u := range qm.idle[namespace] {
+		total.items += u.items
+		total.bytes += u.bytes
+	}
+
+	var results []*Logical
+	for _, q := range qm.queues {
+		if q.Info().Namespace == namespace {
+			results = append(results, q)
+		}
+	}
+	return results, total, nil
+}
+
+// readIdleUsage reads the usage of every queue in the namespace which is not running from the
+// partitions of the queue. The caller must hold the mutex.
+func (qm *QueuesManager) readIdleUsage(ctx context.Context, namespace string) (map[string]idleUsage, error) {
+	f := errors.Fields{"category", "querator", "func", "QueuesManager.readIdleUsage"}
+	results := make(map[string]idleUsage)
+
+	var pivot []byte
+	for {
+		queues := make([]types.QueueInfo, 0, 1_000)
+		if err := qm.conf.StorageConfig.QueueStore.List(ctx, namespace, &queues,
+			types.ListOptions{Pivot: pivot, Limit: 1_000}); err != nil {
+			return nil, f.Errorf("QueueStore.List(): %w", err)
+		}
+		// The pivot is included in the results
+		if pivot != nil && len(queues) != 0 && queues[0].Name == string(pivot) {
+			queues = queues[1:]
+		}
+		if len(queues) == 0 {
+			return results, nil
+		}
+
+		for _, info := range queues {
+			if _, ok := qm.queues[info.Key()]; ok {
+				continue
+			}
+			var u idleUsage
+			for _, pi := range info.PartitionInfo {
+				var qs types.QueueStats
+				p := qm.conf.StorageConfig.Backends[0].PartitionStore.Get(pi)
+				err := p.Stats(ctx, &qs)
+				_ = p.Close(ctx)
+				if err != nil {
+					f = append(f, "queue", info.Name, "partition", pi.Partition)
+					return nil, f.Errorf("Partition.Stats(): %w", err)
+				}
+				u.items += int64(qs.Total)
+				u.bytes += qs.TotalBytes
+			}
+			results[info.Key()] = u
+		}
+		pivot = []byte(queues[len(queues)-1].Name)
+	}
+}
+
+// quota returns the quota for the namespace, the caller must hold the mutex
+func (qm *QueuesManager) quota(namespace string) types.NamespaceQuota {
+	if q, ok := qm.conf.NamespaceQuotas[namespace]; ok {
+		return q
+	}
+	return qm.conf.DefaultNamespaceQuota
+}
+
+func quotaExceeded(quota types.NamespaceQuota, items, bytes int64) bool {
+	return (quota.MaxItems != 0 && items > quota.MaxItems) ||
+		(quota.MaxBytes != 0 && bytes > quota.MaxBytes)
+}
+
+func (qm *QueuesManager) List(ctx context.Context, namespace string, items *[]types.QueueInfo,
+	opts types.ListOptions) error {
 	if qm.inShutdown.Load() {
 		return ErrServiceShutdown
 	}
 	defer qm.mutex.Unlock()
 	qm.mutex.Lock()
 
-	return qm.conf.StorageConfig.QueueStore.List(ctx, items, opts)
+	return qm.conf.StorageConfig.QueueStore.List(ctx, namespace, items, opts)
 }
 
 func (qm *QueuesManager) Update(ctx context.Context, info types.QueueInfo) error {
@@ -203,11 +592,17 @@ func (qm *QueuesManager) Update(ctx context.Context, info types.QueueInfo) error
 	}
 
 	// If the queue is currently in use
-	q, ok := qm.queues[info.Name]
+	q, ok := qm.queues[info.Key()]
 	if !ok {
 		return nil
 	}
 
+	// The info provided only includes the fields which changed, fetch the complete
+	// queue info from the store
+	if err := qm.conf.StorageConfig.QueueStore.Get(ctx, info.Namespace, info.Name, &info); err != nil {
+		return f.Errorf("QueueStore.Get(): %w", err)
+	}
+
 	// Update the active queue with the latest queue info
 	if err := q.UpdateInfo(ctx, info); err != nil {
 		return f.Errorf("LogicalQueue.UpdateInfo(): %w", err)
@@ -216,7 +611,7 @@ func (qm *QueuesManager) Update(ctx context.Context, info types.QueueInfo) error
 	return nil
 }
 
-func (qm *QueuesManager) Delete(ctx context.Context, name string) error {
+func (qm *QueuesManager) Delete(ctx context.Context, namespace, name string) error {
 	if qm.inShutdown.Load() {
 		return ErrServiceShutdown
 	}
@@ -225,12 +620,14 @@ func (qm *QueuesManager) Delete(ctx context.Context, name string) error {
 	qm.mutex.Lock()
 
 	// TODO: Delete should return transport.NewInvalidOption("queue does not exist; no such queue named '%s'", name)
-	if err := qm.conf.StorageConfig.QueueStore.Delete(ctx, name); err != nil {
+	if err := qm.conf.StorageConfig.QueueStore.Delete(ctx, namespace, name); err != nil {
 		return f.Errorf("QueueStore.Delete(): %w", err)
 	}
 
 	// If the queue is currently in use
-	q, ok := qm.queues[name]
+	key := types.QueueKey(namespace, name)
+	delete(qm.idle[namespace], key)
+	q, ok := qm.queues[key]
 	if !ok {
 		return nil
 	}
@@ -240,10 +637,48 @@ func (qm *QueuesManager) Delete(ctx context.Context, name string) error {
 		return f.Errorf("LogicalQueue.Shutdown(): %w", err)
 	}
 
-	delete(qm.queues, name)
+	delete(qm.queues, key)
+	qm.conf.LogicalConfig.Metrics.Remove(namespace, name)
 	return nil
 }
 
+// Describe fetches prometheus metrics to be registered
+func (qm *QueuesManager) Describe(ch chan<- *prometheus.Desc) {
+	qm.conf.LogicalConfig.Metrics.Describe(ch)
+}
+
+// Collect fetches metrics from the server for use by prometheus. The depth, reserved count and
+// age of the oldest item are cached by each running queue, such that a scrape never waits on the
+// storage backend. See Logical.CachedStats() for details.
+func (qm *QueuesManager) Collect(ch chan<- prometheus.Metric) {
+	m := qm.conf.LogicalConfig.Metrics
+	m.Collect(ch)
+
+	qm.mutex.Lock()
+	queues := make([]*Logical, 0, len(qm.queues))
+	for _, q := range qm.queues {
+		queues = append(queues, q)
+	}
+	qm.mutex.Unlock()
+
+	for _, q := range queues {
+		stats, ok := q.CachedStats(collectMaxAge)
+		if !ok {
+			continue
+		}
+		// The stats are read from the first partition of the queue, as the Logical does not yet
+		// distribute items across partitions, the first partition holds every item of the queue.
+		info := q.Info()
+		partition := strconv.Itoa(info.PartitionInfo[0].Partition)
+		ch <- prometheus.MustNewConstMetric(m.depth, prometheus.GaugeValue,
+			float64(stats.Total), info.Namespace, info.Name, partition)
+		ch <- prometheus.MustNewConstMetric(m.reserved, prometheus.GaugeValue,
+			float64(stats.TotalReserved), info.Namespace, info.Name, partition)
+		ch <- prometheus.MustNewConstMetric(m.oldestAge, prometheus.GaugeValue,
+			stats.OldestAge.Seconds(), info.Namespace, info.Name, partition)
+	}
+}
+
 func (qm *QueuesManager) Shutdown(ctx context.Context) error {
 	if qm.inShutdown.Load() {
 		return nil
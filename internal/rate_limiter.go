@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"container/list"
+	"github.com/kapetan-io/querator/internal/types"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/set"
+	"math"
+	"sync"
+	"time"
+)
+
+const MsgRateLimited = "rate limit exceeded"
+
+// maxBuckets is the number of buckets the RateLimiter will hold before it
+// starts removing the least recently used buckets.
+const maxBuckets = 10_000
+
+type RateLimiterConfig struct {
+	// Clock is the clock provider used to calculate the current time
+	Clock *clock.Provider
+}
+
+// RateLimiter is a collection of token buckets keyed by a string. Each call to Allow() takes a single
+// token from the bucket identified by the key. Buckets are created on demand and start out full.
+// Once the RateLimiter holds maxBuckets, the least recently used bucket is removed for each new
+// bucket, such that requests with many distinct keys cannot grow the RateLimiter without bound.
+type RateLimiter struct {
+	buckets map[string]*list.Element
+	// lru holds the buckets ordered from the most to the least recently used
+	lru   *list.List
+	conf  RateLimiterConfig
+	mutex sync.Mutex
+}
+
+type tokenBucket struct {
+	updatedAt time.Time
+	key       string
+	tokens    float64
+	limit     types.RateLimit
+}
+
+func NewRateLimiter(conf RateLimiterConfig) *RateLimiter {
+	set.Default(&conf.Clock, clock.NewProvider())
+
+	return &RateLimiter{
+		buckets: make(map[string]*list.Element),
+		lru:     list.New(),
+		conf:    conf,
+	}
+}
+
+// Allow takes a token from the bucket identified by the key. If the bucket is empty, Allow returns false
+// along with the duration the caller should wait before a token is available. Allow always returns true
+// if the limit provided is not enforced.
+func (r *RateLimiter) Allow(key string, limit types.RateLimit) (bool, clock.Duration) {
+	if limit.Rate <= 0 {
+		return true, 0
+	}
+	set.Default(&limit.Burst, 1)
+
+	defer r.mutex.Unlock()
+	r.mutex.Lock()
+
+	now := r.conf.Clock.Now()
+	var b *tokenBucket
+	if e, ok := r.buckets[key]; ok {
+		r.lru.MoveToFront(e)
+		b = e.Value.(*tokenBucket)
+	} else {
+		if r.lru.Len() >= maxBuckets {
+			oldest := r.lru.Back()
+			r.lru.Remove(oldest)
+			delete(r.buckets, oldest.Value.(*tokenBucket).key)
+		}
+		b = &tokenBucket{
+			tokens:    float64(limit.Burst),
+			updatedAt: now,
+			limit:     limit,
+			key:       key,
+		}
+		r.buckets[key] = r.lru.PushFront(b)
+	}
+	// A bucket is reset if the limit was changed
+	if b.limit != limit {
+		b.tokens = float64(limit.Burst)
+		b.updatedAt = now
+		b.limit = limit
+	}
+
+	b.refill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	// Calculate how long until the next token is available
+	wait := (1 - b.tokens) / limit.Rate
+	return false, clock.Duration(math.Ceil(wait * float64(clock.Second)))
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updatedAt)
+	if elapsed <= 0 {
+		return
+	}
+	b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed.Seconds()*b.limit.Rate)
+	b.updatedAt = now
+}
@@ -3,20 +3,28 @@ package store
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"encoding/gob"
 	"fmt"
 	"github.com/duh-rpc/duh-go"
 	"github.com/kapetan-io/errors"
 	"github.com/kapetan-io/querator/internal/types"
+	pb "github.com/kapetan-io/querator/proto"
 	"github.com/kapetan-io/querator/transport"
 	"github.com/kapetan-io/tackle/clock"
 	"github.com/segmentio/ksuid"
 	bolt "go.etcd.io/bbolt"
+	"os"
 	"path/filepath"
 )
 
 var bucketName = []byte("queue")
 
+// dedupBucketName holds the DedupDeadline of each DedupID produced, dedupExpireBucketName holds the same
+// entries keyed by DedupDeadline such that expired entries can be found without scanning every entry.
+var dedupBucketName = []byte("dedup")
+var dedupExpireBucketName = []byte("dedup-expire")
+
 type BoltConfig struct {
 	// StorageDir is the directory where bolt will store its data
 	StorageDir string
@@ -44,7 +52,7 @@ func NewBoltPartitionStore(conf BoltConfig) *BoltPartitionStore {
 func (b BoltPartitionStore) Create(info types.PartitionInfo) error {
 	f := errors.Fields{"category", "bolt", "func", "BoltPartitionStore.Create"}
 
-	file := filepath.Join(b.conf.StorageDir, fmt.Sprintf("%s-%06d.db", info.QueueName, info.Partition))
+	file := partitionFile(b.conf.StorageDir, info)
 
 	opts := &bolt.Options{
 		FreelistType: bolt.FreelistArrayType,
@@ -70,6 +78,20 @@ func (b BoltPartitionStore) Create(info types.PartitionInfo) error {
 	return db.Close()
 }
 
+// Ping verifies the storage directory exists, as partitions are opened on demand
+func (b BoltPartitionStore) Ping(_ context.Context) error {
+	f := errors.Fields{"category", "bolt", "func", "BoltPartitionStore.Ping"}
+
+	info, err := os.Stat(b.conf.StorageDir)
+	if err != nil {
+		return f.Errorf("while accessing storage dir: %w", err)
+	}
+	if !info.IsDir() {
+		return f.Errorf("storage dir '%s' is not a directory", b.conf.StorageDir)
+	}
+	return nil
+}
+
 func (b BoltPartitionStore) Get(info types.PartitionInfo) Partition {
 	return &BoltPartition{
 		uid:  ksuid.New(),
@@ -87,6 +109,13 @@ type BoltPartition struct {
 	conf BoltConfig
 	uid  ksuid.KSUID
 	db   *bolt.DB
+	// scheduler decides which priority level the next reserved item is taken from
+	scheduler priorityScheduler
+	// expired is the number of expired items removed since the partition was opened
+	expired int
+	// stored is the number of items of each priority level in the partition, it is counted when
+	// first needed and is nil if the count is unknown.
+	stored *priorityCounts
 }
 
 func (b *BoltPartition) Produce(_ context.Context, batch types.Batch[types.ProduceRequest]) error {
@@ -96,17 +125,47 @@ func (b *BoltPartition) Produce(_ context.Context, batch types.Batch[types.Produ
 		return err
 	}
 
-	return db.Update(func(tx *bolt.Tx) error {
+	var added priorityCounts
+	err = db.Update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket(bucketName)
 		if bucket == nil {
 			return f.Error("bucket does not exist in data file")
 		}
 
+		// Partitions created before dedup was introduced do not have the dedup buckets
+		dedup, err := tx.CreateBucketIfNotExists(dedupBucketName)
+		if err != nil {
+			return f.Errorf("during CreateBucketIfNotExists(): %w", err)
+		}
+		expire, err := tx.CreateBucketIfNotExists(dedupExpireBucketName)
+		if err != nil {
+			return f.Errorf("during CreateBucketIfNotExists(): %w", err)
+		}
+
+		now := b.conf.Clock.Now().UTC()
+		if err := expireDedup(dedup, expire, now); err != nil {
+			return f.Errorf("while expiring dedup ids: %w", err)
+		}
+
 		for _, r := range batch.Requests {
+			items := r.Items[:0]
 			for _, item := range r.Items {
+				if item.DedupID != "" {
+					if v := dedup.Get([]byte(item.DedupID)); v != nil && now.Before(decodeDedupDeadline(v)) {
+						continue
+					}
+					deadline := encodeDedupDeadline(item.DedupDeadline)
+					if err := dedup.Put([]byte(item.DedupID), deadline); err != nil {
+						return f.Errorf("during dedup Put(): %w", err)
+					}
+					if err := expire.Put(append(deadline, item.DedupID...), []byte{}); err != nil {
+						return f.Errorf("during dedup expire Put(): %w", err)
+					}
+				}
+
 				b.uid = b.uid.Next()
 				item.ID = []byte(b.uid.String())
-				item.CreatedAt = b.conf.Clock.Now().UTC()
+				item.CreatedAt = now
 
 				// TODO: Get buffers from memory pool
 				var buf bytes.Buffer
@@ -117,13 +176,53 @@ func (b *BoltPartition) Produce(_ context.Context, batch types.Batch[types.Produ
 				if err := bucket.Put(item.ID, buf.Bytes()); err != nil {
 					return f.Errorf("during Put(): %w", err)
 				}
+				added[item.Priority]++
+				items = append(items, item)
 			}
+			r.Items = items
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	b.addStored(added, 1)
+	return nil
+}
+
+// expireDedup removes the dedup ids whose DedupDeadline has passed
+func expireDedup(dedup, expire *bolt.Bucket, now clock.Time) error {
+	c := expire.Cursor()
+	for k, _ := c.First(); k != nil; k, _ = c.First() {
+		if now.Before(decodeDedupDeadline(k)) {
+			return nil
+		}
+		// The id might have been produced again after it expired
+		id := k[8:]
+		if v := dedup.Get(id); v != nil && bytes.Equal(v, k[:8]) {
+			if err := dedup.Delete(id); err != nil {
+				return err
+			}
+		}
+		if err := c.Delete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeDedupDeadline encodes the deadline such that the encoded deadlines sort in chronological order
+func encodeDedupDeadline(deadline clock.Time) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(deadline.UnixNano()))
+	return buf
 }
 
-func (b *BoltPartition) Reserve(_ context.Context, batch types.ReserveBatch, opts ReserveOptions) error {
+func decodeDedupDeadline(b []byte) clock.Time {
+	return clock.Unix(0, int64(binary.BigEndian.Uint64(b[:8]))).UTC()
+}
+
+func (b *BoltPartition) Reserve(_ context.Context, batch *types.ReserveBatch, opts ReserveOptions) error {
 	f := errors.Fields{"category", "bolt", "func", "Partition.Reserve"}
 
 	db, err := b.getDB()
@@ -131,7 +230,8 @@ func (b *BoltPartition) Reserve(_ context.Context, batch types.ReserveBatch, opt
 		return err
 	}
 
-	return db.Update(func(tx *bolt.Tx) error {
+	var removed priorityCounts
+	err = db.Update(func(tx *bolt.Tx) error {
 
 		bucket := tx.Bucket(bucketName)
 		if bucket == nil {
@@ -139,29 +239,14 @@ func (b *BoltPartition) Reserve(_ context.Context, batch types.ReserveBatch, opt
 		}
 
 		batchIter := batch.Iterator()
-		c := bucket.Cursor()
-		var count int
-
-		// We preform a full scan of the entire bucket to find our reserved items.
-		// I might entertain using an index for this if Bolt becomes a popular choice
-		// in production.
-		for k, v := c.First(); k != nil; k, v = c.Next() {
-			if count >= batch.Total {
-				break
-			}
-
-			item := new(types.Item) // TODO: memory pool
-			if err := gob.NewDecoder(bytes.NewReader(v)).Decode(item); err != nil {
-				return f.Errorf("during Decode(): %w", err)
-			}
-
-			if item.IsReserved {
-				continue
-			}
+		reservable, expired, err := b.reservable(bucket, batch.Total, opts.PriorityWeights, &b.scheduler)
+		if err != nil {
+			return f.Wrap(err)
+		}
 
-			item.ReserveDeadline = opts.ReserveDeadline
+		for _, item := range reservable {
+			item.ReserveDeadline = opts.Deadline(item)
 			item.IsReserved = true
-			count++
 
 			// Assign the item to the next waiting reservation in the batch,
 			// returns false if there are no more reservations available to fill
@@ -179,6 +264,132 @@ func (b *BoltPartition) Reserve(_ context.Context, batch types.ReserveBatch, opt
 			}
 			break
 		}
+
+		for _, item := range expired {
+			if err := bucket.Delete(item.ID); err != nil {
+				return f.Errorf("during Delete(): %w", err)
+			}
+			removed[item.Priority]++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	b.expired += sum(removed)
+	batch.Expired = sum(removed)
+	b.addStored(removed, -1)
+	return nil
+}
+
+func (b *BoltPartition) Peek(_ context.Context, items *[]*types.Item, opts PeekOptions) error {
+	f := errors.Fields{"category", "bolt", "func", "Partition.Peek"}
+
+	db, err := b.getDB()
+	if err != nil {
+		return err
+	}
+
+	return db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName)
+		if bucket == nil {
+			return f.Error("bucket does not exist in data file")
+		}
+
+		// Use a copy of the scheduler such that peeking does not change the order of future reservations
+		scheduler := b.scheduler
+		reservable, _, err := b.reservable(bucket, opts.Limit, opts.PriorityWeights, &scheduler)
+		if err != nil {
+			return f.Wrap(err)
+		}
+		*items = append(*items, reservable...)
+		return nil
+	})
+}
+
+// reservable returns up to limit items in the order they should be reserved, and any unreserved
+// items found to have expired.
+func (b *BoltPartition) reservable(bucket *bolt.Bucket, limit int, weights []int,
+	scheduler *priorityScheduler) ([]*types.Item, []*types.Item, error) {
+
+	now := b.conf.Clock.Now().UTC()
+	stored, err := b.storedCounts(bucket)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Scan the bucket in the order items were produced to find the oldest unreserved items of each
+	// priority level, stopping once enough items of every level stored have been found. I might entertain
+	// using an index per priority level if Bolt becomes a popular choice in production.
+	var levels [types.NumPriorities][]*types.Item
+	var found, seen priorityCounts
+	var expired []*types.Item
+	keys := make(orderingKeys)
+	c := bucket.Cursor()
+	for k, v := c.First(); k != nil && !scanDone(&found, &seen, stored, limit); k, v = c.Next() {
+		item := new(types.Item) // TODO: memory pool
+		if err := gob.NewDecoder(bytes.NewReader(v)).Decode(item); err != nil {
+			return nil, nil, fmt.Errorf("during Decode(): %w", err)
+		}
+		seen[item.Priority]++
+
+		if !item.IsReserved && item.IsExpired(now) {
+			expired = append(expired, item)
+			continue
+		}
+
+		if !keys.first(item) || item.IsReserved {
+			continue
+		}
+
+		if p := item.Priority; found[p] < limit {
+			levels[p] = append(levels[p], item)
+			found[p]++
+		}
+	}
+
+	results := make([]*types.Item, 0, limit)
+	for len(results) < limit {
+		level := scheduler.next(weights, func(l int) bool { return len(levels[l]) != 0 })
+		if level == -1 {
+			break
+		}
+		results = append(results, levels[level][0])
+		levels[level] = levels[level][1:]
+	}
+	return results, expired, nil
+}
+
+func (b *BoltPartition) Get(_ context.Context, ids []types.ItemID, items *[]*types.Item) error {
+	f := errors.Fields{"category", "bolt", "func", "Partition.Get"}
+
+	db, err := b.getDB()
+	if err != nil {
+		return err
+	}
+
+	return db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName)
+		if bucket == nil {
+			return f.Error("bucket does not exist in data file")
+		}
+
+		for _, id := range ids {
+			if err := b.validateID(id); err != nil {
+				return transport.NewInvalidOption("invalid storage id; '%s': %s", id, err)
+			}
+
+			value := bucket.Get(id)
+			if value == nil {
+				return transport.NewInvalidOption("invalid storage id; '%s' does not exist", id)
+			}
+
+			item := new(types.Item) // TODO: memory pool
+			if err := gob.NewDecoder(bytes.NewReader(value)).Decode(item); err != nil {
+				return f.Errorf("during Decode(): %w", err)
+			}
+			*items = append(*items, item)
+		}
 		return nil
 	})
 }
@@ -210,35 +421,48 @@ func (b *BoltPartition) Complete(_ context.Context, batch types.Batch[types.Comp
 		return f.Error("bucket does not exist in data file")
 	}
 
+	// The priority of each item deleted, ids repeated in the same request are only deleted once
+	deleted := make(map[string]int)
+
 nextBatch:
 	for i := range batch.Requests {
-		for _, id := range batch.Requests[i].Ids {
-			if err = b.validateID(id); err != nil {
-				batch.Requests[i].Err = transport.NewInvalidOption("invalid storage id; '%s': %s", id, err)
-				continue nextBatch
-			}
-
-			// TODO: Test complete with id's that do not exist in the database
-			value := bucket.Get(id)
-			if value == nil {
-				batch.Requests[i].Err = transport.NewInvalidOption("invalid storage id; '%s' does not exist", id)
-				continue nextBatch
+		req := batch.Requests[i]
+		if req.Detailed {
+			// Complete each id independently, recording the outcome of each
+			for _, id := range req.Ids {
+				item, result, err := b.completable(bucket, id)
+				if err != nil {
+					return f.Wrap(err)
+				}
+				if result.Status == pb.CompleteStatus_COMPLETE_OK {
+					if err := bucket.Delete(id); err != nil {
+						return f.Errorf("during Delete(%s): %w", id, err)
+					}
+					deleted[string(id)] = item.Priority
+				}
+				req.Results = append(req.Results, result)
 			}
+			continue
+		}
 
-			item := new(types.Item) // TODO: memory pool
-			if err = gob.NewDecoder(bytes.NewReader(value)).Decode(item); err != nil {
-				return f.Errorf("during Decode(): %w", err)
+		// Validate all the ids before completing any of them
+		priorities := make([]int, 0, len(req.Ids))
+		for _, id := range req.Ids {
+			item, result, err := b.completable(bucket, id)
+			if err != nil {
+				return f.Wrap(err)
 			}
-
-			if !item.IsReserved {
-				batch.Requests[i].Err = transport.NewConflict("item(s) cannot be completed; '%s' is not "+
-					"marked as reserved", id)
+			if result.Status != pb.CompleteStatus_COMPLETE_OK {
+				req.Err = completeError(result)
 				continue nextBatch
 			}
-
+			priorities = append(priorities, item.Priority)
+		}
+		for i, id := range req.Ids {
 			if err = bucket.Delete(id); err != nil {
 				return f.Errorf("during Delete(%s): %w", id, err)
 			}
+			deleted[string(id)] = priorities[i]
 		}
 	}
 
@@ -248,9 +472,159 @@ nextBatch:
 	}
 
 	done = true
+	var removed priorityCounts
+	for _, priority := range deleted {
+		removed[priority]++
+	}
+	b.addStored(removed, -1)
 	return nil
 }
 
+// completable returns the item and the result of completing the item, or an error if the item could
+// not be read. The item is nil if it does not exist.
+func (b *BoltPartition) completable(bucket *bolt.Bucket, id types.ItemID) (*types.Item,
+	types.CompleteResult, error) {
+	if err := b.validateID(id); err != nil {
+		return nil, validateComplete(id, err, nil), nil
+	}
+
+	value := bucket.Get(id)
+	if value == nil {
+		return nil, validateComplete(id, nil, nil), nil
+	}
+
+	item := new(types.Item) // TODO: memory pool
+	if err := gob.NewDecoder(bytes.NewReader(value)).Decode(item); err != nil {
+		return nil, types.CompleteResult{}, fmt.Errorf("during Decode(): %w", err)
+	}
+	return item, validateComplete(id, nil, item), nil
+}
+
+func (b *BoltPartition) Extend(_ context.Context, batch types.Batch[types.ExtendRequest]) error {
+	f := errors.Fields{"category", "bolt", "func", "Partition.Extend"}
+	now := b.conf.Clock.Now().UTC()
+
+	db, err := b.getDB()
+	if err != nil {
+		return err
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName)
+		if bucket == nil {
+			return f.Error("bucket does not exist in data file")
+		}
+
+		items := make([]*types.Item, 0, 10)
+	nextBatch:
+		for i := range batch.Requests {
+			// Validate all the ids before extending any of them
+			items = items[:0]
+			for _, id := range batch.Requests[i].Ids {
+				if err := b.validateID(id); err != nil {
+					batch.Requests[i].Err = transport.NewInvalidOption("invalid storage id; '%s': %s", id, err)
+					continue nextBatch
+				}
+
+				value := bucket.Get(id)
+				if value == nil {
+					batch.Requests[i].Err = transport.NewInvalidOption("invalid storage id; '%s' does not exist", id)
+					continue nextBatch
+				}
+
+				item := new(types.Item) // TODO: memory pool
+				if err := gob.NewDecoder(bytes.NewReader(value)).Decode(item); err != nil {
+					return f.Errorf("during Decode(): %w", err)
+				}
+
+				if err := validateReservation(item, now, "extended"); err != nil {
+					batch.Requests[i].Err = err
+					continue nextBatch
+				}
+				items = append(items, item)
+			}
+
+			batch.Requests[i].ReserveDeadlines = make([]clock.Time, 0, len(items))
+			for _, item := range items {
+				item.ReserveDeadline = batch.Requests[i].Deadline(item)
+				batch.Requests[i].ReserveDeadlines = append(batch.Requests[i].ReserveDeadlines, item.ReserveDeadline)
+
+				var buf bytes.Buffer // TODO: memory pool
+				if err := gob.NewEncoder(&buf).Encode(item); err != nil {
+					return f.Errorf("during gob.Encode(): %w", err)
+				}
+
+				if err := bucket.Put(item.ID, buf.Bytes()); err != nil {
+					return f.Errorf("during Put(): %w", err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (b *BoltPartition) Release(_ context.Context, batch types.Batch[types.ReleaseRequest]) error {
+	f := errors.Fields{"category", "bolt", "func", "Partition.Release"}
+	now := b.conf.Clock.Now().UTC()
+
+	db, err := b.getDB()
+	if err != nil {
+		return err
+	}
+
+	return db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName)
+		if bucket == nil {
+			return f.Error("bucket does not exist in data file")
+		}
+
+		items := make([]*types.Item, 0, 10)
+	nextBatch:
+		for i := range batch.Requests {
+			// Validate all the ids before releasing any of them
+			items = items[:0]
+			for _, id := range batch.Requests[i].Ids {
+				if err := b.validateID(id); err != nil {
+					batch.Requests[i].Err = transport.NewInvalidOption("invalid storage id; '%s': %s", id, err)
+					continue nextBatch
+				}
+
+				value := bucket.Get(id)
+				if value == nil {
+					batch.Requests[i].Err = transport.NewInvalidOption("invalid storage id; '%s' does not exist", id)
+					continue nextBatch
+				}
+
+				item := new(types.Item) // TODO: memory pool
+				if err := gob.NewDecoder(bytes.NewReader(value)).Decode(item); err != nil {
+					return f.Errorf("during Decode(): %w", err)
+				}
+
+				if err := validateReservation(item, now, "released"); err != nil {
+					batch.Requests[i].Err = err
+					continue nextBatch
+				}
+				items = append(items, item)
+			}
+
+			// Items are stored by id, so updating the item in place keeps its position in the queue
+			for _, item := range items {
+				release(item, batch.Requests[i].SkipAttempt)
+
+				var buf bytes.Buffer // TODO: memory pool
+				if err := gob.NewEncoder(&buf).Encode(item); err != nil {
+					return f.Errorf("during gob.Encode(): %w", err)
+				}
+
+				if err := bucket.Put(item.ID, buf.Bytes()); err != nil {
+					return f.Errorf("during Put(): %w", err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
 func (b *BoltPartition) List(_ context.Context, items *[]*types.Item, opts types.ListOptions) error {
 	f := errors.Fields{"category", "bolt", "func", "Partition.List"}
 
@@ -321,7 +695,8 @@ func (b *BoltPartition) Add(_ context.Context, items []*types.Item) error {
 		return err
 	}
 
-	return db.Update(func(tx *bolt.Tx) error {
+	var added priorityCounts
+	err = db.Update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket(bucketName)
 		if bucket == nil {
 			return f.Error("bucket does not exist in data file")
@@ -341,9 +716,15 @@ func (b *BoltPartition) Add(_ context.Context, items []*types.Item) error {
 			if err := bucket.Put(item.ID, buf.Bytes()); err != nil {
 				return f.Errorf("during Put(): %w", err)
 			}
+			added[item.Priority]++
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+	b.addStored(added, 1)
+	return nil
 }
 
 func (b *BoltPartition) Delete(_ context.Context, ids []types.ItemID) error {
@@ -354,6 +735,9 @@ func (b *BoltPartition) Delete(_ context.Context, ids []types.ItemID) error {
 		return err
 	}
 
+	// The priority of the items deleted is not known, so they are counted again when next needed
+	b.stored = nil
+
 	return db.Update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket(bucketName)
 		if bucket == nil {
@@ -380,6 +764,8 @@ func (b *BoltPartition) Clear(_ context.Context, destructive bool) error {
 		return err
 	}
 
+	b.stored = nil
+
 	return db.Update(func(tx *bolt.Tx) error {
 		if destructive {
 			if err := tx.DeleteBucket(bucketName); err != nil {
@@ -388,6 +774,14 @@ func (b *BoltPartition) Clear(_ context.Context, destructive bool) error {
 			if _, err := tx.CreateBucket(bucketName); err != nil {
 				return f.Errorf("while re-creating with CreateBucket()): %w", err)
 			}
+			for _, name := range [][]byte{dedupBucketName, dedupExpireBucketName} {
+				if tx.Bucket(name) == nil {
+					continue
+				}
+				if err := tx.DeleteBucket(name); err != nil {
+					return f.Errorf("during destructive DeleteBucket(): %w", err)
+				}
+			}
 			return nil
 		}
 
@@ -418,6 +812,7 @@ func (b *BoltPartition) Clear(_ context.Context, destructive bool) error {
 func (b *BoltPartition) Stats(_ context.Context, stats *types.QueueStats) error {
 	f := errors.Fields{"category", "bunt-db", "func", "Partition.Stats"}
 	now := b.conf.Clock.Now().UTC()
+	stats.TotalExpired += b.expired
 
 	db, err := b.getDB()
 	if err != nil {
@@ -440,10 +835,16 @@ func (b *BoltPartition) Stats(_ context.Context, stats *types.QueueStats) error
 			}
 
 			stats.Total++
+			stats.TotalBytes += int64(len(item.Payload))
 			stats.AverageAge += now.Sub(item.CreatedAt)
+			if age := now.Sub(item.CreatedAt); age > stats.OldestAge {
+				stats.OldestAge = age
+			}
 			if item.IsReserved {
 				stats.AverageReservedAge += item.ReserveDeadline.Sub(now)
 				stats.TotalReserved++
+			} else if item.IsExpired(now) {
+				stats.TotalExpired++
 			}
 		}
 		if stats.Total != 0 {
@@ -457,12 +858,43 @@ func (b *BoltPartition) Stats(_ context.Context, stats *types.QueueStats) error
 }
 
 func (b *BoltPartition) Close(_ context.Context) error {
+	b.stored = nil
 	if b.db != nil {
 		return b.db.Close()
 	}
 	return nil
 }
 
+// storedCounts returns the number of items of each priority level in the bucket, counting the items
+// if the count is not already known.
+func (b *BoltPartition) storedCounts(bucket *bolt.Bucket) (*priorityCounts, error) {
+	if b.stored != nil {
+		return b.stored, nil
+	}
+
+	var stored priorityCounts
+	c := bucket.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		item := new(types.Item) // TODO: memory pool
+		if err := gob.NewDecoder(bytes.NewReader(v)).Decode(item); err != nil {
+			return nil, fmt.Errorf("during Decode(): %w", err)
+		}
+		stored[item.Priority]++
+	}
+	b.stored = &stored
+	return b.stored, nil
+}
+
+// addStored adds the counts provided multiplied by sign to the stored counts, if they are known
+func (b *BoltPartition) addStored(counts priorityCounts, sign int) {
+	if b.stored == nil {
+		return
+	}
+	for level, count := range counts {
+		b.stored[level] += sign * count
+	}
+}
+
 func (b *BoltPartition) validateID(id []byte) error {
 	_, err := ksuid.Parse(string(id))
 	if err != nil {
@@ -477,7 +909,7 @@ func (b *BoltPartition) getDB() (*bolt.DB, error) {
 	}
 
 	f := errors.Fields{"category", "bolt", "func", "BoltPartition.getDB"}
-	file := filepath.Join(b.conf.StorageDir, fmt.Sprintf("%s-%06d.db", b.info.QueueName, b.info.Partition))
+	file := partitionFile(b.conf.StorageDir, b.info)
 
 	opts := &bolt.Options{
 		FreelistType: bolt.FreelistArrayType,
@@ -494,6 +926,13 @@ func (b *BoltPartition) getDB() (*bolt.DB, error) {
 	return db, nil
 }
 
+// partitionFile returns the path to the bolt db file for the partition provided. Partitions of queues
+// which are not in the default namespace are prefixed with the namespace to avoid name collisions.
+func partitionFile(dir string, info types.PartitionInfo) string {
+	name := types.QueueKey(info.Namespace, info.QueueName)
+	return filepath.Join(dir, fmt.Sprintf("%s-%06d.db", name, info.Partition))
+}
+
 // ---------------------------------------------
 // QueueStore Implementation
 // ---------------------------------------------
@@ -546,10 +985,10 @@ func (b *BoltQueueStore) getDB() (*bolt.DB, error) {
 	return db, nil
 }
 
-func (b *BoltQueueStore) Get(_ context.Context, name string, queue *types.QueueInfo) error {
+func (b *BoltQueueStore) Get(_ context.Context, namespace, name string, queue *types.QueueInfo) error {
 	f := errors.Fields{"category", "bolt", "func", "QueueStore.Get"}
 
-	if err := b.validateGet(name); err != nil {
+	if err := b.validateGet(namespace, name); err != nil {
 		return err
 	}
 
@@ -564,7 +1003,7 @@ func (b *BoltQueueStore) Get(_ context.Context, name string, queue *types.QueueI
 			return f.Error("bucket does not exist in data file")
 		}
 
-		v := bucket.Get([]byte(name))
+		v := bucket.Get([]byte(types.QueueKey(namespace, name)))
 		if v == nil {
 			return ErrQueueNotExist
 		}
@@ -595,7 +1034,7 @@ func (b *BoltQueueStore) Add(_ context.Context, info types.QueueInfo) error {
 		}
 
 		// If the queue already exists in the store
-		if bucket.Get([]byte(info.Name)) != nil {
+		if bucket.Get([]byte(info.Key())) != nil {
 			return transport.NewInvalidOption("invalid queue; '%s' already exists", info.Name)
 		}
 
@@ -604,7 +1043,7 @@ func (b *BoltQueueStore) Add(_ context.Context, info types.QueueInfo) error {
 			return f.Errorf("during gob.Encode(): %w", err)
 		}
 
-		if err := bucket.Put([]byte(info.Name), buf.Bytes()); err != nil {
+		if err := bucket.Put([]byte(info.Key()), buf.Bytes()); err != nil {
 			return f.Errorf("during Put(): %w", err)
 		}
 		return nil
@@ -629,7 +1068,7 @@ func (b *BoltQueueStore) Update(_ context.Context, info types.QueueInfo) error {
 			return f.Error("bucket does not exist in data file")
 		}
 
-		v := bucket.Get([]byte(info.Name))
+		v := bucket.Get([]byte(info.Key()))
 		if v == nil {
 			return ErrQueueNotExist
 		}
@@ -655,17 +1094,18 @@ func (b *BoltQueueStore) Update(_ context.Context, info types.QueueInfo) error {
 			return f.Errorf("during gob.Encode(): %w", err)
 		}
 
-		if err := bucket.Put([]byte(info.Name), buf.Bytes()); err != nil {
+		if err := bucket.Put([]byte(info.Key()), buf.Bytes()); err != nil {
 			return f.Errorf("during Put(): %w", err)
 		}
 		return nil
 	})
 }
 
-func (b *BoltQueueStore) List(_ context.Context, queues *[]types.QueueInfo, opts types.ListOptions) error {
+func (b *BoltQueueStore) List(_ context.Context, namespace string, queues *[]types.QueueInfo,
+	opts types.ListOptions) error {
 	f := errors.Fields{"category", "bolt", "func", "QueueStore.List"}
 
-	if err := b.validateList(opts); err != nil {
+	if err := b.validateList(namespace, opts); err != nil {
 		return err
 	}
 
@@ -680,17 +1120,21 @@ func (b *BoltQueueStore) List(_ context.Context, queues *[]types.QueueInfo, opts
 			return f.Error("bucket does not exist in data file")
 		}
 
+		// Queues in a namespace other than the default namespace share the same key prefix
+		// See types.QueueKey() for details.
+		prefix := []byte(types.QueueKey(namespace, ""))
+
 		c := bucket.Cursor()
 		var count int
 		var k, v []byte
 		if opts.Pivot != nil {
-			k, v = c.Seek(opts.Pivot)
+			k, v = c.Seek([]byte(types.QueueKey(namespace, string(opts.Pivot))))
 			if k == nil {
 				return transport.NewInvalidOption("invalid pivot; '%s' does not exist", opts.Pivot)
 			}
 
 		} else {
-			k, v = c.First()
+			k, v = c.Seek(prefix)
 			if k == nil {
 				// TODO: Add a test for this code path, attempt to list an empty queue
 				// we get here if the bucket is empty
@@ -698,18 +1142,21 @@ func (b *BoltQueueStore) List(_ context.Context, queues *[]types.QueueInfo, opts
 			}
 		}
 
-		var info types.QueueInfo
-		if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&info); err != nil {
-			return f.Errorf("during Decode(): %w", err)
-		}
-		*queues = append(*queues, info)
-		count++
-
-		for k, v = c.Next(); k != nil; k, v = c.Next() {
+		for ; k != nil; k, v = c.Next() {
 			if count >= opts.Limit {
 				return nil
 			}
 
+			if namespace == "" {
+				// Skip queues which belong to other namespaces
+				if bytes.Contains(k, []byte("~")) {
+					continue
+				}
+			} else if !bytes.HasPrefix(k, prefix) {
+				// We have reached the end of the queues in this namespace
+				return nil
+			}
+
 			var info types.QueueInfo
 			if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&info); err != nil {
 				return f.Errorf("during Decode(): %w", err)
@@ -721,10 +1168,10 @@ func (b *BoltQueueStore) List(_ context.Context, queues *[]types.QueueInfo, opts
 	})
 }
 
-func (b *BoltQueueStore) Delete(_ context.Context, name string) error {
+func (b *BoltQueueStore) Delete(_ context.Context, namespace, name string) error {
 	f := errors.Fields{"category", "bolt", "func", "QueueStore.Delete"}
 
-	if err := b.validateDelete(name); err != nil {
+	if err := b.validateDelete(namespace, name); err != nil {
 		return err
 	}
 
@@ -739,14 +1186,36 @@ func (b *BoltQueueStore) Delete(_ context.Context, name string) error {
 			return f.Error("bucket does not exist in data file")
 		}
 
-		if err := bucket.Delete([]byte(name)); err != nil {
+		if err := bucket.Delete([]byte(types.QueueKey(namespace, name))); err != nil {
 			return f.Errorf("during Delete(%s): %w", name, err)
 		}
 		return nil
 	})
 }
 
+func (b *BoltQueueStore) Ping(_ context.Context) error {
+	f := errors.Fields{"category", "bolt", "func", "BoltQueueStore.Ping"}
+	db, err := b.getDB()
+	if err != nil {
+		return err
+	}
+
+	// Ensure the file is still readable
+	if err := db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(bucketName) == nil {
+			return errors.New("bucket does not exist")
+		}
+		return nil
+	}); err != nil {
+		return f.Errorf("while reading db: %w", err)
+	}
+	return nil
+}
+
 func (b *BoltQueueStore) Close(_ context.Context) error {
+	if b.db == nil {
+		return nil
+	}
 	err := b.db.Close()
 	b.db = nil
 	return err
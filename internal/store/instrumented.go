@@ -0,0 +1,133 @@
+package store
+
+import (
+	"context"
+	"github.com/kapetan-io/querator/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// InstrumentedPartition wraps a Partition such that every call to the underlying storage
+// is recorded as a child span of the span found in the context provided, and the latency
+// of each call is observed by the latency observer labeled with the method name.
+type InstrumentedPartition struct {
+	partition Partition
+	tracer    trace.Tracer
+	latency   prometheus.ObserverVec
+	attrs     []attribute.KeyValue
+}
+
+var _ Partition = &InstrumentedPartition{}
+
+func NewInstrumentedPartition(p Partition, info types.PartitionInfo, tracer trace.Tracer,
+	latency prometheus.ObserverVec) *InstrumentedPartition {
+	return &InstrumentedPartition{
+		partition: p,
+		tracer:    tracer,
+		latency:   latency,
+		attrs: []attribute.KeyValue{
+			attribute.String("querator.namespace", info.Namespace),
+			attribute.String("querator.queue", info.QueueName),
+			attribute.Int("querator.partition", info.Partition),
+			attribute.String("querator.storage", info.StorageName),
+		},
+	}
+}
+
+func (p *InstrumentedPartition) Produce(ctx context.Context, batch types.Batch[types.ProduceRequest]) error {
+	ctx, span := p.start(ctx, "Partition.Produce")
+	defer p.observe("Produce", time.Now())
+	return p.end(span, p.partition.Produce(ctx, batch))
+}
+
+func (p *InstrumentedPartition) Reserve(ctx context.Context, batch *types.ReserveBatch, opts ReserveOptions) error {
+	ctx, span := p.start(ctx, "Partition.Reserve")
+	defer p.observe("Reserve", time.Now())
+	return p.end(span, p.partition.Reserve(ctx, batch, opts))
+}
+
+func (p *InstrumentedPartition) Peek(ctx context.Context, items *[]*types.Item, opts PeekOptions) error {
+	ctx, span := p.start(ctx, "Partition.Peek")
+	defer p.observe("Peek", time.Now())
+	return p.end(span, p.partition.Peek(ctx, items, opts))
+}
+
+func (p *InstrumentedPartition) Get(ctx context.Context, ids []types.ItemID, items *[]*types.Item) error {
+	ctx, span := p.start(ctx, "Partition.Get")
+	defer p.observe("Get", time.Now())
+	return p.end(span, p.partition.Get(ctx, ids, items))
+}
+
+func (p *InstrumentedPartition) Complete(ctx context.Context, batch types.Batch[types.CompleteRequest]) error {
+	ctx, span := p.start(ctx, "Partition.Complete")
+	defer p.observe("Complete", time.Now())
+	return p.end(span, p.partition.Complete(ctx, batch))
+}
+
+func (p *InstrumentedPartition) Extend(ctx context.Context, batch types.Batch[types.ExtendRequest]) error {
+	ctx, span := p.start(ctx, "Partition.Extend")
+	defer p.observe("Extend", time.Now())
+	return p.end(span, p.partition.Extend(ctx, batch))
+}
+
+func (p *InstrumentedPartition) Release(ctx context.Context, batch types.Batch[types.ReleaseRequest]) error {
+	ctx, span := p.start(ctx, "Partition.Release")
+	defer p.observe("Release", time.Now())
+	return p.end(span, p.partition.Release(ctx, batch))
+}
+
+func (p *InstrumentedPartition) List(ctx context.Context, items *[]*types.Item, opts types.ListOptions) error {
+	ctx, span := p.start(ctx, "Partition.List")
+	defer p.observe("List", time.Now())
+	return p.end(span, p.partition.List(ctx, items, opts))
+}
+
+func (p *InstrumentedPartition) Add(ctx context.Context, items []*types.Item) error {
+	ctx, span := p.start(ctx, "Partition.Add")
+	defer p.observe("Add", time.Now())
+	return p.end(span, p.partition.Add(ctx, items))
+}
+
+func (p *InstrumentedPartition) Delete(ctx context.Context, ids []types.ItemID) error {
+	ctx, span := p.start(ctx, "Partition.Delete")
+	defer p.observe("Delete", time.Now())
+	return p.end(span, p.partition.Delete(ctx, ids))
+}
+
+func (p *InstrumentedPartition) Clear(ctx context.Context, destructive bool) error {
+	ctx, span := p.start(ctx, "Partition.Clear")
+	defer p.observe("Clear", time.Now())
+	return p.end(span, p.partition.Clear(ctx, destructive))
+}
+
+func (p *InstrumentedPartition) Stats(ctx context.Context, stats *types.QueueStats) error {
+	ctx, span := p.start(ctx, "Partition.Stats")
+	defer p.observe("Stats", time.Now())
+	return p.end(span, p.partition.Stats(ctx, stats))
+}
+
+func (p *InstrumentedPartition) Close(ctx context.Context) error {
+	ctx, span := p.start(ctx, "Partition.Close")
+	defer p.observe("Close", time.Now())
+	return p.end(span, p.partition.Close(ctx))
+}
+
+func (p *InstrumentedPartition) start(ctx context.Context, name string) (context.Context, trace.Span) {
+	return p.tracer.Start(ctx, name, trace.WithAttributes(p.attrs...))
+}
+
+func (p *InstrumentedPartition) observe(method string, start time.Time) {
+	p.latency.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+func (p *InstrumentedPartition) end(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+	return err
+}
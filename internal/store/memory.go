@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"github.com/kapetan-io/querator/internal/types"
+	pb "github.com/kapetan-io/querator/proto"
 	"github.com/kapetan-io/querator/transport"
 	"github.com/kapetan-io/tackle/clock"
 	"github.com/segmentio/ksuid"
@@ -18,33 +19,75 @@ type MemoryPartition struct {
 	conf StorageConfig
 	mem  []types.Item
 	uid  ksuid.KSUID
+	// scheduler decides which priority level the next reserved item is taken from
+	scheduler priorityScheduler
+	// dedup is the DedupDeadline of each DedupID produced, dedupOrder holds the same entries
+	// in the order they were produced such that expired entries can be removed.
+	dedup      map[string]clock.Time
+	dedupOrder []dedupEntry
+	// expired is the number of expired items removed since the partition was opened
+	expired int
+	// stored is the number of items of each priority level in mem
+	stored priorityCounts
+}
+
+type dedupEntry struct {
+	ID       string
+	Deadline clock.Time
 }
 
 func (q *MemoryPartition) Produce(_ context.Context, batch types.Batch[types.ProduceRequest]) error {
+	now := q.conf.Clock.Now().UTC()
+	q.expireDedup(now)
+
 	for _, r := range batch.Requests {
+		items := r.Items[:0]
 		for _, item := range r.Items {
+			if item.DedupID != "" {
+				if deadline, ok := q.dedup[item.DedupID]; ok && now.Before(deadline) {
+					continue
+				}
+				q.dedup[item.DedupID] = item.DedupDeadline
+				q.dedupOrder = append(q.dedupOrder, dedupEntry{ID: item.DedupID, Deadline: item.DedupDeadline})
+			}
+
 			q.uid = q.uid.Next()
 			item.ID = []byte(q.uid.String())
-			item.CreatedAt = q.conf.Clock.Now().UTC()
+			item.CreatedAt = now
 
 			q.mem = append(q.mem, *item)
+			q.stored[item.Priority]++
+			items = append(items, item)
 		}
+		r.Items = items
 	}
 	return nil
 }
 
-func (q *MemoryPartition) Reserve(_ context.Context, batch types.ReserveBatch, opts ReserveOptions) error {
-	batchIter := batch.Iterator()
-	var count int
-
-	for i, item := range q.mem {
-		if item.IsReserved {
-			continue
+// expireDedup removes the dedup ids whose DedupDeadline has passed
+func (q *MemoryPartition) expireDedup(now clock.Time) {
+	var i int
+	for ; i < len(q.dedupOrder); i++ {
+		entry := q.dedupOrder[i]
+		if now.Before(entry.Deadline) {
+			break
 		}
+		// The id might have been produced again after it expired
+		if deadline, ok := q.dedup[entry.ID]; ok && !now.Before(deadline) {
+			delete(q.dedup, entry.ID)
+		}
+	}
+	q.dedupOrder = q.dedupOrder[i:]
+}
 
-		item.ReserveDeadline = opts.ReserveDeadline
+func (q *MemoryPartition) Reserve(_ context.Context, batch *types.ReserveBatch, opts ReserveOptions) error {
+	batchIter := batch.Iterator()
+
+	reservable, expired := q.reservable(batch.Total, opts.PriorityWeights, &q.scheduler)
+	for _, i := range reservable {
+		item := q.mem[i]
+		item.ReserveDeadline = opts.Deadline(&item)
 		item.IsReserved = true
-		count++
 
 		// Item returned gets the public StorageID
 		itemPtr := new(types.Item) // TODO: Memory Pool
@@ -59,12 +102,134 @@ func (q *MemoryPartition) Reserve(_ context.Context, batch types.ReserveBatch, o
 		}
 		break
 	}
+
+	// Remove the expired items found, in reverse order such that the remaining indexes are not shifted
+	for i := len(expired) - 1; i >= 0; i-- {
+		q.remove(expired[i])
+	}
+	q.expired += len(expired)
+	batch.Expired = len(expired)
+	return nil
+}
+
+func (q *MemoryPartition) Peek(_ context.Context, items *[]*types.Item, opts PeekOptions) error {
+	// Use a copy of the scheduler such that peeking does not change the order of future reservations
+	scheduler := q.scheduler
+	reservable, _ := q.reservable(opts.Limit, opts.PriorityWeights, &scheduler)
+	for _, i := range reservable {
+		item := q.mem[i]
+		*items = append(*items, &item)
+	}
+	return nil
+}
+
+// reservable returns the index of up to limit items in the order they should be reserved, and the
+// index of any unreserved items found to have expired in the order they appear in the partition.
+func (q *MemoryPartition) reservable(limit int, weights []int, scheduler *priorityScheduler) ([]int, []int) {
+	now := q.conf.Clock.Now().UTC()
+
+	// Find the oldest unreserved items of each priority level
+	var levels [types.NumPriorities][]int
+	var found, seen priorityCounts
+	var expired []int
+	keys := make(orderingKeys)
+	for i := 0; i < len(q.mem) && !scanDone(&found, &seen, &q.stored, limit); i++ {
+		seen[q.mem[i].Priority]++
+		if !q.mem[i].IsReserved && q.mem[i].IsExpired(now) {
+			expired = append(expired, i)
+			continue
+		}
+		if !keys.first(&q.mem[i]) || q.mem[i].IsReserved {
+			continue
+		}
+		if p := q.mem[i].Priority; found[p] < limit {
+			levels[p] = append(levels[p], i)
+			found[p]++
+		}
+	}
+
+	results := make([]int, 0, limit)
+	for len(results) < limit {
+		level := scheduler.next(weights, func(l int) bool { return len(levels[l]) != 0 })
+		if level == -1 {
+			break
+		}
+		results = append(results, levels[level][0])
+		levels[level] = levels[level][1:]
+	}
+	return results, expired
+}
+
+func (q *MemoryPartition) Get(_ context.Context, ids []types.ItemID, items *[]*types.Item) error {
+	for _, id := range ids {
+		if err := q.validateID(id); err != nil {
+			return transport.NewInvalidOption("invalid storage id; '%s': %s", id, err)
+		}
+
+		idx, ok := q.findID(id)
+		if !ok {
+			return transport.NewInvalidOption("invalid storage id; '%s' does not exist", id)
+		}
+		item := q.mem[idx]
+		*items = append(*items, &item)
+	}
 	return nil
 }
 
 func (q *MemoryPartition) Complete(_ context.Context, batch types.Batch[types.CompleteRequest]) error {
 nextBatch:
 	for i := range batch.Requests {
+		req := batch.Requests[i]
+		if req.Detailed {
+			// Complete each id independently, recording the outcome of each
+			for _, id := range req.Ids {
+				idx, result := q.completable(id)
+				if result.Status == pb.CompleteStatus_COMPLETE_OK {
+					q.remove(idx)
+				}
+				req.Results = append(req.Results, result)
+			}
+			continue
+		}
+
+		// Validate all the ids before completing any of them
+		for _, id := range req.Ids {
+			if _, result := q.completable(id); result.Status != pb.CompleteStatus_COMPLETE_OK {
+				req.Err = completeError(result)
+				continue nextBatch
+			}
+		}
+		for _, id := range req.Ids {
+			// Remove the item from the array, ignoring ids repeated in the same request
+			if idx, ok := q.findID(id); ok {
+				q.remove(idx)
+			}
+		}
+	}
+	return nil
+}
+
+// completable returns the index of the item and the result of completing the item
+func (q *MemoryPartition) completable(id types.ItemID) (int, types.CompleteResult) {
+	if err := q.validateID(id); err != nil {
+		return 0, validateComplete(id, err, nil)
+	}
+
+	idx, ok := q.findID(id)
+	if !ok {
+		return 0, validateComplete(id, nil, nil)
+	}
+	return idx, validateComplete(id, nil, &q.mem[idx])
+}
+
+func (q *MemoryPartition) Extend(_ context.Context, batch types.Batch[types.ExtendRequest]) error {
+	now := q.conf.Clock.Now().UTC()
+	indexes := make([]int, 0, 10)
+
+nextBatch:
+	for i := range batch.Requests {
+		// Validate all the ids before extending any of them
+		indexes = indexes[:0]
 		for _, id := range batch.Requests[i].Ids {
 			if err := q.validateID(id); err != nil {
 				batch.Requests[i].Err = transport.NewInvalidOption("invalid storage id; '%s': %s", id, err)
@@ -77,13 +242,51 @@ nextBatch:
 				continue nextBatch
 			}
 
-			if !q.mem[idx].IsReserved {
-				batch.Requests[i].Err = transport.NewConflict("item(s) cannot be completed; '%s' is not "+
-					"marked as reserved", id)
+			if err := validateReservation(&q.mem[idx], now, "extended"); err != nil {
+				batch.Requests[i].Err = err
 				continue nextBatch
 			}
-			// Remove the item from the array
-			q.mem = append(q.mem[:idx], q.mem[idx+1:]...)
+			indexes = append(indexes, idx)
+		}
+
+		batch.Requests[i].ReserveDeadlines = make([]clock.Time, 0, len(indexes))
+		for _, idx := range indexes {
+			q.mem[idx].ReserveDeadline = batch.Requests[i].Deadline(&q.mem[idx])
+			batch.Requests[i].ReserveDeadlines = append(batch.Requests[i].ReserveDeadlines, q.mem[idx].ReserveDeadline)
+		}
+	}
+	return nil
+}
+
+func (q *MemoryPartition) Release(_ context.Context, batch types.Batch[types.ReleaseRequest]) error {
+	now := q.conf.Clock.Now().UTC()
+	indexes := make([]int, 0, 10)
+
+nextBatch:
+	for i := range batch.Requests {
+		// Validate all the ids before releasing any of them
+		indexes = indexes[:0]
+		for _, id := range batch.Requests[i].Ids {
+			if err := q.validateID(id); err != nil {
+				batch.Requests[i].Err = transport.NewInvalidOption("invalid storage id; '%s': %s", id, err)
+				continue nextBatch
+			}
+
+			idx, ok := q.findID(id)
+			if !ok {
+				batch.Requests[i].Err = transport.NewInvalidOption("invalid storage id; '%s' does not exist", id)
+				continue nextBatch
+			}
+
+			if err := validateReservation(&q.mem[idx], now, "released"); err != nil {
+				batch.Requests[i].Err = err
+				continue nextBatch
+			}
+			indexes = append(indexes, idx)
+		}
+
+		for _, idx := range indexes {
+			release(&q.mem[idx], batch.Requests[i].SkipAttempt)
 		}
 	}
 	return nil
@@ -123,6 +326,7 @@ func (q *MemoryPartition) Add(_ context.Context, items []*types.Item) error {
 		item.CreatedAt = q.conf.Clock.Now().UTC()
 
 		q.mem = append(q.mem, *item)
+		q.stored[item.Priority]++
 	}
 	return nil
 }
@@ -138,8 +342,7 @@ func (q *MemoryPartition) Delete(_ context.Context, ids []types.ItemID) error {
 			continue
 		}
 
-		// Remove the item from the array
-		q.mem = append(q.mem[:idx], q.mem[idx+1:]...)
+		q.remove(idx)
 	}
 	return nil
 }
@@ -147,13 +350,18 @@ func (q *MemoryPartition) Delete(_ context.Context, ids []types.ItemID) error {
 func (q *MemoryPartition) Clear(_ context.Context, destructive bool) error {
 	if destructive {
 		q.mem = make([]types.Item, 0, 1_000)
+		q.stored = priorityCounts{}
+		q.dedup = make(map[string]clock.Time)
+		q.dedupOrder = nil
 		return nil
 	}
 
 	mem := make([]types.Item, 0, len(q.mem))
+	q.stored = priorityCounts{}
 	for _, item := range q.mem {
 		if item.IsReserved {
 			mem = append(mem, item)
+			q.stored[item.Priority]++
 			continue
 		}
 	}
@@ -163,12 +371,19 @@ func (q *MemoryPartition) Clear(_ context.Context, destructive bool) error {
 
 func (q *MemoryPartition) Stats(_ context.Context, stats *types.QueueStats) error {
 	now := q.conf.Clock.Now().UTC()
+	stats.TotalExpired += q.expired
 	for _, item := range q.mem {
 		stats.Total++
+		stats.TotalBytes += int64(len(item.Payload))
 		stats.AverageAge += now.Sub(item.CreatedAt)
+		if age := now.Sub(item.CreatedAt); age > stats.OldestAge {
+			stats.OldestAge = age
+		}
 		if item.IsReserved {
 			stats.AverageReservedAge += item.ReserveDeadline.Sub(now)
 			stats.TotalReserved++
+		} else if item.IsExpired(now) {
+			stats.TotalExpired++
 		}
 	}
 	if stats.Total != 0 {
@@ -182,9 +397,16 @@ func (q *MemoryPartition) Stats(_ context.Context, stats *types.QueueStats) erro
 
 func (q *MemoryPartition) Close(_ context.Context) error {
 	q.mem = nil
+	q.stored = priorityCounts{}
 	return nil
 }
 
+// remove removes the item at the index provided from mem
+func (q *MemoryPartition) remove(idx int) {
+	q.stored[q.mem[idx].Priority]--
+	q.mem = append(q.mem[:idx], q.mem[idx+1:]...)
+}
+
 // findID attempts to find the provided id in q.mem. If found returns the index and true.
 // If not found returns the next nearest item in the list.
 func (q *MemoryPartition) findID(id []byte) (int, bool) {
@@ -219,12 +441,12 @@ func NewMemoryQueueStore() *MemoryQueueStore {
 	}
 }
 
-func (s *MemoryQueueStore) Get(_ context.Context, name string, queue *types.QueueInfo) error {
-	if err := s.validateGet(name); err != nil {
+func (s *MemoryQueueStore) Get(_ context.Context, namespace, name string, queue *types.QueueInfo) error {
+	if err := s.validateGet(namespace, name); err != nil {
 		return err
 	}
 
-	idx, ok := s.findQueue(name)
+	idx, ok := s.findQueue(namespace, name)
 	if !ok {
 		return ErrQueueNotExist
 	}
@@ -237,7 +459,7 @@ func (s *MemoryQueueStore) Add(_ context.Context, info types.QueueInfo) error {
 		return err
 	}
 
-	_, ok := s.findQueue(info.Name)
+	_, ok := s.findQueue(info.Namespace, info.Name)
 	if ok {
 		return transport.NewInvalidOption("invalid queue; '%s' already exists", info.Name)
 	}
@@ -251,7 +473,7 @@ func (s *MemoryQueueStore) Update(_ context.Context, info types.QueueInfo) error
 		return err
 	}
 
-	idx, ok := s.findQueue(info.Name)
+	idx, ok := s.findQueue(info.Namespace, info.Name)
 	if !ok {
 		return ErrQueueNotExist
 	}
@@ -279,32 +501,36 @@ func (s *MemoryQueueStore) Update(_ context.Context, info types.QueueInfo) error
 
 }
 
-func (s *MemoryQueueStore) List(_ context.Context, queues *[]types.QueueInfo, opts types.ListOptions) error {
-	if err := s.validateList(opts); err != nil {
+func (s *MemoryQueueStore) List(_ context.Context, namespace string, queues *[]types.QueueInfo,
+	opts types.ListOptions) error {
+	if err := s.validateList(namespace, opts); err != nil {
 		return err
 	}
 
 	var count, idx int
 	if opts.Pivot != nil {
-		idx, _ = s.findQueue(string(opts.Pivot))
+		idx, _ = s.findQueue(namespace, string(opts.Pivot))
 	}
 
 	for _, info := range s.mem[idx:] {
 		if count >= opts.Limit {
 			return nil
 		}
+		if info.Namespace != namespace {
+			continue
+		}
 		*queues = append(*queues, info)
 		count++
 	}
 	return nil
 }
 
-func (s *MemoryQueueStore) Delete(_ context.Context, name string) error {
-	if err := s.validateDelete(name); err != nil {
+func (s *MemoryQueueStore) Delete(_ context.Context, namespace, name string) error {
+	if err := s.validateDelete(namespace, name); err != nil {
 		return err
 	}
 
-	idx, ok := s.findQueue(name)
+	idx, ok := s.findQueue(namespace, name)
 	if !ok {
 		return nil
 	}
@@ -312,6 +538,10 @@ func (s *MemoryQueueStore) Delete(_ context.Context, name string) error {
 	return nil
 }
 
+func (s *MemoryQueueStore) Ping(_ context.Context) error {
+	return nil
+}
+
 func (s *MemoryQueueStore) Close(_ context.Context) error {
 	s.mem = nil
 	return nil
@@ -319,10 +549,10 @@ func (s *MemoryQueueStore) Close(_ context.Context) error {
 
 // findID attempts to find the provided queue in q.mem. If found returns the index and true.
 // If not found returns the next nearest item in the list.
-func (s *MemoryQueueStore) findQueue(name string) (int, bool) {
+func (s *MemoryQueueStore) findQueue(namespace, name string) (int, bool) {
 	var nearest, nearestIdx int
 	for i, queue := range s.mem {
-		lex := strings.Compare(queue.Name, name)
+		lex := strings.Compare(queue.Key(), types.QueueKey(namespace, name))
 		if lex == 0 {
 			return i, true
 		}
@@ -354,11 +584,16 @@ func (m MemoryPartitionStore) Create(info types.PartitionInfo) error {
 	return nil
 }
 
+func (m MemoryPartitionStore) Ping(_ context.Context) error {
+	return nil
+}
+
 func (m MemoryPartitionStore) Get(info types.PartitionInfo) Partition {
 	return &MemoryPartition{
-		mem:  make([]types.Item, 0, 1_000),
-		uid:  ksuid.New(),
-		conf: m.conf,
+		mem:   make([]types.Item, 0, 1_000),
+		dedup: make(map[string]clock.Time),
+		uid:   ksuid.New(),
+		conf:  m.conf,
 	}
 
 }
@@ -0,0 +1,84 @@
+package store
+
+import "github.com/kapetan-io/querator/internal/types"
+
+// priorityScheduler decides which priority level the next reserved item is taken from. The scheduler
+// uses a smooth weighted round-robin, such that when items of several priorities are waiting, each
+// priority is chosen in proportion to its weight and in an interleaved fashion. The state of the
+// round-robin is kept between calls to Reserve() so that lower priorities are not starved when
+// reservations are small. See doc/adr/0014-ordered-storage.md
+type priorityScheduler struct {
+	current [types.NumPriorities]int
+}
+
+// next returns the priority level the next item should be reserved from, or -1 if none of the levels
+// have items available. If weights is empty, the highest priority level with items available is chosen.
+func (s *priorityScheduler) next(weights []int, available func(level int) bool) int {
+	if len(weights) != types.NumPriorities {
+		for level := types.NumPriorities - 1; level >= 0; level-- {
+			if available(level) {
+				return level
+			}
+		}
+		return -1
+	}
+
+	chosen, total := -1, 0
+	// Ties are won by the higher priority level
+	for level := types.NumPriorities - 1; level >= 0; level-- {
+		if !available(level) {
+			continue
+		}
+		s.current[level] += weights[level]
+		total += weights[level]
+		if chosen == -1 || s.current[level] > s.current[chosen] {
+			chosen = level
+		}
+	}
+	if chosen != -1 {
+		s.current[chosen] -= total
+	}
+	return chosen
+}
+
+// orderingKeys tracks the ordering keys seen while scanning the items of a partition in the order
+// they were produced. Only the first item of each ordering key can be reserved, any item which follows
+// must wait until the first item is completed, regardless of whether the first item is reserved.
+type orderingKeys map[string]struct{}
+
+// first returns true if the item has no ordering key, or is the first item seen with its ordering key
+func (k orderingKeys) first(item *types.Item) bool {
+	if item.OrderingKey == "" {
+		return true
+	}
+	if _, ok := k[item.OrderingKey]; ok {
+		return false
+	}
+	k[item.OrderingKey] = struct{}{}
+	return true
+}
+
+// priorityCounts is the number of items of each priority level
+type priorityCounts [types.NumPriorities]int
+
+// scanDone returns true once the scan for reservable items no longer needs to visit any more items. That
+// is, for every priority level, either limit items have been found, or every item of the level stored
+// in the partition has been seen. Since most queues only use a single priority level, this allows the
+// scan to stop as soon as limit items are found instead of visiting every item in the partition.
+func scanDone(found, seen, stored *priorityCounts, limit int) bool {
+	for level := range stored {
+		if found[level] < limit && seen[level] < stored[level] {
+			return false
+		}
+	}
+	return true
+}
+
+// sum returns the total number of items of all priority levels
+func sum(counts priorityCounts) int {
+	var total int
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
@@ -24,25 +24,50 @@ func (id StorageID) String() string {
 type ReserveOptions struct {
 	// ReserveDeadline is a time in the future when the reservation should expire
 	ReserveDeadline clock.Time
+	// ReservedAt is the time the reservation was made, items produced with a ReserveTimeout
+	// expire at ReservedAt + Item.ReserveTimeout instead of ReserveDeadline
+	ReservedAt clock.Time
+	// PriorityWeights is the relative weight of each priority level. If empty, items with a
+	// higher priority are always reserved before items with a lower priority.
+	PriorityWeights []int
 }
 
-// QueueStore is storage for listing and storing information about queues
+// Deadline returns the time the reservation of the item provided should expire
+func (o ReserveOptions) Deadline(item *types.Item) clock.Time {
+	if item.ReserveTimeout != 0 {
+		return o.ReservedAt.Add(item.ReserveTimeout)
+	}
+	return o.ReserveDeadline
+}
+
+type PeekOptions struct {
+	// Limit is the maximum number of items to return
+	Limit int
+	// PriorityWeights is the relative weight of each priority level, see ReserveOptions
+	PriorityWeights []int
+}
+
+// QueueStore is storage for listing and storing information about queues. Queue names are unique
+// within a namespace, the empty namespace "" is the default namespace.
 type QueueStore interface {
 	// Get returns a store.Partition from storage ready to be used. Returns ErrQueueNotExist if the
 	// queue requested does not exist
-	Get(ctx context.Context, name string, queue *types.QueueInfo) error
+	Get(ctx context.Context, namespace, name string, queue *types.QueueInfo) error
 
-	// Add a queue in the store. if the queue already exists returns an error
+	// Add a queue in the store. if the queue already exists in QueueInfo.Namespace returns an error
 	Add(ctx context.Context, info types.QueueInfo) error
 
 	// Update a queue in the store if the queue already exists it updates the existing QueueInfo
 	Update(ctx context.Context, info types.QueueInfo) error
 
-	// List returns a list of queues
-	List(ctx context.Context, queues *[]types.QueueInfo, opts types.ListOptions) error
+	// List returns a list of queues within the namespace provided
+	List(ctx context.Context, namespace string, queues *[]types.QueueInfo, opts types.ListOptions) error
 
 	// Delete deletes a queue. Returns without error if the queue does not exist
-	Delete(ctx context.Context, queueName string) error
+	Delete(ctx context.Context, namespace, queueName string) error
+
+	// Ping verifies the store is reachable and able to service requests
+	Ping(ctx context.Context) error
 
 	// Close the all open database connections or files
 	Close(ctx context.Context) error
@@ -52,17 +77,41 @@ type QueueStore interface {
 // thread safe as it is intended to be used by a Logical Queue only.
 type Partition interface {
 	// Produce writes the items for each batch to the data store, assigning an error for each
-	// batch that fails.
+	// batch that fails. Items with a DedupID which was produced before the DedupDeadline of the
+	// previously produced item are removed from the request and not written to the data store.
 	Produce(ctx context.Context, batch types.Batch[types.ProduceRequest]) error
 
-	// Reserve attempts to reserve items for each request in the provided batch.
-	Reserve(ctx context.Context, batch types.ReserveBatch, opts ReserveOptions) error
+	// Reserve attempts to reserve items for each request in the provided batch. An item with an
+	// OrderingKey is only reserved if no item with the same OrderingKey produced before it is reserved.
+	// Unreserved items found to have passed their ExpireDeadline are never reserved and are removed,
+	// the number of items removed is reported in ReserveBatch.Expired.
+	Reserve(ctx context.Context, batch *types.ReserveBatch, opts ReserveOptions) error
+
+	// Peek returns up to PeekOptions.Limit items in the order they would be reserved by Reserve(), without
+	// reserving the items or changing the order of future reservations.
+	Peek(ctx context.Context, items *[]*types.Item, opts PeekOptions) error
+
+	// Get returns the items for the ids provided in the same order as the ids. Returns an error
+	// if any of the ids do not exist.
+	Get(ctx context.Context, ids []types.ItemID, items *[]*types.Item) error
 
 	// Complete marks ids in the batch as complete, assigning an error for each batch that fails.
 	// If the underlying data storage fails for some reason, this call returns an error. In that case
 	// the caller should assume none of the batched items were marked as "complete"
 	Complete(ctx context.Context, batch types.Batch[types.CompleteRequest]) error
 
+	// Extend moves the ReserveDeadline of the reserved ids in the batch to ExtendRequest.Deadline(),
+	// recording the new deadline of each id in ExtendRequest.ReserveDeadlines and assigning an error
+	// for each batch that fails. Ids in a batch are extended all or nothing. Items
+	// whose reservation has already expired cannot be extended, as they are eligible to be reserved
+	// by another consumer.
+	Extend(ctx context.Context, batch types.Batch[types.ExtendRequest]) error
+
+	// Release marks the reserved ids in the batch as no longer reserved, such that they can be reserved
+	// again without waiting for the ReserveDeadline. Released items keep their position in the queue.
+	// Ids in a batch are released all or nothing, assigning an error for each batch that fails.
+	Release(ctx context.Context, batch types.Batch[types.ReleaseRequest]) error
+
 	// List lists items in a queue. limit and offset allow the user to page through all the items
 	// in the queue.
 	List(ctx context.Context, items *[]*types.Item, opts types.ListOptions) error
@@ -100,6 +149,12 @@ type Backend struct {
 	ScheduledStore ScheduledStore
 	Affinity       float64
 	Name           string
+	// Settings describes the configuration of the PartitionStore (IE: the driver and storage directory),
+	// such that a change to the settings of a backend can be detected when the config is reloaded.
+	Settings string
+	// NewPartitionStore if provided, is used to create the PartitionStore when PartitionStore is nil. This
+	// allows a reloaded config to describe backends without creating stores for backends which already exist.
+	NewPartitionStore func() PartitionStore
 }
 
 // PartitionStore manages the partitions
@@ -109,6 +164,8 @@ type PartitionStore interface {
 	// Get assumes the partition exists and returns a new Partition instance for the requested partition.
 	// returns an error if the partition requested does not exist.
 	Get(types.PartitionInfo) Partition
+	// Ping verifies the store is reachable and able to create and access partitions
+	Ping(ctx context.Context) error
 }
 
 // TODO: A scheduled store should probably be located or managed by a partition store, possibly in the same table
@@ -2,8 +2,11 @@ package store
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/kapetan-io/querator/internal/types"
+	pb "github.com/kapetan-io/querator/proto"
 	"github.com/kapetan-io/querator/transport"
+	"github.com/kapetan-io/tackle/clock"
 	"strings"
 )
 
@@ -15,7 +18,22 @@ const maxInt16 = 65536 // 1<<16
 
 type QueuesValidation struct{}
 
-func (s QueuesValidation) validateGet(name string) error {
+func (s QueuesValidation) validateNamespace(namespace string) error {
+	if len(namespace) > maxQueueNameLength {
+		return transport.NewInvalidOption("namespace is invalid; cannot be greater than '%d' characters", maxQueueNameLength)
+	}
+
+	if strings.Contains(namespace, "~") {
+		return transport.NewInvalidOption("namespace is invalid; '%s' cannot contain '~' character", namespace)
+	}
+	return nil
+}
+
+func (s QueuesValidation) validateGet(namespace, name string) error {
+	if err := s.validateNamespace(namespace); err != nil {
+		return err
+	}
+
 	if strings.TrimSpace(name) == "" {
 		return ErrEmptyQueueName
 	}
@@ -27,6 +45,10 @@ func (s QueuesValidation) validateGet(name string) error {
 	return nil
 }
 func (s QueuesValidation) validateQueueName(info types.QueueInfo) error {
+	if err := s.validateNamespace(info.Namespace); err != nil {
+		return err
+	}
+
 	if len(info.Name) > maxQueueNameLength {
 		return transport.NewInvalidOption("queue name is invalid; cannot be greater than '%d' characters", maxQueueNameLength)
 	}
@@ -66,6 +88,14 @@ func (s QueuesValidation) validateQueueInfo(info types.QueueInfo) error {
 		return transport.NewInvalidOption("max attempts is invalid; cannot be negative number")
 	}
 
+	if info.DedupWindow < 0 {
+		return transport.NewInvalidOption("dedup window is invalid; cannot be negative duration")
+	}
+
+	if info.ExpireTimeout < 0 {
+		return transport.NewInvalidOption("expire timeout is invalid; cannot be negative duration")
+	}
+
 	// TODO: Add this check to the errors test
 	if info.Partitions < 1 {
 		return transport.NewInvalidOption("partitions is invalid; cannot be less than 1")
@@ -99,7 +129,10 @@ func (s QueuesValidation) validateUpdate(info types.QueueInfo) error {
 	return s.validateQueueInfo(info)
 }
 
-func (s QueuesValidation) validateList(opts types.ListOptions) error {
+func (s QueuesValidation) validateList(namespace string, opts types.ListOptions) error {
+	if err := s.validateNamespace(namespace); err != nil {
+		return err
+	}
 
 	if opts.Limit < 0 {
 		return transport.NewInvalidOption("limit is invalid; limit cannot be negative")
@@ -116,7 +149,11 @@ func (s QueuesValidation) validateList(opts types.ListOptions) error {
 	return nil
 }
 
-func (s QueuesValidation) validateDelete(name string) error {
+func (s QueuesValidation) validateDelete(namespace, name string) error {
+	if err := s.validateNamespace(namespace); err != nil {
+		return err
+	}
+
 	if len(name) > maxQueueNameLength {
 		return transport.NewInvalidOption("queue name is invalid; cannot be greater than '%d' characters", maxQueueNameLength)
 	}
@@ -130,3 +167,53 @@ func (s QueuesValidation) validateDelete(name string) error {
 	}
 	return nil
 }
+
+// validateReservation returns an error if the item provided is not held by an unexpired reservation.
+// The action is the operation attempted on the reservation, IE: 'extended' or 'released'
+func validateReservation(item *types.Item, now clock.Time, action string) error {
+	if !item.IsReserved {
+		return transport.NewConflict("item(s) cannot be %s; '%s' is not marked as reserved", action, item.ID)
+	}
+	// Once the reservation has expired, the item may be offered to another consumer
+	if !now.Before(item.ReserveDeadline) {
+		return transport.NewConflict("item(s) cannot be %s; '%s' reservation has expired", action, item.ID)
+	}
+	return nil
+}
+
+// validateComplete returns the result of completing the item provided. The validErr is the result of
+// validating the id and item is nil if the id does not exist.
+func validateComplete(id types.ItemID, validErr error, item *types.Item) types.CompleteResult {
+	switch {
+	case validErr != nil:
+		return types.CompleteResult{ID: id, Status: pb.CompleteStatus_COMPLETE_INVALID_ID,
+			Message: fmt.Sprintf("invalid storage id; '%s': %s", id, validErr)}
+	case item == nil:
+		return types.CompleteResult{ID: id, Status: pb.CompleteStatus_COMPLETE_NOT_FOUND,
+			Message: fmt.Sprintf("invalid storage id; '%s' does not exist", id)}
+	case !item.IsReserved:
+		return types.CompleteResult{ID: id, Status: pb.CompleteStatus_COMPLETE_NOT_RESERVED,
+			Message: fmt.Sprintf("item(s) cannot be completed; '%s' is not marked as reserved", id)}
+	}
+	return types.CompleteResult{ID: id, Status: pb.CompleteStatus_COMPLETE_OK}
+}
+
+// completeError returns the error returned to the client if the result is not COMPLETE_OK
+func completeError(result types.CompleteResult) error {
+	switch result.Status {
+	case pb.CompleteStatus_COMPLETE_OK:
+		return nil
+	case pb.CompleteStatus_COMPLETE_NOT_RESERVED:
+		return transport.NewConflict("%s", result.Message)
+	}
+	return transport.NewInvalidOption("%s", result.Message)
+}
+
+// release clears the reservation of the item, counting the release as an attempt unless skipAttempt is true
+func release(item *types.Item, skipAttempt bool) {
+	item.IsReserved = false
+	item.ReserveDeadline = clock.Time{}
+	if !skipAttempt {
+		item.Attempts++
+	}
+}
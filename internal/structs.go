@@ -10,6 +10,9 @@ type QueueState struct {
 	Reservations types.ReserveBatch
 	Producers    types.Batch[types.ProduceRequest]
 	Completes    types.Batch[types.CompleteRequest]
+	Extends      types.Batch[types.ExtendRequest]
+	Releases     types.Batch[types.ReleaseRequest]
+	Consumers    consumers
 
 	NextMaintenanceCh <-chan clock.Time
 }
@@ -37,6 +37,8 @@ func (r *Batch[T]) Reset() {
 type ReserveBatch struct {
 	Requests []*ReserveRequest
 	Total    int
+	// Expired is set by the partition to the number of expired items removed while reserving
+	Expired int
 }
 
 func (r *ReserveBatch) Add(req *ReserveRequest) {
@@ -106,7 +108,8 @@ func (it *ReserveBatchIterator) Next(item *Item) bool {
 			count--
 			continue
 		}
-		if len(it.b.Requests[it.pos].Items) == it.b.Requests[it.pos].NumRequested {
+		if len(it.b.Requests[it.pos].Items) == it.b.Requests[it.pos].NumRequested ||
+			!it.b.Requests[it.pos].fits(item) {
 			it.pos++
 			if it.pos == len(it.b.Requests) {
 				it.pos = 0
@@ -115,6 +118,7 @@ func (it *ReserveBatchIterator) Next(item *Item) bool {
 			continue
 		}
 		it.b.Requests[it.pos].Items = append(it.b.Requests[it.pos].Items, item)
+		it.b.Requests[it.pos].itemBytes += item.Size()
 		it.pos++
 		if it.pos == len(it.b.Requests) {
 			it.pos = 0
@@ -123,3 +127,11 @@ func (it *ReserveBatchIterator) Next(item *Item) bool {
 	}
 	return false
 }
+
+// fits returns true if the item can be added to the reservation without exceeding MaxBytes
+func (r *ReserveRequest) fits(item *Item) bool {
+	if r.MaxBytes == 0 || len(r.Items) == 0 {
+		return true
+	}
+	return r.itemBytes+item.Size() <= r.MaxBytes
+}
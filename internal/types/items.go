@@ -18,6 +18,10 @@ func ToItemID(id string) ItemID {
 	return ItemID(id)
 }
 
+// NumPriorities is the number of priority levels an item can be produced with. Priority zero
+// is the default and lowest priority, NumPriorities - 1 is the highest priority.
+const NumPriorities = 4
+
 // Item is the store and queue representation of an item in the queue.
 type Item struct {
 	// ID is unique to each item in the data store. The ID style is different depending on the data store
@@ -38,6 +42,30 @@ type Item struct {
 	// MaxAttempts is the maximum number of times this message can be deferred by a consumer before it is
 	// placed in the dead letter queue
 	MaxAttempts int
+	// ReserveTimeout if non-zero is used instead of the queue ReserveTimeout to calculate
+	// the ReserveDeadline when this item is reserved.
+	ReserveTimeout clock.Duration
+	// DeadTimeout if non-zero is used instead of the queue DeadTimeout to calculate
+	// the DeadDeadline when this item is produced.
+	DeadTimeout clock.Duration
+	// ExpireTimeout if non-zero is used instead of the queue ExpireTimeout to calculate
+	// the ExpireDeadline when this item is produced.
+	ExpireTimeout clock.Duration
+	// ExpireDeadline is the time after which the item is no longer useful. Expired items are never
+	// reserved and are removed from the queue without being moved to the dead letter queue. If zero,
+	// the item does not expire.
+	ExpireDeadline clock.Time
+	// DedupID is a producer supplied id used to detect items produced more than once
+	DedupID string
+	// DedupDeadline is the time after which an item produced with the same DedupID
+	// is no longer considered a duplicate of this item.
+	DedupDeadline clock.Time
+	// Priority is the priority level of the item, items with a higher priority are
+	// reserved before items with a lower priority.
+	Priority int
+	// OrderingKey is a producer supplied key, items with the same key are reserved one at a time
+	// in the order they were produced.
+	OrderingKey string
 	// Reference is a user supplied field which could contain metadata or specify who owns this queue
 	// Examples: "jake@statefarm.com", "stapler@office-space.com", "account-0001"
 	Reference string
@@ -91,6 +119,15 @@ func (i *Item) ToProto(in *pb.StorageQueueItem) *pb.StorageQueueItem {
 	in.CreatedAt = timestamppb.New(i.CreatedAt)
 	in.Attempts = int32(i.Attempts)
 	in.MaxAttempts = int32(i.MaxAttempts)
+	if i.ReserveTimeout != 0 {
+		in.ReserveTimeout = i.ReserveTimeout.String()
+	}
+	if !i.ExpireDeadline.IsZero() {
+		in.ExpireDeadline = timestamppb.New(i.ExpireDeadline)
+	}
+	in.DedupId = i.DedupID
+	in.Priority = int32(i.Priority)
+	in.OrderingKey = i.OrderingKey
 	in.IsReserved = i.IsReserved
 	in.Reference = i.Reference
 	in.Encoding = i.Encoding
@@ -100,12 +137,29 @@ func (i *Item) ToProto(in *pb.StorageQueueItem) *pb.StorageQueueItem {
 	return in
 }
 
+// IsExpired returns true if the item has an expiry and the expiry has passed
+func (i *Item) IsExpired(now clock.Time) bool {
+	return !i.ExpireDeadline.IsZero() && !now.Before(i.ExpireDeadline)
+}
+
+// Size returns the number of bytes the user supplied fields of the item occupy
+func (i *Item) Size() int {
+	return len(i.Payload) + len(i.Reference) + len(i.Encoding) + len(i.Kind) + len(i.OrderingKey)
+}
+
 func (i *Item) FromProto(in *pb.StorageQueueItem) *Item {
 	i.ReserveDeadline = in.ReserveDeadline.AsTime()
 	i.DeadDeadline = in.DeadDeadline.AsTime()
 	i.CreatedAt = in.CreatedAt.AsTime()
 	i.Attempts = int(in.Attempts)
 	i.MaxAttempts = int(in.MaxAttempts)
+	i.ReserveTimeout, _ = clock.ParseDuration(in.ReserveTimeout)
+	if in.ExpireDeadline != nil {
+		i.ExpireDeadline = in.ExpireDeadline.AsTime()
+	}
+	i.DedupID = in.DedupId
+	i.Priority = int(in.Priority)
+	i.OrderingKey = in.OrderingKey
 	i.IsReserved = in.IsReserved
 	i.Reference = in.Reference
 	i.Encoding = in.Encoding
@@ -117,6 +171,8 @@ func (i *Item) FromProto(in *pb.StorageQueueItem) *Item {
 
 // PartitionInfo is information about partition
 type PartitionInfo struct {
+	// The namespace the queue belongs too
+	Namespace string
 	QueueName string
 	// Which StorageConfig Instance this partition belong too
 	StorageName string
@@ -128,6 +184,9 @@ type PartitionInfo struct {
 
 // QueueInfo is information about a queue
 type QueueInfo struct {
+	// Namespace is the namespace (tenant) this queue belongs too. An empty namespace
+	// is the default namespace.
+	Namespace string
 	// The name of the queue
 	Name string
 	// ReserveTimeout is how long the reservation is valid for.
@@ -148,6 +207,19 @@ type QueueInfo struct {
 	Reference string
 	// Partitions is the number of partitions this queue expects
 	Partitions int
+	// MaxItemSize is the maximum size in bytes of an item payload produced to this queue.
+	// If zero, the maximum item size configured for the service is used.
+	MaxItemSize int
+	// DedupWindow is how long the DedupID of a produced item is remembered.
+	// If zero, DefaultDedupWindow is used.
+	DedupWindow clock.Duration
+	// PriorityWeights is the relative weight of each priority level used to decide which priority
+	// the next item is reserved from. If empty or all zero, higher priority levels are drained before
+	// any item of a lower priority level is reserved.
+	PriorityWeights []int
+	// ExpireTimeout is how long an item is useful for after it is produced. This value is used
+	// if no ExpireTimeout is provided by the produced item. If zero, items do not expire.
+	ExpireTimeout clock.Duration
 	// PartitionInfo is a list current partition details
 	PartitionInfo []PartitionInfo
 }
@@ -158,12 +230,29 @@ func (i *QueueInfo) ToProto(in *pb.QueueInfo) *pb.QueueInfo {
 	in.CreatedAt = timestamppb.New(i.CreatedAt)
 	in.DeadTimeout = i.DeadTimeout.String()
 	in.MaxAttempts = int32(i.MaxAttempts)
+	in.MaxItemSize = int32(i.MaxItemSize)
+	if i.DedupWindow != 0 {
+		in.DedupWindow = i.DedupWindow.String()
+	}
+	if i.ExpireTimeout != 0 {
+		in.ExpireTimeout = i.ExpireTimeout.String()
+	}
+	in.PriorityWeights = nil
+	for _, w := range i.PriorityWeights {
+		in.PriorityWeights = append(in.PriorityWeights, int32(w))
+	}
 	in.DeadQueue = i.DeadQueue
+	in.Namespace = i.Namespace
 	in.Reference = i.Reference
 	in.QueueName = i.Name
 	return in
 }
 
+// Key returns the unique key for this queue which combines the namespace and name of the queue.
+func (i *QueueInfo) Key() string {
+	return QueueKey(i.Namespace, i.Name)
+}
+
 func (i *QueueInfo) Update(r QueueInfo) bool {
 	if r.DeadTimeout.Nanoseconds() != 0 {
 		i.DeadTimeout = r.DeadTimeout
@@ -186,5 +275,67 @@ func (i *QueueInfo) Update(r QueueInfo) bool {
 	if r.Partitions != 0 && i.Partitions != r.Partitions {
 		i.Partitions = r.Partitions
 	}
+	if r.MaxItemSize != 0 && i.MaxItemSize != r.MaxItemSize {
+		i.MaxItemSize = r.MaxItemSize
+	}
+	if r.DedupWindow.Nanoseconds() != 0 {
+		i.DedupWindow = r.DedupWindow
+	}
+	if len(r.PriorityWeights) != 0 {
+		i.PriorityWeights = r.PriorityWeights
+	}
+	if r.ExpireTimeout.Nanoseconds() != 0 {
+		i.ExpireTimeout = r.ExpireTimeout
+	}
 	return true
 }
+
+// QueueKey returns a key which uniquely identifies a queue across all namespaces. Queues in the
+// default namespace are keyed by name only, all others are keyed by `<namespace>~<name>`. Since
+// neither namespaces nor queue names can contain a `~` the key is never ambiguous.
+func QueueKey(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "~" + name
+}
+
+// NamespaceQuota is the limits imposed upon all the queues within a namespace. A limit
+// of zero means the limit is not enforced.
+type NamespaceQuota struct {
+	// MaxQueues is the maximum number of queues which can be created in the namespace
+	MaxQueues int
+	// MaxItems is the maximum number of items all queues in the namespace can hold
+	MaxItems int64
+	// MaxBytes is the maximum number of payload bytes all queues in the namespace can hold
+	MaxBytes int64
+}
+
+// RateLimit is a token bucket rate limit. A Rate of zero means the limit is not enforced.
+type RateLimit struct {
+	// Rate is the number of requests per second which are permitted
+	Rate float64
+	// Burst is the maximum number of requests permitted to exceed Rate in a short period of time.
+	// If Burst is less than 1, it defaults to 1.
+	Burst int
+}
+
+// RateLimits are the rate limits enforced on requests to a queue. Limits which are "PerQueue"
+// apply to all requests made to a single queue, limits which are "PerClient" apply to all requests
+// made to a single queue by a single client. Clients are identified by the client_id of the request if
+// it has one, otherwise by the identity provided by the client, see transport.HeaderClientIdentity for
+// when the identity can be trusted.
+//
+// Extend and release requests have no limits of their own, they are limited by CompletePerQueue
+// and CompletePerClient. Likewise peek and get requests are limited by ReservePerQueue and
+// ReservePerClient. Each method is counted separately, such that extend and release requests do
+// not use up the tokens of complete requests, and peek and get requests do not use up the tokens
+// of reserve requests.
+type RateLimits struct {
+	ProducePerQueue   RateLimit
+	ProducePerClient  RateLimit
+	ReservePerQueue   RateLimit
+	ReservePerClient  RateLimit
+	CompletePerQueue  RateLimit
+	CompletePerClient RateLimit
+}
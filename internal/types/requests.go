@@ -2,6 +2,7 @@ package types
 
 import (
 	"context"
+	pb "github.com/kapetan-io/querator/proto"
 	"github.com/kapetan-io/tackle/clock"
 )
 
@@ -15,10 +16,15 @@ type ReserveRequest struct {
 	RequestTimeout clock.Duration
 	// The id of the client
 	ClientID string
+	// MaxBytes is the maximum number of item bytes which can be returned in a single reservation. The first
+	// item assigned to the request is never limited by MaxBytes. Zero means no limit.
+	MaxBytes int
 	// The context of the requesting client
 	Context context.Context
 	// The result of the reservation
 	Items []*Item
+	// The total size of all the Items in the reservation
+	itemBytes int
 	// The RequestDeadline calculated from RequestTimeout
 	RequestDeadline clock.Time
 	// Used to wait for this request to complete
@@ -49,6 +55,11 @@ type CompleteRequest struct {
 	Context context.Context
 	// The ids to mark as complete
 	Ids [][]byte
+	// If Detailed is true each id is completed independently of the others, and the outcome
+	// of each id is recorded in Results instead of failing the request with Err.
+	Detailed bool
+	// Results is the outcome of each id in the same order as Ids, only populated if Detailed is true
+	Results []CompleteResult
 	// The RequestDeadline calculated from RequestTimeout
 	RequestDeadline clock.Time
 	// Used to wait for this request to complete
@@ -57,6 +68,106 @@ type CompleteRequest struct {
 	Err error
 }
 
+// Completed returns the number of ids marked as complete by the request
+func (r *CompleteRequest) Completed() int {
+	if !r.Detailed {
+		return len(r.Ids)
+	}
+	var count int
+	for _, result := range r.Results {
+		if result.Status == pb.CompleteStatus_COMPLETE_OK {
+			count++
+		}
+	}
+	return count
+}
+
+// CompleteResult is the outcome of completing a single id in a detailed CompleteRequest
+type CompleteResult struct {
+	ID      ItemID
+	Status  pb.CompleteStatus
+	Message string
+}
+
+type ExtendRequest struct {
+	// How long the caller expects Extend() to block before returning
+	RequestTimeout clock.Duration
+	// How long from now the reservations should be extended by. If zero, items are extended
+	// by their own ReserveTimeout, or by the ReserveTimeout of the queue.
+	ReserveTimeout clock.Duration
+	// The context of the requesting client
+	Context context.Context
+	// The ids of the reserved items to extend
+	Ids [][]byte
+	// ExtendedAt is the time the request was processed
+	ExtendedAt clock.Time
+	// The new ReserveDeadline calculated from ReserveTimeout when the request is processed
+	ReserveDeadline clock.Time
+	// ReserveDeadlines are the new ReserveDeadline of each of the Ids, in the same order as Ids,
+	// assigned by the partition. See Deadline()
+	ReserveDeadlines []clock.Time
+	// The RequestDeadline calculated from RequestTimeout
+	RequestDeadline clock.Time
+	// Used to wait for this request to complete
+	ReadyCh chan struct{}
+	// The error to be returned to the caller
+	Err error
+}
+
+// Deadline returns the new ReserveDeadline of the item provided. Items produced with a ReserveTimeout
+// are extended by that timeout unless the request provided a ReserveTimeout.
+func (r *ExtendRequest) Deadline(item *Item) clock.Time {
+	if r.ReserveTimeout == 0 && item.ReserveTimeout != 0 {
+		return r.ExtendedAt.Add(item.ReserveTimeout)
+	}
+	return r.ReserveDeadline
+}
+
+type ReleaseRequest struct {
+	// How long the caller expects Release() to block before returning
+	RequestTimeout clock.Duration
+	// The context of the requesting client
+	Context context.Context
+	// The ids of the reserved items to release
+	Ids [][]byte
+	// SkipAttempt is true if the release should not be counted as an attempt to process the items
+	SkipAttempt bool
+	// The RequestDeadline calculated from RequestTimeout
+	RequestDeadline clock.Time
+	// Used to wait for this request to complete
+	ReadyCh chan struct{}
+	// The error to be returned to the caller
+	Err error
+}
+
+type ConsumerRequest struct {
+	// The id of the client registering as a consumer
+	ClientID string
+	// HeartbeatTimeout is how long the consumer can go without a heartbeat before it is considered
+	// gone and its reservations are released. Only used when registering.
+	HeartbeatTimeout clock.Duration
+	// HeartbeatDeadline is the time the next heartbeat is expected by, returned to the caller
+	HeartbeatDeadline clock.Time
+}
+
+// ConsumerInfo is information about a consumer registered with a queue
+type ConsumerInfo struct {
+	// ClientID is the id the consumer registered with
+	ClientID string
+	// HeartbeatTimeout is how long the consumer can go without a heartbeat
+	HeartbeatTimeout clock.Duration
+	// RegisteredAt is the time the consumer registered
+	RegisteredAt clock.Time
+	// LastActivity is the time of the last register, heartbeat or reserve request from the consumer
+	LastActivity clock.Time
+	// HeartbeatDeadline is the time after which the consumer is considered gone if no further
+	// heartbeat or reserve request is received
+	HeartbeatDeadline clock.Time
+	// Reserved is the number of items reserved by the consumer which have not been
+	// completed or released
+	Reserved int
+}
+
 type ClearRequest struct {
 	// Defer indicates the 'defer' queue will be cleared. If true, any items
 	// scheduled to be retried at a future date will be removed.
@@ -95,15 +206,43 @@ type ListOptions struct {
 	Limit int
 }
 
+// HealthCheck is the result of checking a single component of the service
+type HealthCheck struct {
+	// Name is the name of the component checked
+	Name string
+	// Err is nil if the check passed
+	Err error
+}
+
+// DrainStatus reports the progress of draining the service
+type DrainStatus struct {
+	// Draining is true if the service is draining
+	Draining bool
+	// Queues is the number of queues running
+	Queues int
+	// InFlight is the number of client requests being processed by the queues
+	InFlight int
+	// Reserved is the number of items reserved by consumers which have not been completed
+	Reserved int
+}
+
 type QueueStats struct {
 	// Total is the number of items in the queue
 	Total int
 	// TotalReserved is the number of items in the queue that are in reserved state
 	TotalReserved int
+	// TotalBytes is the sum of all the item payloads in the queue
+	TotalBytes int64
 	// AverageAge is the average age of all items in the queue
 	AverageAge clock.Duration
 	// AverageReservedAge is the average age of reserved items in the queue
 	AverageReservedAge clock.Duration
+	// OldestAge is the age of the oldest item in the queue
+	OldestAge clock.Duration
+	// TotalExpired is the number of items removed from the queue because they expired since the
+	// partition was opened, including expired items which have not yet been removed. The count of
+	// removed items is held in memory only and resets when the partition is closed or the service restarts.
+	TotalExpired int
 	// ProduceWaiting is the number of `/queue.produce` requests currently waiting
 	// to be processed by the sync loop
 	ProduceWaiting int
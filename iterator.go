@@ -0,0 +1,151 @@
+package querator
+
+import (
+	"context"
+	pb "github.com/kapetan-io/querator/proto"
+)
+
+// ListIterator pages through the results of a list API. List APIs include the pivot in the results, the
+// iterator accounts for this such that each item is returned only once.
+//
+//	it := client.QueuesListIter(&ListOptions{Limit: 100})
+//	for it.Next(ctx) {
+//		fmt.Println(it.Value().QueueName)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type ListIterator[T any] struct {
+	fetch func(ctx context.Context, opts ListOptions) ([]T, error)
+	id    func(T) string
+	opts  ListOptions
+	// pivot is the id of the last item returned, which will be the first item of the next page
+	pivot string
+	page  []T
+	value T
+	err   error
+	done  bool
+}
+
+// Next advances the iterator to the next item, fetching the next page if needed. Returns false
+// when there are no more items or an error occurred. Check Err() to determine which.
+func (it *ListIterator[T]) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+
+	for len(it.page) == 0 {
+		if it.done {
+			return false
+		}
+		if err := it.nextPage(ctx); err != nil {
+			it.err = err
+			return false
+		}
+	}
+
+	it.value, it.page = it.page[0], it.page[1:]
+	it.pivot = it.id(it.value)
+	return true
+}
+
+// Value returns the current item
+func (it *ListIterator[T]) Value() T {
+	return it.value
+}
+
+// Err returns the error, if any, which occurred while iterating
+func (it *ListIterator[T]) Err() error {
+	return it.err
+}
+
+// All returns a function which yields each item. It is compatible with range-over-func
+//
+//	for item, err := range it.All(ctx) {
+//		if err != nil {
+//			return err
+//		}
+//	}
+func (it *ListIterator[T]) All(ctx context.Context) func(yield func(T, error) bool) {
+	return func(yield func(T, error) bool) {
+		for it.Next(ctx) {
+			if !yield(it.Value(), nil) {
+				return
+			}
+		}
+		if it.Err() != nil {
+			var empty T
+			yield(empty, it.Err())
+		}
+	}
+}
+
+func (it *ListIterator[T]) nextPage(ctx context.Context) error {
+	opts := it.opts
+	// Pages after the first start with the last item returned, so we ask for one more
+	// item than the page size to account for the pivot.
+	if it.pivot != "" {
+		opts.Pivot = it.pivot
+		opts.Limit++
+	}
+
+	items, err := it.fetch(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if len(items) < opts.Limit {
+		it.done = true
+	}
+
+	if it.pivot != "" && len(items) != 0 && it.id(items[0]) == it.pivot {
+		items = items[1:]
+	}
+	if len(items) == 0 {
+		it.done = true
+	}
+	it.page = items
+	return nil
+}
+
+func newListIterator[T any](opts *ListOptions, id func(T) string,
+	fetch func(ctx context.Context, opts ListOptions) ([]T, error)) *ListIterator[T] {
+	it := &ListIterator[T]{fetch: fetch, id: id}
+	if opts != nil {
+		it.opts = *opts
+	}
+	if it.opts.Limit <= 0 {
+		it.opts.Limit = DefaultListLimit
+	}
+	return it
+}
+
+// QueuesListIter returns an iterator which pages through all the queues. ListOptions.Limit is the
+// number of queues fetched in each page, and ListOptions.Pivot is the name of the first queue returned.
+func (c *Client) QueuesListIter(opts *ListOptions) *ListIterator[*pb.QueueInfo] {
+	return newListIterator(opts, (*pb.QueueInfo).GetQueueName,
+		func(ctx context.Context, opts ListOptions) ([]*pb.QueueInfo, error) {
+			var resp pb.QueuesListResponse
+			if err := c.QueuesList(ctx, &resp, &opts); err != nil {
+				return nil, err
+			}
+			return resp.Items, nil
+		})
+}
+
+// StorageQueueListIter returns an iterator which pages through all the items in the named queue.
+// ListOptions.Limit is the number of items fetched in each page, and ListOptions.Pivot is the id
+// of the first item returned.
+func (c *Client) StorageQueueListIter(name string, opts *ListOptions) *ListIterator[*pb.StorageQueueItem] {
+	return newListIterator(opts, (*pb.StorageQueueItem).GetId,
+		func(ctx context.Context, opts ListOptions) ([]*pb.StorageQueueItem, error) {
+			var resp pb.StorageQueueListResponse
+			if err := c.StorageQueueList(ctx, name, &resp, &opts); err != nil {
+				return nil, err
+			}
+			return resp.Items, nil
+		})
+}
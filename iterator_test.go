@@ -0,0 +1,137 @@
+package querator_test
+
+import (
+	"context"
+	"fmt"
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestListIterators(t *testing.T) {
+	bdb := boltTestSetup{Dir: t.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			testListIterators(t, tc.Setup, tc.TearDown)
+		})
+	}
+}
+
+func testListIterators(t *testing.T, setup NewStorageFunc, tearDown func()) {
+	_store := setup(clock.NewProvider())
+	defer tearDown()
+	d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store})
+	defer d.Shutdown(t)
+
+	var names []string
+	for i := 0; i < 25; i++ {
+		name := fmt.Sprintf("queue-%02d", i)
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      name,
+			ReserveTimeout: "1m",
+			DeadTimeout:    "10m",
+			Partitions:     1,
+		}))
+		names = append(names, name)
+	}
+
+	t.Run("QueuesList", func(t *testing.T) {
+		for _, limit := range []int{1, 7, 25, 100} {
+			it := c.QueuesListIter(&que.ListOptions{Limit: limit})
+			var results []string
+			for it.Next(ctx) {
+				results = append(results, it.Value().QueueName)
+			}
+			require.NoError(t, it.Err())
+			assert.Equal(t, names, results, "limit %d", limit)
+		}
+	})
+
+	t.Run("QueuesListPivot", func(t *testing.T) {
+		it := c.QueuesListIter(&que.ListOptions{Limit: 10, Pivot: "queue-20"})
+		var results []string
+		for it.Next(ctx) {
+			results = append(results, it.Value().QueueName)
+		}
+		require.NoError(t, it.Err())
+		assert.Equal(t, names[20:], results)
+	})
+
+	t.Run("StorageQueueList", func(t *testing.T) {
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      names[0],
+			RequestTimeout: "1m",
+			Items:          randomProduceItems(25),
+		}))
+
+		var list pb.StorageQueueListResponse
+		require.NoError(t, c.StorageQueueList(ctx, names[0], &list, &que.ListOptions{Limit: 25}))
+		require.Equal(t, 25, len(list.Items))
+
+		it := c.StorageQueueListIter(names[0], &que.ListOptions{Limit: 10})
+		var ids []string
+		it.All(ctx)(func(item *pb.StorageQueueItem, err error) bool {
+			require.NoError(t, err)
+			ids = append(ids, item.Id)
+			return true
+		})
+		assert.Equal(t, que.CollectIDs(list.Items), ids)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		cancelCtx, cancel := context.WithCancel(ctx)
+		it := c.QueuesListIter(&que.ListOptions{Limit: 10})
+		var count int
+		it.All(cancelCtx)(func(q *pb.QueueInfo, err error) bool {
+			if err != nil {
+				assert.ErrorIs(t, err, context.Canceled)
+				return false
+			}
+			count++
+			// Cancel in the middle of the first page
+			if count == 5 {
+				cancel()
+			}
+			return true
+		})
+		assert.Equal(t, 5, count)
+		assert.ErrorIs(t, it.Err(), context.Canceled)
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		it := c.QueuesListIter(&que.ListOptions{Limit: 10})
+		var results []string
+		it.All(ctx)(func(q *pb.QueueInfo, err error) bool {
+			require.NoError(t, err)
+			results = append(results, q.QueueName)
+			return len(results) < 3
+		})
+		assert.Equal(t, names[:3], results)
+	})
+}
@@ -0,0 +1,118 @@
+package querator_test
+
+import (
+	"fmt"
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestQueueMetrics(t *testing.T) {
+	bdb := boltTestSetup{Dir: t.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			testQueueMetrics(t, tc.Setup, tc.TearDown)
+		})
+	}
+}
+
+func testQueueMetrics(t *testing.T, setup NewStorageFunc, tearDown func()) {
+	var queueName = random.String("queue-", 10)
+	_store := setup(clock.NewProvider())
+	defer tearDown()
+
+	d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store})
+	defer d.Shutdown(t)
+
+	require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+		QueueName:      queueName,
+		ReserveTimeout: "1m",
+		DeadTimeout:    "10m",
+		Partitions:     1,
+	}))
+
+	require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+		QueueName:      queueName,
+		RequestTimeout: "1m",
+		Items:          randomProduceItems(10),
+	}))
+
+	var reserve pb.QueueReserveResponse
+	require.NoError(t, c.QueueReserve(ctx, &pb.QueueReserveRequest{
+		ClientId:       random.String("client-", 10),
+		QueueName:      queueName,
+		RequestTimeout: "1m",
+		BatchSize:      6,
+	}, &reserve))
+	require.Equal(t, 6, len(reserve.Items))
+
+	require.NoError(t, c.QueueComplete(ctx, &pb.QueueCompleteRequest{
+		QueueName:      queueName,
+		RequestTimeout: "1m",
+		Ids:            que.CollectIDs(reserve.Items[:4]),
+	}))
+
+	// The depth, reserved and oldest age of the queue are fetched in the background after the first scrape
+	var metrics string
+	queue := fmt.Sprintf(`namespace="",queue="%s"`, queueName)
+	partition := fmt.Sprintf(`namespace="",partition="0",queue="%s"`, queueName)
+	require.Eventually(t, func() bool {
+		metrics = scrapeMetrics(t, d)
+		return strings.Contains(metrics, fmt.Sprintf(`queue_depth{%s}`, partition))
+	}, 5*clock.Second, 10*clock.Millisecond)
+	assert.Contains(t, metrics, fmt.Sprintf(`queue_depth{%s} 6`, partition))
+	assert.Contains(t, metrics, fmt.Sprintf(`queue_reserved{%s} 2`, partition))
+	assert.Contains(t, metrics, fmt.Sprintf(`queue_oldest_item_age_seconds{%s}`, partition))
+	assert.Contains(t, metrics, fmt.Sprintf(`queue_items_total{namespace="",op="produce",queue="%s"} 10`, queueName))
+	assert.Contains(t, metrics, fmt.Sprintf(`queue_items_total{namespace="",op="reserve",queue="%s"} 6`, queueName))
+	assert.Contains(t, metrics, fmt.Sprintf(`queue_items_total{namespace="",op="complete",queue="%s"} 4`, queueName))
+	assert.Contains(t, metrics, fmt.Sprintf(`queue_batch_size_count{namespace="",op="produce",queue="%s"} 1`, queueName))
+	assert.Contains(t, metrics, fmt.Sprintf(`queue_sync_loop_duration_seconds_count{%s}`, queue))
+	assert.Contains(t, metrics, fmt.Sprintf(`partition_storage_duration_seconds_count{method="Produce",namespace="",`+
+		`partition="0",queue="%s",storage="%s"} 1`, queueName, _store.Backends[0].Name))
+
+	// Metrics for a deleted queue are removed
+	require.NoError(t, c.QueuesDelete(ctx, &pb.QueuesDeleteRequest{QueueName: queueName}))
+	assert.NotContains(t, scrapeMetrics(t, d), queueName)
+}
+
+func scrapeMetrics(t *testing.T, d *testDaemon) string {
+	t.Helper()
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/metrics", d.d.Listener.Addr().String()))
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(b)
+}
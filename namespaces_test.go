@@ -0,0 +1,315 @@
+package querator_test
+
+import (
+	"errors"
+	"github.com/duh-rpc/duh-go"
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNamespaces(t *testing.T) {
+	bdb := boltTestSetup{Dir: t.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+		// Durable is true if the storage survives a restart of the daemon
+		Durable bool
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+			Durable: true,
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			testNamespaces(t, tc.Setup, tc.TearDown, tc.Durable)
+		})
+	}
+}
+
+func testNamespaces(t *testing.T, setup NewStorageFunc, tearDown func(), durable bool) {
+	t.Run("Isolation", func(t *testing.T) {
+		_store := setup(clock.NewProvider())
+		defer tearDown()
+		d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store})
+		defer d.Shutdown(t)
+
+		alpha := namespaceClient(t, d, "alpha")
+		beta := namespaceClient(t, d, "beta")
+
+		// The same queue name is created in the default namespace and each of the other namespaces
+		queueName := random.String("queue-", 10)
+		for _, client := range []*que.Client{c, alpha, beta} {
+			require.NoError(t, client.QueuesCreate(ctx, &pb.QueueInfo{
+				QueueName:      queueName,
+				ReserveTimeout: "1m",
+				DeadTimeout:    "10m",
+				Partitions:     1,
+			}))
+		}
+		require.NoError(t, alpha.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      "alpha-only",
+			ReserveTimeout: "1m",
+			DeadTimeout:    "10m",
+			Partitions:     1,
+		}))
+
+		t.Run("List", func(t *testing.T) {
+			var list pb.QueuesListResponse
+			require.NoError(t, c.QueuesList(ctx, &list, nil))
+			require.Equal(t, 1, len(list.Items))
+			assert.Equal(t, queueName, list.Items[0].QueueName)
+			assert.Equal(t, "", list.Items[0].Namespace)
+
+			list.Reset()
+			require.NoError(t, alpha.QueuesList(ctx, &list, nil))
+			require.Equal(t, 2, len(list.Items))
+			for _, item := range list.Items {
+				assert.Equal(t, "alpha", item.Namespace)
+			}
+
+			list.Reset()
+			require.NoError(t, beta.QueuesList(ctx, &list, nil))
+			require.Equal(t, 1, len(list.Items))
+			assert.Equal(t, queueName, list.Items[0].QueueName)
+			assert.Equal(t, "beta", list.Items[0].Namespace)
+		})
+
+		t.Run("Items", func(t *testing.T) {
+			require.NoError(t, alpha.QueueProduce(ctx, &pb.QueueProduceRequest{
+				QueueName:      queueName,
+				RequestTimeout: "1m",
+				Items:          randomProduceItems(10),
+			}))
+
+			var stats pb.QueueStatsResponse
+			require.NoError(t, alpha.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: queueName}, &stats))
+			assert.Equal(t, int32(10), stats.Total)
+
+			// Items produced in one namespace are not visible in another
+			stats.Reset()
+			require.NoError(t, beta.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: queueName}, &stats))
+			assert.Equal(t, int32(0), stats.Total)
+
+			stats.Reset()
+			require.NoError(t, c.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: queueName}, &stats))
+			assert.Equal(t, int32(0), stats.Total)
+		})
+
+		t.Run("NotFound", func(t *testing.T) {
+			var stats pb.QueueStatsResponse
+			err := beta.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: "alpha-only"}, &stats)
+			require.Error(t, err)
+			var e duh.Error
+			require.True(t, errors.As(err, &e))
+			assert.Equal(t, "queue does not exist; no such queue named 'alpha-only'", e.Message())
+			assert.Equal(t, duh.CodeBadRequest, e.Code())
+		})
+
+		t.Run("Delete", func(t *testing.T) {
+			require.NoError(t, beta.QueuesDelete(ctx, &pb.QueuesDeleteRequest{QueueName: queueName}))
+
+			var list pb.QueuesListResponse
+			require.NoError(t, beta.QueuesList(ctx, &list, nil))
+			assert.Equal(t, 0, len(list.Items))
+
+			// Queues of the same name in other namespaces are unaffected
+			list.Reset()
+			require.NoError(t, alpha.QueuesList(ctx, &list, nil))
+			assert.Equal(t, 2, len(list.Items))
+
+			list.Reset()
+			require.NoError(t, c.QueuesList(ctx, &list, nil))
+			assert.Equal(t, 1, len(list.Items))
+		})
+	})
+
+	t.Run("Quotas", func(t *testing.T) {
+		_store := setup(clock.NewProvider())
+		defer tearDown()
+		d, _, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{
+			StorageConfig: _store,
+			NamespaceQuotas: map[string]que.NamespaceQuota{
+				"limited": {MaxQueues: 2, MaxItems: 15, MaxBytes: 1_000},
+			},
+		})
+		defer d.Shutdown(t)
+
+		limited := namespaceClient(t, d, "limited")
+		unlimited := namespaceClient(t, d, "unlimited")
+
+		t.Run("MaxQueues", func(t *testing.T) {
+			for _, name := range []string{"queue-1", "queue-2"} {
+				require.NoError(t, limited.QueuesCreate(ctx, &pb.QueueInfo{
+					QueueName:      name,
+					ReserveTimeout: "1m",
+					DeadTimeout:    "10m",
+					Partitions:     1,
+				}))
+			}
+
+			err := limited.QueuesCreate(ctx, &pb.QueueInfo{
+				QueueName:      "queue-3",
+				ReserveTimeout: "1m",
+				DeadTimeout:    "10m",
+				Partitions:     1,
+			})
+			require.Error(t, err)
+			var e duh.Error
+			require.True(t, errors.As(err, &e))
+			assert.Equal(t, "namespace quota exceeded; namespace 'limited' is limited to 2 queues", e.Message())
+			assert.Equal(t, duh.CodeRetryRequest, e.Code())
+
+			// Other namespaces are not limited
+			for _, name := range []string{"queue-1", "queue-2", "queue-3"} {
+				require.NoError(t, unlimited.QueuesCreate(ctx, &pb.QueueInfo{
+					QueueName:      name,
+					ReserveTimeout: "1m",
+					DeadTimeout:    "10m",
+					Partitions:     1,
+				}))
+			}
+		})
+
+		t.Run("MaxItems", func(t *testing.T) {
+			require.NoError(t, limited.QueueProduce(ctx, &pb.QueueProduceRequest{
+				QueueName:      "queue-1",
+				RequestTimeout: "1m",
+				Items:          randomProduceItems(10),
+			}))
+
+			// The quota applies to all queues in the namespace
+			err := limited.QueueProduce(ctx, &pb.QueueProduceRequest{
+				QueueName:      "queue-2",
+				RequestTimeout: "1m",
+				Items:          randomProduceItems(10),
+			})
+			require.Error(t, err)
+			var e duh.Error
+			require.True(t, errors.As(err, &e))
+			assert.Equal(t, "namespace quota exceeded; namespace 'limited' is limited to 15 items", e.Message())
+			assert.Equal(t, duh.CodeRetryRequest, e.Code())
+
+			// Completing items frees up space in the namespace
+			var reserve pb.QueueReserveResponse
+			require.NoError(t, limited.QueueReserve(ctx, &pb.QueueReserveRequest{
+				ClientId:       random.String("client-", 10),
+				QueueName:      "queue-1",
+				RequestTimeout: "1m",
+				BatchSize:      10,
+			}, &reserve))
+			require.Equal(t, 10, len(reserve.Items))
+
+			require.NoError(t, limited.QueueComplete(ctx, &pb.QueueCompleteRequest{
+				QueueName:      "queue-1",
+				RequestTimeout: "1m",
+				Ids:            que.CollectIDs(reserve.Items),
+			}))
+
+			require.NoError(t, limited.QueueProduce(ctx, &pb.QueueProduceRequest{
+				QueueName:      "queue-2",
+				RequestTimeout: "1m",
+				Items:          randomProduceItems(10),
+			}))
+
+			require.NoError(t, unlimited.QueueProduce(ctx, &pb.QueueProduceRequest{
+				QueueName:      "queue-1",
+				RequestTimeout: "1m",
+				Items:          randomProduceItems(100),
+			}))
+		})
+
+		t.Run("MaxBytes", func(t *testing.T) {
+			err := limited.QueueProduce(ctx, &pb.QueueProduceRequest{
+				QueueName:      "queue-1",
+				RequestTimeout: "1m",
+				Items: []*pb.QueueProduceItem{
+					{Bytes: make([]byte, 1_001)},
+				},
+			})
+			require.Error(t, err)
+			var e duh.Error
+			require.True(t, errors.As(err, &e))
+			assert.Equal(t, "namespace quota exceeded; namespace 'limited' is limited to 1000 bytes", e.Message())
+			assert.Equal(t, duh.CodeRetryRequest, e.Code())
+		})
+	})
+
+	t.Run("QuotaAfterRestart", func(t *testing.T) {
+		if !durable {
+			t.Skip("storage does not survive a restart")
+		}
+		_store := setup(clock.NewProvider())
+		defer tearDown()
+		conf := que.ServiceConfig{
+			StorageConfig: _store,
+			NamespaceQuotas: map[string]que.NamespaceQuota{
+				"limited": {MaxItems: 15},
+			},
+		}
+		d, _, ctx := newDaemon(t, 10*clock.Second, conf)
+		defer func() { d.Shutdown(t) }()
+
+		limited := namespaceClient(t, d, "limited")
+		for _, name := range []string{"queue-1", "queue-2"} {
+			require.NoError(t, limited.QueuesCreate(ctx, &pb.QueueInfo{
+				QueueName:      name,
+				ReserveTimeout: "1m",
+				DeadTimeout:    "10m",
+				Partitions:     1,
+			}))
+		}
+		produce := func(name string, count int) error {
+			return limited.QueueProduce(ctx, &pb.QueueProduceRequest{
+				QueueName:      name,
+				RequestTimeout: "1m",
+				Items:          randomProduceItems(count),
+			})
+		}
+		require.NoError(t, produce("queue-1", 10))
+
+		d.Shutdown(t)
+		d, _, ctx = newDaemon(t, 10*clock.Second, conf)
+		limited = namespaceClient(t, d, "limited")
+
+		// Items held by a queue which has not been started since the restart count towards the quota
+		require.ErrorContains(t, produce("queue-2", 10), "namespace 'limited' is limited to 15 items")
+		require.NoError(t, produce("queue-2", 5))
+
+		// Once started, the items of the queue are counted by the running queue
+		var stats pb.QueueStatsResponse
+		require.NoError(t, limited.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: "queue-1"}, &stats))
+		assert.Equal(t, int32(10), stats.Total)
+		require.ErrorContains(t, produce("queue-2", 1), "namespace 'limited' is limited to 15 items")
+	})
+}
+
+func namespaceClient(t *testing.T, d *testDaemon, namespace string) *que.Client {
+	t.Helper()
+
+	conf := que.WithNoTLS(d.d.Listener.Addr().String())
+	conf.Namespace = namespace
+	c, err := que.NewClient(conf)
+	require.NoError(t, err)
+	return c
+}
@@ -0,0 +1,188 @@
+package querator_test
+
+import (
+	"testing"
+
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderingKey(t *testing.T) {
+	bdb := boltTestSetup{Dir: t.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			testOrderingKey(t, tc.Setup, tc.TearDown)
+		})
+	}
+}
+
+func testOrderingKey(t *testing.T, setup NewStorageFunc, tearDown func()) {
+	_store := setup(clock.NewProvider())
+	defer tearDown()
+	d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store})
+	defer d.Shutdown(t)
+
+	createQueue := func(t *testing.T) string {
+		queueName := random.String("queue-", 10)
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      queueName,
+			ReserveTimeout: "1m",
+			DeadTimeout:    "10m",
+			Partitions:     1,
+		}))
+		return queueName
+	}
+
+	// reserve returns the payloads of the reserved items, and a map of payload to item id
+	reserve := func(t *testing.T, queueName string, ids map[string]string) []string {
+		var res pb.QueueReserveResponse
+		require.NoError(t, c.QueueReserve(ctx, &pb.QueueReserveRequest{
+			ClientId:       random.String("client-", 10),
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			BatchSize:      10,
+		}, &res))
+		var payloads []string
+		for _, item := range res.Items {
+			payloads = append(payloads, string(item.Bytes))
+			ids[string(item.Bytes)] = item.Id
+		}
+		return payloads
+	}
+
+	complete := func(t *testing.T, queueName string, ids ...string) {
+		require.NoError(t, c.QueueComplete(ctx, &pb.QueueCompleteRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Ids:            ids,
+		}))
+	}
+
+	t.Run("OneReservedPerKey", func(t *testing.T) {
+		queueName := createQueue(t)
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items: []*pb.QueueProduceItem{
+				{Utf8: "a", OrderingKey: "account-1"},
+				{Utf8: "b", OrderingKey: "account-1"},
+				{Utf8: "c", OrderingKey: "account-2"},
+				{Utf8: "d"},
+				{Utf8: "e", OrderingKey: "account-1"},
+			},
+		}))
+
+		ids := make(map[string]string)
+		assert.Equal(t, []string{"a", "c", "d"}, reserve(t, queueName, ids))
+
+		// Completing the first item of a key allows the next item of the key to be reserved
+		complete(t, queueName, ids["a"])
+		assert.Equal(t, []string{"b"}, reserve(t, queueName, ids))
+
+		// A released item is reserved again before the items which follow it
+		require.NoError(t, c.QueueRelease(ctx, &pb.QueueReleaseRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Ids:            []string{ids["b"]},
+		}))
+		assert.Equal(t, []string{"b"}, reserve(t, queueName, ids))
+
+		complete(t, queueName, ids["b"], ids["c"], ids["d"])
+		var res pb.QueueReserveResponse
+		require.NoError(t, c.QueueReserve(ctx, &pb.QueueReserveRequest{
+			ClientId:       random.String("client-", 10),
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			BatchSize:      10,
+		}, &res))
+		require.Len(t, res.Items, 1)
+		assert.Equal(t, "e", string(res.Items[0].Bytes))
+		assert.Equal(t, "account-1", res.Items[0].OrderingKey)
+	})
+
+	t.Run("OrderBeforePriority", func(t *testing.T) {
+		queueName := createQueue(t)
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items: []*pb.QueueProduceItem{
+				{Utf8: "a", OrderingKey: "account-1"},
+				{Utf8: "b", OrderingKey: "account-1", Priority: 3},
+				{Utf8: "c", Priority: 3},
+			},
+		}))
+
+		// The higher priority item of a key cannot jump ahead of an earlier item with the same key
+		ids := make(map[string]string)
+		assert.Equal(t, []string{"c", "a"}, reserve(t, queueName, ids))
+		complete(t, queueName, ids["a"])
+		assert.Equal(t, []string{"b"}, reserve(t, queueName, ids))
+	})
+
+	t.Run("MultiplePartitions", func(t *testing.T) {
+		queueName := random.String("queue-", 10)
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      queueName,
+			ReserveTimeout: "1m",
+			DeadTimeout:    "10m",
+			Partitions:     2,
+		}))
+
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items: []*pb.QueueProduceItem{
+				{Utf8: "a", OrderingKey: "account-1"},
+				{Utf8: "b", OrderingKey: "account-2"},
+				{Utf8: "c", OrderingKey: "account-1"},
+				{Utf8: "d", OrderingKey: "account-2"},
+			},
+		}))
+
+		// Items with the same key are reserved in the order they were produced
+		ids := make(map[string]string)
+		assert.Equal(t, []string{"a", "b"}, reserve(t, queueName, ids))
+		complete(t, queueName, ids["a"])
+		assert.Equal(t, []string{"c"}, reserve(t, queueName, ids))
+		complete(t, queueName, ids["b"])
+		assert.Equal(t, []string{"d"}, reserve(t, queueName, ids))
+	})
+
+	t.Run("OrderingKeyMaxLength", func(t *testing.T) {
+		queueName := createQueue(t)
+		err := c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          []*pb.QueueProduceItem{{OrderingKey: random.String("", 513)}},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "item ordering key is invalid; cannot be greater than '512' characters")
+	})
+}
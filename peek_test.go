@@ -0,0 +1,205 @@
+package querator_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/duh-rpc/duh-go"
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeekAndGet(t *testing.T) {
+	bdb := boltTestSetup{Dir: t.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			testPeekAndGet(t, tc.Setup, tc.TearDown)
+		})
+	}
+}
+
+func testPeekAndGet(t *testing.T, setup NewStorageFunc, tearDown func()) {
+	_store := setup(clock.NewProvider())
+	defer tearDown()
+	d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store})
+	defer d.Shutdown(t)
+
+	createQueue := func(t *testing.T) string {
+		queueName := random.String("queue-", 10)
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      queueName,
+			ReserveTimeout: "1m",
+			DeadTimeout:    "10m",
+			Partitions:     1,
+		}))
+		return queueName
+	}
+
+	produce := func(t *testing.T, queueName string) {
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items: []*pb.QueueProduceItem{
+				{Utf8: "a"},
+				{Utf8: "b", Priority: 3, OrderingKey: "account-1"},
+				{Utf8: "c", Priority: 3, OrderingKey: "account-1"},
+				{Utf8: "d", Priority: 1},
+				{Utf8: "e"},
+			},
+		}))
+	}
+
+	peek := func(t *testing.T, queueName string, batchSize int32) []*pb.QueueItem {
+		var res pb.QueuePeekResponse
+		require.NoError(t, c.QueuePeek(ctx, &pb.QueuePeekRequest{
+			QueueName: queueName,
+			BatchSize: batchSize,
+		}, &res))
+		return res.Items
+	}
+
+	payloads := func(items []*pb.QueueItem) []string {
+		var results []string
+		for _, item := range items {
+			results = append(results, string(item.Bytes))
+		}
+		return results
+	}
+
+	t.Run("PeekMatchesReserveOrder", func(t *testing.T) {
+		queueName := createQueue(t)
+		produce(t, queueName)
+
+		// Peeking repeatedly does not reserve the items or change the order of future reservations
+		items := peek(t, queueName, 10)
+		assert.Equal(t, []string{"b", "d", "a", "e"}, payloads(items))
+		assert.Equal(t, []string{"b", "d", "a", "e"}, payloads(peek(t, queueName, 10)))
+		assert.Equal(t, []string{"b", "d"}, payloads(peek(t, queueName, 2)))
+
+		item := items[0]
+		assert.Equal(t, int32(3), item.Priority)
+		assert.Equal(t, "account-1", item.OrderingKey)
+		assert.False(t, item.IsReserved)
+		assert.Nil(t, item.ReserveDeadline)
+		assert.NotNil(t, item.DeadDeadline)
+		assert.NotNil(t, item.CreatedAt)
+		assert.Equal(t, int32(0), item.Attempts)
+
+		var res pb.QueueReserveResponse
+		require.NoError(t, c.QueueReserve(ctx, &pb.QueueReserveRequest{
+			ClientId:       random.String("client-", 10),
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			BatchSize:      2,
+		}, &res))
+		require.Len(t, res.Items, 2)
+		assert.Equal(t, items[0].Id, res.Items[0].Id)
+		assert.Equal(t, items[1].Id, res.Items[1].Id)
+
+		// Reserved items are no longer returned by peek
+		assert.Equal(t, []string{"a", "e"}, payloads(peek(t, queueName, 10)))
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		queueName := createQueue(t)
+		produce(t, queueName)
+		items := peek(t, queueName, 10)
+		require.Len(t, items, 4)
+
+		var res pb.QueueReserveResponse
+		require.NoError(t, c.QueueReserve(ctx, &pb.QueueReserveRequest{
+			ClientId:       random.String("client-", 10),
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			BatchSize:      1,
+		}, &res))
+		require.Len(t, res.Items, 1)
+
+		// Items are returned in the order requested, including reserved items
+		var get pb.QueueGetResponse
+		require.NoError(t, c.QueueGet(ctx, &pb.QueueGetRequest{
+			QueueName: queueName,
+			Ids:       []string{items[2].Id, items[0].Id},
+		}, &get))
+		require.Len(t, get.Items, 2)
+		assert.Equal(t, "a", string(get.Items[0].Bytes))
+		assert.False(t, get.Items[0].IsReserved)
+		assert.Equal(t, "b", string(get.Items[1].Bytes))
+		assert.True(t, get.Items[1].IsReserved)
+		assert.Equal(t, res.Items[0].ReserveDeadline.AsTime(), get.Items[1].ReserveDeadline.AsTime())
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		queueName := createQueue(t)
+		for _, test := range []struct {
+			Name string
+			Err  error
+			Msg  string
+		}{
+			{
+				Name: "PeekBatchSizeZero",
+				Err: c.QueuePeek(ctx, &pb.QueuePeekRequest{
+					QueueName: queueName,
+				}, &pb.QueuePeekResponse{}),
+				Msg: "invalid batch size; must be greater than zero",
+			},
+			{
+				Name: "PeekBatchSizeTooLarge",
+				Err: c.QueuePeek(ctx, &pb.QueuePeekRequest{
+					QueueName: queueName,
+					BatchSize: 1_001,
+				}, &pb.QueuePeekResponse{}),
+				Msg: "invalid batch size; max_reserve_batch_size is 1000, but 1001 was requested",
+			},
+			{
+				Name: "GetNoIDs",
+				Err: c.QueueGet(ctx, &pb.QueueGetRequest{
+					QueueName: queueName,
+				}, &pb.QueueGetResponse{}),
+				Msg: "ids is invalid; list of ids cannot be empty",
+			},
+			{
+				Name: "GetInvalidID",
+				Err: c.QueueGet(ctx, &pb.QueueGetRequest{
+					QueueName: queueName,
+					Ids:       []string{"another-invalid-id"},
+				}, &pb.QueueGetResponse{}),
+				Msg: "invalid storage id; 'another-invalid-id': Valid encoded KSUIDs are 27 characters",
+			},
+		} {
+			t.Run(test.Name, func(t *testing.T) {
+				var e duh.Error
+				require.True(t, errors.As(test.Err, &e))
+				assert.Equal(t, test.Msg, e.Message())
+				assert.Equal(t, duh.CodeBadRequest, e.Code())
+			})
+		}
+	})
+}
@@ -0,0 +1,242 @@
+package querator_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/duh-rpc/duh-go"
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriority(t *testing.T) {
+	bdb := boltTestSetup{Dir: t.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			testPriority(t, tc.Setup, tc.TearDown)
+		})
+	}
+}
+
+func testPriority(t *testing.T, setup NewStorageFunc, tearDown func()) {
+	_store := setup(clock.NewProvider())
+	defer tearDown()
+	d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store})
+	defer d.Shutdown(t)
+
+	createQueue := func(t *testing.T, weights ...int32) string {
+		queueName := random.String("queue-", 10)
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:       queueName,
+			ReserveTimeout:  "1m",
+			DeadTimeout:     "10m",
+			PriorityWeights: weights,
+			Partitions:      1,
+		}))
+		return queueName
+	}
+
+	// produce an item for each priority provided, the payload of each item is its position in the list
+	produce := func(t *testing.T, queueName string, priorities ...int32) {
+		var items []*pb.QueueProduceItem
+		for i, p := range priorities {
+			items = append(items, &pb.QueueProduceItem{Priority: p, Utf8: string(rune('a' + i))})
+		}
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          items,
+		}))
+	}
+
+	reserve := func(t *testing.T, queueName string, batchSize int32) (payloads []string, priorities []int32) {
+		var res pb.QueueReserveResponse
+		require.NoError(t, c.QueueReserve(ctx, &pb.QueueReserveRequest{
+			ClientId:       random.String("client-", 10),
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			BatchSize:      batchSize,
+		}, &res))
+		for _, item := range res.Items {
+			payloads = append(payloads, string(item.Bytes))
+			priorities = append(priorities, item.Priority)
+		}
+		return
+	}
+
+	t.Run("DrainsHigherFirst", func(t *testing.T) {
+		queueName := createQueue(t)
+		produce(t, queueName, 0, 3, 1, 3, 0)
+
+		payloads, priorities := reserve(t, queueName, 5)
+		assert.Equal(t, []string{"b", "d", "c", "a", "e"}, payloads)
+		assert.Equal(t, []int32{3, 3, 1, 0, 0}, priorities)
+
+		// Listing items from storage remains in the order they were produced
+		var list pb.StorageQueueListResponse
+		require.NoError(t, c.StorageQueueList(ctx, queueName, &list, &que.ListOptions{Limit: 10}))
+		require.Len(t, list.Items, 5)
+		assert.Equal(t, int32(0), list.Items[0].Priority)
+		assert.Equal(t, int32(3), list.Items[1].Priority)
+	})
+
+	t.Run("HigherBehindManyLower", func(t *testing.T) {
+		queueName := createQueue(t)
+		priorities := make([]int32, 20)
+		priorities[19] = 2
+		produce(t, queueName, priorities...)
+
+		// The scan for reservable items must not stop before finding the higher priority item
+		payloads, _ := reserve(t, queueName, 1)
+		assert.Equal(t, []string{"t"}, payloads)
+		payloads, _ = reserve(t, queueName, 2)
+		assert.Equal(t, []string{"a", "b"}, payloads)
+	})
+
+	t.Run("StrictByDefault", func(t *testing.T) {
+		// Without weights, or with weights which are all zero, lower priority items are only
+		// reserved once no higher priority items are waiting.
+		for _, queueName := range []string{createQueue(t), createQueue(t, 0, 0, 0, 0)} {
+			priorities := make([]int32, 17)
+			for i := 1; i < len(priorities); i++ {
+				priorities[i] = 3
+			}
+			produce(t, queueName, priorities...)
+
+			for i := 0; i < 16; i++ {
+				_, p := reserve(t, queueName, 1)
+				require.Equal(t, []int32{3}, p)
+			}
+			payloads, _ := reserve(t, queueName, 1)
+			assert.Equal(t, []string{"a"}, payloads)
+		}
+	})
+
+	t.Run("WeightsPreventStarvation", func(t *testing.T) {
+		queueName := createQueue(t, 1, 1, 1, 3)
+		produce(t, queueName, 3, 3, 3, 3, 3, 3, 0, 0)
+
+		// With weights of 1 and 3, one of every four items reserved comes from priority 0
+		// even though priority 3 items are still waiting.
+		var payloads []string
+		for i := 0; i < 4; i++ {
+			p, _ := reserve(t, queueName, 1)
+			payloads = append(payloads, p...)
+		}
+		assert.Equal(t, []string{"a", "b", "g", "c"}, payloads)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		queueName := createQueue(t)
+		for _, test := range []struct {
+			Name string
+			Err  error
+			Msg  string
+		}{
+			{
+				Name: "InvalidPriority",
+				Err: c.QueueProduce(ctx, &pb.QueueProduceRequest{
+					QueueName:      queueName,
+					RequestTimeout: "1m",
+					Items:          []*pb.QueueProduceItem{{Priority: 4}},
+				}),
+				Msg: "item priority is invalid; 4 must be between 0 and 3",
+			},
+			{
+				Name: "NegativePriority",
+				Err: c.QueueProduce(ctx, &pb.QueueProduceRequest{
+					QueueName:      queueName,
+					RequestTimeout: "1m",
+					Items:          []*pb.QueueProduceItem{{Priority: -1}},
+				}),
+				Msg: "item priority is invalid; -1 must be between 0 and 3",
+			},
+			{
+				Name: "PriorityWeightsCount",
+				Err: c.QueuesCreate(ctx, &pb.QueueInfo{
+					QueueName:       random.String("queue-", 10),
+					ReserveTimeout:  "1m",
+					DeadTimeout:     "10m",
+					PriorityWeights: []int32{1, 2, 3},
+					Partitions:      1,
+				}),
+				Msg: "priority weights is invalid; expected 4 weights but received 3",
+			},
+			{
+				Name: "PriorityWeightsTooMany",
+				Err: c.QueuesCreate(ctx, &pb.QueueInfo{
+					QueueName:       random.String("queue-", 10),
+					ReserveTimeout:  "1m",
+					DeadTimeout:     "10m",
+					PriorityWeights: []int32{1, 2, 4, 8, 16},
+					Partitions:      1,
+				}),
+				Msg: "priority weights is invalid; expected 4 weights but received 5",
+			},
+			{
+				Name: "PriorityWeightNegative",
+				Err: c.QueuesCreate(ctx, &pb.QueueInfo{
+					QueueName:       random.String("queue-", 10),
+					ReserveTimeout:  "1m",
+					DeadTimeout:     "10m",
+					PriorityWeights: []int32{1, -2, 4, 8},
+					Partitions:      1,
+				}),
+				Msg: "priority weights is invalid; weight cannot be negative",
+			},
+			{
+				Name: "PriorityWeightZero",
+				Err: c.QueuesCreate(ctx, &pb.QueueInfo{
+					QueueName:       random.String("queue-", 10),
+					ReserveTimeout:  "1m",
+					DeadTimeout:     "10m",
+					PriorityWeights: []int32{0, 2, 4, 8},
+					Partitions:      1,
+				}),
+				Msg: "priority weights is invalid; weight cannot be 0 unless all weights are 0",
+			},
+			{
+				Name: "UpdatePriorityWeightNegative",
+				Err: c.QueuesUpdate(ctx, &pb.QueueInfo{
+					QueueName:       queueName,
+					PriorityWeights: []int32{-1, -1, -1, -1},
+				}),
+				Msg: "priority weights is invalid; weight cannot be negative",
+			},
+		} {
+			t.Run(test.Name, func(t *testing.T) {
+				var e duh.Error
+				require.True(t, errors.As(test.Err, &e))
+				assert.Equal(t, test.Msg, e.Message())
+				assert.Equal(t, duh.CodeBadRequest, e.Code())
+			})
+		}
+	})
+}
@@ -0,0 +1,281 @@
+package querator
+
+import (
+	"context"
+	"errors"
+	"github.com/duh-rpc/duh-go"
+	"github.com/kapetan-io/querator/internal"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/set"
+	"github.com/segmentio/ksuid"
+	"google.golang.org/protobuf/proto"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultProducerLinger          = 10 * clock.Millisecond
+	DefaultProducerBufferSize      = 10_000
+	DefaultProducerRequestTimeout  = 30 * clock.Second
+	DefaultProducerRetryBackoff    = 100 * clock.Millisecond
+	DefaultProducerMaxRetryBackoff = 5 * clock.Second
+	DefaultProducerMaxAttempts     = 10
+)
+
+var ErrProducerClosed = errors.New("producer is closed")
+
+type ProducerConfig struct {
+	// Client is the client used to produce items
+	Client *Client
+	// QueueName is the name of the queue items are produced to
+	QueueName string
+	// MaxBatchSize is the maximum number of items produced in a single request. This should not
+	// be greater than the MaxProduceBatchSize of the server. The default is 1,000.
+	MaxBatchSize int
+	// Linger is the maximum amount of time an item is buffered waiting for a batch to fill
+	// before the batch is produced. The default is 10 milliseconds.
+	Linger clock.Duration
+	// BufferSize is the maximum number of items which can be pending at any given time. Once the
+	// buffer is full, calls to Produce() block until there is space in the buffer.
+	// The default is 10,000.
+	BufferSize int
+	// RequestTimeout is the request_timeout of each produce request. The default is 30 seconds.
+	RequestTimeout clock.Duration
+	// RetryBackoff is the initial amount of time the producer waits before retrying a batch which
+	// failed with a retryable error, such as when the queue is overloaded. The backoff doubles
+	// with each attempt up to MaxRetryBackoff. If the server provided a retry after hint, the
+	// producer waits for the hint instead. The default is 100 milliseconds.
+	RetryBackoff clock.Duration
+	// MaxRetryBackoff is the maximum amount of time to wait between retries. The default is 5 seconds.
+	MaxRetryBackoff clock.Duration
+	// MaxAttempts is the maximum number of attempts made to produce a batch before the items in the
+	// batch are failed. The default is 10.
+	MaxAttempts int
+	// Logger is used to log errors which occur while producing. The default is slog.Default()
+	Logger duh.StandardLogger
+}
+
+// ProduceFuture is the result of an item produced by the Producer
+type ProduceFuture struct {
+	callback func(error)
+	done     chan struct{}
+	err      error
+}
+
+// Done returns a channel which is closed once the item has been produced or has failed
+func (f *ProduceFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Err returns the error which occurred while producing the item. Err is only valid after
+// Done() has been closed.
+func (f *ProduceFuture) Err() error {
+	return f.err
+}
+
+// Wait blocks until the item has been produced or the context is cancelled
+func (f *ProduceFuture) Wait(ctx context.Context) error {
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type pendingItem struct {
+	item   *pb.QueueProduceItem
+	future *ProduceFuture
+}
+
+// Producer buffers items and produces them to a queue in batches. A batch is produced once it
+// reaches MaxBatchSize or once the first item in the batch has waited for Linger.
+//
+// The producer assigns a DedupId to each item which does not have one before the first attempt,
+// such that retrying a batch which may have been written, for instance after a request timeout,
+// does not produce duplicate items as long as the retries complete within the dedup_window of
+// the queue. The retries are made in place of, not in addition to, the RetryPolicy of the Client.
+type Producer struct {
+	itemCh chan *pendingItem
+	// pending holds a token for every item which has not yet been produced or failed
+	pending chan struct{}
+	// closeCh is closed once Close() is called, such that Produce() stops waiting for buffer space
+	closeCh chan struct{}
+	done    chan struct{}
+	cancel  context.CancelFunc
+	ctx     context.Context
+	conf    ProducerConfig
+	closed  bool
+	mutex   sync.RWMutex
+}
+
+// NewProducer creates a new Producer and starts the background flush loop
+func NewProducer(conf ProducerConfig) (*Producer, error) {
+	set.Default(&conf.MaxBatchSize, internal.DefaultMaxProduceBatchSize)
+	set.Default(&conf.Linger, DefaultProducerLinger)
+	set.Default(&conf.BufferSize, DefaultProducerBufferSize)
+	set.Default(&conf.RequestTimeout, DefaultProducerRequestTimeout)
+	set.Default(&conf.RetryBackoff, DefaultProducerRetryBackoff)
+	set.Default(&conf.MaxRetryBackoff, DefaultProducerMaxRetryBackoff)
+	set.Default(&conf.MaxAttempts, DefaultProducerMaxAttempts)
+	set.Default(&conf.Logger, slog.Default())
+
+	if conf.Client == nil {
+		return nil, errors.New("conf.Client cannot be nil")
+	}
+	if conf.QueueName == "" {
+		return nil, errors.New("conf.QueueName cannot be empty")
+	}
+
+	p := &Producer{
+		itemCh:  make(chan *pendingItem, conf.BufferSize),
+		pending: make(chan struct{}, conf.BufferSize),
+		closeCh: make(chan struct{}),
+		done:    make(chan struct{}),
+		conf:    conf,
+	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	go p.run()
+	return p, nil
+}
+
+// Produce adds the item to the buffer and returns a future which completes once the item has been
+// produced or has failed. If the buffer is full, Produce blocks until there is room in the buffer,
+// the producer is closed or the context is cancelled. The item must not be modified until the
+// future completes.
+func (p *Producer) Produce(ctx context.Context, item *pb.QueueProduceItem) (*ProduceFuture, error) {
+	return p.produce(ctx, item, nil)
+}
+
+// ProduceFunc is identical to Produce, except the callback provided is called once the item has been
+// produced or has failed. The callback is called from the flush loop and should not block.
+func (p *Producer) ProduceFunc(ctx context.Context, item *pb.QueueProduceItem, cb func(error)) error {
+	_, err := p.produce(ctx, item, cb)
+	return err
+}
+
+func (p *Producer) produce(ctx context.Context, item *pb.QueueProduceItem, cb func(error)) (*ProduceFuture, error) {
+	// Wait for room in the buffer without holding the mutex, such that Close() is not blocked
+	select {
+	case p.pending <- struct{}{}:
+	case <-p.closeCh:
+		return nil, ErrProducerClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	defer p.mutex.RUnlock()
+	p.mutex.RLock()
+
+	if p.closed {
+		<-p.pending
+		return nil, ErrProducerClosed
+	}
+
+	f := &ProduceFuture{done: make(chan struct{}), callback: cb}
+	p.itemCh <- &pendingItem{item: item, future: f}
+	return f, nil
+}
+
+// Close stops accepting new items and waits for all buffered items to be produced. If the
+// context is cancelled before all items are produced, the remaining items fail with the
+// context error and Close returns the context error.
+func (p *Producer) Close(ctx context.Context) error {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.closeCh)
+	close(p.itemCh)
+	p.mutex.Unlock()
+
+	select {
+	case <-p.done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		<-p.done
+		return ctx.Err()
+	}
+}
+
+func (p *Producer) run() {
+	defer close(p.done)
+
+	batch := make([]*pendingItem, 0, p.conf.MaxBatchSize)
+	var linger <-chan time.Time
+
+	for {
+		select {
+		case item, ok := <-p.itemCh:
+			if !ok {
+				p.flush(batch)
+				return
+			}
+			batch = append(batch, item)
+			if len(batch) == 1 {
+				linger = clock.After(p.conf.Linger)
+			}
+			if len(batch) >= p.conf.MaxBatchSize {
+				p.flush(batch)
+				batch = batch[:0]
+				linger = nil
+			}
+		case <-linger:
+			p.flush(batch)
+			batch = batch[:0]
+			linger = nil
+		}
+	}
+}
+
+// flush produces the batch, retrying if the server asks us to, and then completes the futures for
+// each item in the batch.
+func (p *Producer) flush(batch []*pendingItem) {
+	if len(batch) == 0 {
+		return
+	}
+
+	req := pb.QueueProduceRequest{
+		RequestTimeout: p.conf.RequestTimeout.String(),
+		QueueName:      p.conf.QueueName,
+		Items:          make([]*pb.QueueProduceItem, 0, len(batch)),
+	}
+	for _, pi := range batch {
+		// Assign a dedup id before the first attempt, such that retries cannot produce duplicates. The
+		// id is assigned to a copy, as the item provided to Produce() belongs to the caller.
+		item := pi.item
+		if item.DedupId == "" {
+			item = proto.Clone(pi.item).(*pb.QueueProduceItem)
+			item.DedupId = ksuid.New().String()
+		}
+		req.Items = append(req.Items, item)
+	}
+
+	// Since every item has a dedup id, the batch is safe to retry on any retryable error
+	err := p.conf.Client.produce(p.ctx, &req, RetryPolicy{
+		MaxAttempts:        p.conf.MaxAttempts,
+		InitialBackoff:     p.conf.RetryBackoff,
+		MaxBackoff:         p.conf.MaxRetryBackoff,
+		Jitter:             p.conf.Client.conf.RetryPolicy.Jitter,
+		RetryNonIdempotent: true,
+	})
+	if err != nil {
+		p.conf.Logger.Error("while producing items", "error", err, "items", len(batch),
+			"category", "producer", "queueName", p.conf.QueueName)
+	}
+
+	for _, pi := range batch {
+		pi.future.err = err
+		close(pi.future.done)
+		if pi.future.callback != nil {
+			pi.future.callback(err)
+		}
+		<-p.pending
+	}
+}
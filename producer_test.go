@@ -0,0 +1,232 @@
+package querator_test
+
+import (
+	"context"
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestProducer(t *testing.T) {
+	bdb := boltTestSetup{Dir: t.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			testProducer(t, tc.Setup, tc.TearDown)
+		})
+	}
+}
+
+func testProducer(t *testing.T, setup NewStorageFunc, tearDown func()) {
+	_store := setup(clock.NewProvider())
+	defer tearDown()
+	d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{
+		StorageConfig: _store,
+		RateLimits: que.RateLimits{
+			// Produce requests to a queue which exceed this rate fail with a retry request error
+			ProducePerQueue: que.RateLimit{Rate: 20, Burst: 1},
+		},
+	})
+	defer d.Shutdown(t)
+
+	createQueue := func(t *testing.T) string {
+		queueName := random.String("queue-", 10)
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      queueName,
+			ReserveTimeout: "1m",
+			DeadTimeout:    "10m",
+			Partitions:     1,
+		}))
+		return queueName
+	}
+
+	stats := func(t *testing.T, queueName string) *pb.QueueStatsResponse {
+		var stats pb.QueueStatsResponse
+		require.NoError(t, c.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: queueName}, &stats))
+		return &stats
+	}
+
+	t.Run("Batching", func(t *testing.T) {
+		queueName := createQueue(t)
+		p, err := que.NewProducer(que.ProducerConfig{
+			Client:       c,
+			QueueName:    queueName,
+			MaxBatchSize: 10,
+			Linger:       clock.Hour,
+			Logger:       log,
+		})
+		require.NoError(t, err)
+
+		var futures []*que.ProduceFuture
+		for _, item := range randomProduceItems(25) {
+			f, err := p.Produce(ctx, item)
+			require.NoError(t, err)
+			futures = append(futures, f)
+		}
+
+		// Full batches are produced without waiting for the linger interval
+		for _, f := range futures[:20] {
+			require.NoError(t, f.Wait(ctx))
+		}
+		assert.Equal(t, int32(20), stats(t, queueName).Total)
+
+		// The partial batch is produced on close
+		require.NoError(t, p.Close(ctx))
+		for _, f := range futures[20:] {
+			<-f.Done()
+			require.NoError(t, f.Err())
+		}
+		assert.Equal(t, int32(25), stats(t, queueName).Total)
+
+		_, err = p.Produce(ctx, randomProduceItems(1)[0])
+		assert.ErrorIs(t, err, que.ErrProducerClosed)
+	})
+
+	t.Run("Linger", func(t *testing.T) {
+		queueName := createQueue(t)
+		p, err := que.NewProducer(que.ProducerConfig{
+			Client:       c,
+			QueueName:    queueName,
+			MaxBatchSize: 100,
+			Linger:       50 * clock.Millisecond,
+			Logger:       log,
+		})
+		require.NoError(t, err)
+		defer func() { _ = p.Close(ctx) }()
+
+		results := make(chan error, 3)
+		for _, item := range randomProduceItems(3) {
+			require.NoError(t, p.ProduceFunc(ctx, item, func(err error) {
+				results <- err
+			}))
+		}
+
+		// The partial batch is produced after the linger interval
+		for i := 0; i < 3; i++ {
+			require.NoError(t, <-results)
+		}
+		assert.Equal(t, int32(3), stats(t, queueName).Total)
+	})
+
+	t.Run("BackPressure", func(t *testing.T) {
+		queueName := createQueue(t)
+		p, err := que.NewProducer(que.ProducerConfig{
+			Client:       c,
+			QueueName:    queueName,
+			MaxBatchSize: 100,
+			BufferSize:   2,
+			Linger:       clock.Hour,
+			Logger:       log,
+		})
+		require.NoError(t, err)
+
+		items := randomProduceItems(3)
+		for _, item := range items[:2] {
+			_, err := p.Produce(ctx, item)
+			require.NoError(t, err)
+		}
+
+		// Produce blocks while the buffer is full
+		timeout, cancel := context.WithTimeout(ctx, 50*clock.Millisecond)
+		defer cancel()
+		_, err = p.Produce(timeout, items[2])
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		require.NoError(t, p.Close(ctx))
+		assert.Equal(t, int32(2), stats(t, queueName).Total)
+	})
+
+	t.Run("CloseWhileBufferFull", func(t *testing.T) {
+		queueName := createQueue(t)
+		p, err := que.NewProducer(que.ProducerConfig{
+			Client:       c,
+			QueueName:    queueName,
+			MaxBatchSize: 100,
+			BufferSize:   1,
+			Linger:       clock.Hour,
+			Logger:       log,
+		})
+		require.NoError(t, err)
+
+		items := randomProduceItems(2)
+		_, err = p.Produce(ctx, items[0])
+		require.NoError(t, err)
+
+		// A call to Produce waiting for buffer space does not prevent the producer from closing
+		errCh := make(chan error, 1)
+		go func() {
+			_, err := p.Produce(ctx, items[1])
+			errCh <- err
+		}()
+		// Allow the goroutine time to block while waiting for buffer space
+		<-clock.After(50 * clock.Millisecond)
+		require.NoError(t, p.Close(ctx))
+		assert.ErrorIs(t, <-errCh, que.ErrProducerClosed)
+		assert.Equal(t, int32(1), stats(t, queueName).Total)
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		queueName := createQueue(t)
+		p, err := que.NewProducer(que.ProducerConfig{
+			Client:       c,
+			QueueName:    queueName,
+			MaxBatchSize: 1,
+			Logger:       log,
+		})
+		require.NoError(t, err)
+
+		// Each item is produced in its own batch, which exceeds the rate limit of the queue
+		var futures []*que.ProduceFuture
+		items := randomProduceItems(3)
+		for _, item := range items {
+			f, err := p.Produce(ctx, item)
+			require.NoError(t, err)
+			futures = append(futures, f)
+		}
+
+		// The rate limited batches are retried until they succeed
+		for _, f := range futures {
+			require.NoError(t, f.Wait(ctx))
+		}
+		require.NoError(t, p.Close(ctx))
+		assert.Equal(t, int32(3), stats(t, queueName).Total)
+
+		// Each item was assigned a dedup id before the first attempt, such that retries are safe
+		var list pb.StorageQueueListResponse
+		require.NoError(t, c.StorageQueueList(ctx, queueName, &list, nil))
+		require.Len(t, list.Items, 3)
+		for _, item := range list.Items {
+			assert.NotEmpty(t, item.DedupId)
+		}
+		// The items provided by the caller are not modified
+		for _, item := range items {
+			assert.Empty(t, item.DedupId)
+		}
+	})
+}
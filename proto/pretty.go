@@ -11,6 +11,7 @@ func PPStats(stats *QueueStatsResponse) string {
 	buf.WriteString("Stats {")
 	_, _ = fmt.Fprintf(&buf, " Total: %d", stats.Total)
 	_, _ = fmt.Fprintf(&buf, " TotalReserved: %d", stats.TotalReserved)
+	_, _ = fmt.Fprintf(&buf, " TotalBytes: %d", stats.TotalBytes)
 	_, _ = fmt.Fprintf(&buf, " AverageAge: %s", stats.AverageAge)
 	_, _ = fmt.Fprintf(&buf, " AverageReservedAge: %s", stats.AverageReservedAge)
 	_, _ = fmt.Fprintf(&buf, " ProduceWaiting: %d", stats.ProduceWaiting)
@@ -36,6 +36,65 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type CompleteStatus int32
+
+const (
+	CompleteStatus_COMPLETE_UNSPECIFIED CompleteStatus = 0
+	// The item was marked as complete
+	CompleteStatus_COMPLETE_OK CompleteStatus = 1
+	// The item does not exist in the queue, it might have already been completed
+	CompleteStatus_COMPLETE_NOT_FOUND CompleteStatus = 2
+	// The item exists, but is not reserved
+	CompleteStatus_COMPLETE_NOT_RESERVED CompleteStatus = 3
+	// The id is not a valid id for the queue
+	CompleteStatus_COMPLETE_INVALID_ID CompleteStatus = 4
+)
+
+// Enum value maps for CompleteStatus.
+var (
+	CompleteStatus_name = map[int32]string{
+		0: "COMPLETE_UNSPECIFIED",
+		1: "COMPLETE_OK",
+		2: "COMPLETE_NOT_FOUND",
+		3: "COMPLETE_NOT_RESERVED",
+		4: "COMPLETE_INVALID_ID",
+	}
+	CompleteStatus_value = map[string]int32{
+		"COMPLETE_UNSPECIFIED":  0,
+		"COMPLETE_OK":           1,
+		"COMPLETE_NOT_FOUND":    2,
+		"COMPLETE_NOT_RESERVED": 3,
+		"COMPLETE_INVALID_ID":   4,
+	}
+)
+
+func (x CompleteStatus) Enum() *CompleteStatus {
+	p := new(CompleteStatus)
+	*p = x
+	return p
+}
+
+func (x CompleteStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CompleteStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_queue_proto_enumTypes[0].Descriptor()
+}
+
+func (CompleteStatus) Type() protoreflect.EnumType {
+	return &file_proto_queue_proto_enumTypes[0]
+}
+
+func (x CompleteStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CompleteStatus.Descriptor instead.
+func (CompleteStatus) EnumDescriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{0}
+}
+
 type QueueProduceRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -141,6 +200,33 @@ type QueueProduceItem struct {
 	// be dropped.
 	// Example: 'Hello, I am a UTF-8 payload' , '{"key", "value"}'
 	Utf8 string `protobuf:"bytes,5,opt,name=utf8,proto3" json:"utf8,omitempty"`
+	// How long a reservation of this item is valid for. If not provided, the reserve_timeout of the
+	// queue is used. Cannot be greater than the dead timeout of the item.
+	// Example: '30s', '1h'
+	ReserveTimeout string `protobuf:"bytes,6,opt,name=reserveTimeout,json=reserve_timeout,proto3" json:"reserveTimeout,omitempty"` // TODO: OpenAPI
+	// The maximum number of attempts to process this item. If not provided, the max_attempts of the
+	// queue is used. Cannot be greater than the max_attempts of the queue if the queue has a maximum.
+	MaxAttempts int32 `protobuf:"varint,7,opt,name=maxAttempts,json=max_attempts,proto3" json:"maxAttempts,omitempty"` // TODO: OpenAPI
+	// How long the item can wait in the queue regardless of attempts before it is considered dead. If not
+	// provided, the dead_timeout of the queue is used. Cannot be greater than the dead_timeout of the queue.
+	// Example: '1h', '24h'
+	DeadTimeout string `protobuf:"bytes,8,opt,name=deadTimeout,json=dead_timeout,proto3" json:"deadTimeout,omitempty"` // TODO: OpenAPI
+	// A producer supplied id used to make produce idempotent. If an item with the same dedup_id was
+	// produced to the same partition within the dedup_window of the queue, the item is acknowledged
+	// but not added to the queue.
+	DedupId string `protobuf:"bytes,9,opt,name=dedupId,json=dedup_id,proto3" json:"dedupId,omitempty"` // TODO: OpenAPI
+	// The priority level of the item, from 0 (the default and lowest priority) to 3. Items with a
+	// higher priority are reserved before items with a lower priority according to the
+	// priority_weights of the queue. Items with the same priority are reserved in FIFO order.
+	Priority int32 `protobuf:"varint,10,opt,name=priority,proto3" json:"priority,omitempty"` // TODO: OpenAPI
+	// Items with the same ordering_key are produced to the same partition and are processed in the order
+	// they were produced. While an item is reserved, no other item with the same ordering_key is reserved
+	// until the item is completed or released. Examples: 'account-0001', 'order-1234'
+	OrderingKey string `protobuf:"bytes,11,opt,name=orderingKey,json=ordering_key,proto3" json:"orderingKey,omitempty"` // TODO: OpenAPI
+	// How long the item is useful for. Once expired the item is never reserved and is removed from the
+	// queue without being moved to the dead letter queue. If not provided, the expire_timeout of the
+	// queue is used. Example: '30s', '1m'
+	ExpireTimeout string `protobuf:"bytes,12,opt,name=expireTimeout,json=expire_timeout,proto3" json:"expireTimeout,omitempty"` // TODO: OpenAPI
 }
 
 func (x *QueueProduceItem) Reset() {
@@ -210,6 +296,55 @@ func (x *QueueProduceItem) GetUtf8() string {
 	return ""
 }
 
+func (x *QueueProduceItem) GetReserveTimeout() string {
+	if x != nil {
+		return x.ReserveTimeout
+	}
+	return ""
+}
+
+func (x *QueueProduceItem) GetMaxAttempts() int32 {
+	if x != nil {
+		return x.MaxAttempts
+	}
+	return 0
+}
+
+func (x *QueueProduceItem) GetDeadTimeout() string {
+	if x != nil {
+		return x.DeadTimeout
+	}
+	return ""
+}
+
+func (x *QueueProduceItem) GetDedupId() string {
+	if x != nil {
+		return x.DedupId
+	}
+	return ""
+}
+
+func (x *QueueProduceItem) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *QueueProduceItem) GetOrderingKey() string {
+	if x != nil {
+		return x.OrderingKey
+	}
+	return ""
+}
+
+func (x *QueueProduceItem) GetExpireTimeout() string {
+	if x != nil {
+		return x.ExpireTimeout
+	}
+	return ""
+}
+
 type QueueReserveRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -217,7 +352,9 @@ type QueueReserveRequest struct {
 
 	// The name of the queue to reserve work from
 	QueueName string `protobuf:"bytes,1,opt,name=queueName,json=queue_name,proto3" json:"queueName,omitempty"`
-	// The number of queue items requested from the queue.
+	// The number of queue items requested from the queue. The total size of the items returned
+	// is limited by the maximum reserve response size configured for the service, as such fewer
+	// items than requested may be returned even if more items are available.
 	BatchSize int32 `protobuf:"varint,2,opt,name=batchSize,json=batch_size,proto3" json:"batchSize,omitempty"`
 	// A user supplied unique string which identifies the client making this request. This
 	// must be unique for each client reserving items. Multiple clients with the same
@@ -322,6 +459,10 @@ type QueueReserveItem struct {
 	// can contain non-UTF8 binary data, and since that cannot be directly represented in JSON, we
 	// have to base64 encode it.
 	Bytes []byte `protobuf:"bytes,7,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	// The priority level the item was produced with
+	Priority int32 `protobuf:"varint,8,opt,name=priority,proto3" json:"priority,omitempty"` // TODO: OpenAPI
+	// The ordering key the item was produced with
+	OrderingKey string `protobuf:"bytes,9,opt,name=orderingKey,json=ordering_key,proto3" json:"orderingKey,omitempty"` // TODO: OpenAPI
 }
 
 func (x *QueueReserveItem) Reset() {
@@ -405,6 +546,20 @@ func (x *QueueReserveItem) GetBytes() []byte {
 	return nil
 }
 
+func (x *QueueReserveItem) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *QueueReserveItem) GetOrderingKey() string {
+	if x != nil {
+		return x.OrderingKey
+	}
+	return ""
+}
+
 type QueueReserveResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -581,6 +736,10 @@ type QueueCompleteRequest struct {
 	RequestTimeout string `protobuf:"bytes,2,opt,name=requestTimeout,json=request_timeout,proto3" json:"requestTimeout,omitempty"` // TODO: OpenAPI
 	// A list of ids to mark complete
 	Ids []string `protobuf:"bytes,3,rep,name=ids,proto3" json:"ids,omitempty"`
+	// If true, each id is completed independently of the others and the outcome of each id is returned
+	// in QueueCompleteResponse. If false (the default) the request fails if any of the ids cannot be
+	// completed, in which case none of the ids are completed.
+	Detailed bool `protobuf:"varint,4,opt,name=detailed,proto3" json:"detailed,omitempty"`
 }
 
 func (x *QueueCompleteRequest) Reset() {
@@ -636,39 +795,28 @@ func (x *QueueCompleteRequest) GetIds() []string {
 	return nil
 }
 
-type QueueInfo struct {
+func (x *QueueCompleteRequest) GetDetailed() bool {
+	if x != nil {
+		return x.Detailed
+	}
+	return false
+}
+
+type QueueCompleteResult struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// The name of the queue
-	QueueName string `protobuf:"bytes,1,opt,name=queueName,json=queue_name,proto3" json:"queueName,omitempty"`
-	// The date the queue was created
-	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=createdAt,json=created_at,proto3" json:"createdAt,omitempty"`
-	// The date the queue was last updated
-	UpdatedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=updatedAt,json=updated_at,proto3" json:"updatedAt,omitempty"`
-	// The name of the dead letter queue for this queue. If this is a dead letter queue then
-	// this field will be empty when retrieved via '/queue.list'
-	DeadQueue string `protobuf:"bytes,4,opt,name=deadQueue,json=dead_queue,proto3" json:"deadQueue,omitempty"`
-	// This is a user supplied field which could contain metadata or specify who owns this queue
-	// Examples: "jake@statefarm.com", "stapler@office-space.com", "account-0001"
-	Reference string `protobuf:"bytes,5,opt,name=reference,proto3" json:"reference,omitempty"`
-	// The reservation timeout for this queue.
-	// Example: '60m', '24h', '10s'
-	ReserveTimeout string `protobuf:"bytes,6,opt,name=reserveTimeout,json=reserve_timeout,proto3" json:"reserveTimeout,omitempty"`
-	// How long the item can wait in the queue regardless of attempts before it is moved
-	// to the dead letter queue. Example: '24h', '60m', '10s'
-	DeadTimeout string `protobuf:"bytes,7,opt,name=deadTimeout,json=dead_timeout,proto3" json:"deadTimeout,omitempty"`
-	// The maximum number of times this message can be deferred by a consumer before it is
-	// placed in the dead letter queue.
-	MaxAttempts int32 `protobuf:"varint,8,opt,name=maxAttempts,json=max_attempts,proto3" json:"maxAttempts,omitempty"`
-	// The number of partitions the queue is requesting. This might be different than the
-	// actual number of partitions if the partition count was recently changed.
-	Partitions int32 `protobuf:"varint,9,opt,name=partitions,proto3" json:"partitions,omitempty"`
+	// The id provided in the request
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// The outcome of completing the id
+	Status CompleteStatus `protobuf:"varint,2,opt,name=status,proto3,enum=querator.CompleteStatus" json:"status,omitempty"`
+	// A human readable explanation of why the id could not be completed
+	Message string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
 }
 
-func (x *QueueInfo) Reset() {
-	*x = QueueInfo{}
+func (x *QueueCompleteResult) Reset() {
+	*x = QueueCompleteResult{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_proto_queue_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -676,13 +824,13 @@ func (x *QueueInfo) Reset() {
 	}
 }
 
-func (x *QueueInfo) String() string {
+func (x *QueueCompleteResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*QueueInfo) ProtoMessage() {}
+func (*QueueCompleteResult) ProtoMessage() {}
 
-func (x *QueueInfo) ProtoReflect() protoreflect.Message {
+func (x *QueueCompleteResult) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_queue_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -694,118 +842,116 @@ func (x *QueueInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use QueueInfo.ProtoReflect.Descriptor instead.
-func (*QueueInfo) Descriptor() ([]byte, []int) {
+// Deprecated: Use QueueCompleteResult.ProtoReflect.Descriptor instead.
+func (*QueueCompleteResult) Descriptor() ([]byte, []int) {
 	return file_proto_queue_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *QueueInfo) GetQueueName() string {
+func (x *QueueCompleteResult) GetId() string {
 	if x != nil {
-		return x.QueueName
+		return x.Id
 	}
 	return ""
 }
 
-func (x *QueueInfo) GetCreatedAt() *timestamppb.Timestamp {
+func (x *QueueCompleteResult) GetStatus() CompleteStatus {
 	if x != nil {
-		return x.CreatedAt
+		return x.Status
 	}
-	return nil
+	return CompleteStatus_COMPLETE_UNSPECIFIED
 }
 
-func (x *QueueInfo) GetUpdatedAt() *timestamppb.Timestamp {
+func (x *QueueCompleteResult) GetMessage() string {
 	if x != nil {
-		return x.UpdatedAt
+		return x.Message
 	}
-	return nil
+	return ""
 }
 
-func (x *QueueInfo) GetDeadQueue() string {
-	if x != nil {
-		return x.DeadQueue
-	}
-	return ""
+// QueueCompleteResponse is returned by '/queue.complete' if the request was 'detailed'
+type QueueCompleteResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The outcome of each id in the same order as the ids requested
+	Results []*QueueCompleteResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
 }
 
-func (x *QueueInfo) GetReference() string {
-	if x != nil {
-		return x.Reference
+func (x *QueueCompleteResponse) Reset() {
+	*x = QueueCompleteResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_queue_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return ""
 }
 
-func (x *QueueInfo) GetReserveTimeout() string {
-	if x != nil {
-		return x.ReserveTimeout
-	}
-	return ""
+func (x *QueueCompleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *QueueInfo) GetDeadTimeout() string {
-	if x != nil {
-		return x.DeadTimeout
+func (*QueueCompleteResponse) ProtoMessage() {}
+
+func (x *QueueCompleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_queue_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *QueueInfo) GetMaxAttempts() int32 {
-	if x != nil {
-		return x.MaxAttempts
-	}
-	return 0
+// Deprecated: Use QueueCompleteResponse.ProtoReflect.Descriptor instead.
+func (*QueueCompleteResponse) Descriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *QueueInfo) GetPartitions() int32 {
+func (x *QueueCompleteResponse) GetResults() []*QueueCompleteResult {
 	if x != nil {
-		return x.Partitions
+		return x.Results
 	}
-	return 0
+	return nil
 }
 
-type QueueClearRequest struct {
+type QueueExtendRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// The name of the queue
 	QueueName string `protobuf:"bytes,1,opt,name=queueName,json=queue_name,proto3" json:"queueName,omitempty"`
-	// Defer indicates the 'defer' queue will be cleared. If true, any items
-	// scheduled to be retried at a future date will be removed.
-	Defer bool `protobuf:"varint,2,opt,name=defer,proto3" json:"defer,omitempty"` // TODO: Implement
-	// Scheduled indicates any 'scheduled' items in the queue will be
-	// cleared. If true, any items scheduled to be enqueued at a future date
-	// will be removed.
-	Scheduled bool `protobuf:"varint,3,opt,name=scheduled,proto3" json:"scheduled,omitempty"` // TODO: Implement
-	// Queue indicates any items currently waiting in the FIFO queue will
-	// clear. If true, any items in the queue which have NOT been reserved
-	// will be removed.
-	Queue bool `protobuf:"varint,4,opt,name=Queue,proto3" json:"Queue,omitempty"`
-	// Destructive indicates the Defer,Scheduled,Queue operations should be
-	// destructive in that all data regardless of status will be removed.
-	// For example, if used with ClearRequest.Queue = true, then ALL items
-	// in the queue regardless of reserve status will be removed. This means
-	// that clients who currently have ownership of those items will not be able
-	// to "complete" those items, as querator will have no knowledge of those items.
-	Destructive bool `protobuf:"varint,5,opt,name=destructive,proto3" json:"destructive,omitempty"`
+	// The duration the client expects to wait for the reservations to be extended before timing out.
+	// Maximum timeout duration is 15 minutes
+	// Example: '5m', '10s'
+	RequestTimeout string `protobuf:"bytes,2,opt,name=requestTimeout,json=request_timeout,proto3" json:"requestTimeout,omitempty"` // TODO: OpenAPI
+	// The duration from now the reservations should be extended by. If not provided, the
+	// reserve_timeout of the queue is used. Cannot be greater than the dead_timeout of the queue.
+	// Example: '5m', '10s'
+	ReserveTimeout string `protobuf:"bytes,3,opt,name=reserveTimeout,json=reserve_timeout,proto3" json:"reserveTimeout,omitempty"` // TODO: OpenAPI
+	// A list of reserved ids to extend
+	Ids []string `protobuf:"bytes,4,rep,name=ids,proto3" json:"ids,omitempty"`
 }
 
-func (x *QueueClearRequest) Reset() {
-	*x = QueueClearRequest{}
+func (x *QueueExtendRequest) Reset() {
+	*x = QueueExtendRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_queue_proto_msgTypes[9]
+		mi := &file_proto_queue_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *QueueClearRequest) String() string {
+func (x *QueueExtendRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*QueueClearRequest) ProtoMessage() {}
+func (*QueueExtendRequest) ProtoMessage() {}
 
-func (x *QueueClearRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_queue_proto_msgTypes[9]
+func (x *QueueExtendRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_queue_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -816,71 +962,67 @@ func (x *QueueClearRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use QueueClearRequest.ProtoReflect.Descriptor instead.
-func (*QueueClearRequest) Descriptor() ([]byte, []int) {
-	return file_proto_queue_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use QueueExtendRequest.ProtoReflect.Descriptor instead.
+func (*QueueExtendRequest) Descriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *QueueClearRequest) GetQueueName() string {
+func (x *QueueExtendRequest) GetQueueName() string {
 	if x != nil {
 		return x.QueueName
 	}
 	return ""
 }
 
-func (x *QueueClearRequest) GetDefer() bool {
-	if x != nil {
-		return x.Defer
-	}
-	return false
-}
-
-func (x *QueueClearRequest) GetScheduled() bool {
+func (x *QueueExtendRequest) GetRequestTimeout() string {
 	if x != nil {
-		return x.Scheduled
+		return x.RequestTimeout
 	}
-	return false
+	return ""
 }
 
-func (x *QueueClearRequest) GetQueue() bool {
+func (x *QueueExtendRequest) GetReserveTimeout() string {
 	if x != nil {
-		return x.Queue
+		return x.ReserveTimeout
 	}
-	return false
+	return ""
 }
 
-func (x *QueueClearRequest) GetDestructive() bool {
+func (x *QueueExtendRequest) GetIds() []string {
 	if x != nil {
-		return x.Destructive
+		return x.Ids
 	}
-	return false
+	return nil
 }
 
-type QueueStatsRequest struct {
+type QueueExtendResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	QueueName string `protobuf:"bytes,1,opt,name=queueName,json=queue_name,proto3" json:"queueName,omitempty"`
+	// The earliest new date time that Querator will offer up one of the extended items to another
+	// consumer if the consumer has not marked them complete. If reserve_timeout was not provided, items
+	// produced with their own reserve_timeout are extended by that timeout instead.
+	ReserveDeadline *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=reserveDeadline,json=reserve_deadline,proto3" json:"reserveDeadline,omitempty"` // TODO: OpenAPI
 }
 
-func (x *QueueStatsRequest) Reset() {
-	*x = QueueStatsRequest{}
+func (x *QueueExtendResponse) Reset() {
+	*x = QueueExtendResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_queue_proto_msgTypes[10]
+		mi := &file_proto_queue_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *QueueStatsRequest) String() string {
+func (x *QueueExtendResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*QueueStatsRequest) ProtoMessage() {}
+func (*QueueExtendResponse) ProtoMessage() {}
 
-func (x *QueueStatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_queue_proto_msgTypes[10]
+func (x *QueueExtendResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_queue_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -891,35 +1033,1224 @@ func (x *QueueStatsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use QueueStatsRequest.ProtoReflect.Descriptor instead.
-func (*QueueStatsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_queue_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use QueueExtendResponse.ProtoReflect.Descriptor instead.
+func (*QueueExtendResponse) Descriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *QueueStatsRequest) GetQueueName() string {
+func (x *QueueExtendResponse) GetReserveDeadline() *timestamppb.Timestamp {
 	if x != nil {
-		return x.QueueName
+		return x.ReserveDeadline
 	}
-	return ""
+	return nil
 }
 
-type QueueStatsResponse struct {
+type QueuePeekRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Total is the number of items in the queue
-	Total int32 `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
-	// TotalReserved is the number of items in the queue that are in reserved state
-	TotalReserved int32 `protobuf:"varint,2,opt,name=TotalReserved,json=total_reserved,proto3" json:"TotalReserved,omitempty"`
-	// AverageAge is the average age of all items in the queue
-	AverageAge string `protobuf:"bytes,3,opt,name=AverageAge,json=average_age,proto3" json:"AverageAge,omitempty"`
-	// AverageReservedAge is the average age of reserved items in the queue
-	AverageReservedAge string `protobuf:"bytes,4,opt,name=AverageReservedAge,json=average_reserved_age,proto3" json:"AverageReservedAge,omitempty"`
-	// ProduceWaiting is the number of `/queue.produce` requests currently waiting
-	// to be processed by the sync loop
-	ProduceWaiting int32 `protobuf:"varint,5,opt,name=ProduceWaiting,json=produce_waiting,proto3" json:"ProduceWaiting,omitempty"`
-	// ReserveWaiting is the number of `/queue.reserve` requests currently waiting
+	QueueName string `protobuf:"bytes,1,opt,name=queueName,json=queue_name,proto3" json:"queueName,omitempty"`
+	// The maximum number of items to return, cannot be greater than the max_reserve_batch_size
+	BatchSize int32 `protobuf:"varint,2,opt,name=batchSize,json=batch_size,proto3" json:"batchSize,omitempty"`
+}
+
+func (x *QueuePeekRequest) Reset() {
+	*x = QueuePeekRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_queue_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueuePeekRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueuePeekRequest) ProtoMessage() {}
+
+func (x *QueuePeekRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_queue_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueuePeekRequest.ProtoReflect.Descriptor instead.
+func (*QueuePeekRequest) Descriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *QueuePeekRequest) GetQueueName() string {
+	if x != nil {
+		return x.QueueName
+	}
+	return ""
+}
+
+func (x *QueuePeekRequest) GetBatchSize() int32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+type QueuePeekResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The next items that would be reserved, in the order they would be reserved
+	Items []*QueueItem `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+}
+
+func (x *QueuePeekResponse) Reset() {
+	*x = QueuePeekResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_queue_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueuePeekResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueuePeekResponse) ProtoMessage() {}
+
+func (x *QueuePeekResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_queue_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueuePeekResponse.ProtoReflect.Descriptor instead.
+func (*QueuePeekResponse) Descriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *QueuePeekResponse) GetItems() []*QueueItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type QueueGetRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	QueueName string `protobuf:"bytes,1,opt,name=queueName,json=queue_name,proto3" json:"queueName,omitempty"`
+	// The ids of the items to return, cannot be more than the max_complete_batch_size
+	Ids []string `protobuf:"bytes,2,rep,name=ids,proto3" json:"ids,omitempty"`
+}
+
+func (x *QueueGetRequest) Reset() {
+	*x = QueueGetRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_queue_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueueGetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueGetRequest) ProtoMessage() {}
+
+func (x *QueueGetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_queue_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueGetRequest.ProtoReflect.Descriptor instead.
+func (*QueueGetRequest) Descriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *QueueGetRequest) GetQueueName() string {
+	if x != nil {
+		return x.QueueName
+	}
+	return ""
+}
+
+func (x *QueueGetRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type QueueGetResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The items requested in the same order as the ids requested
+	Items []*QueueItem `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+}
+
+func (x *QueueGetResponse) Reset() {
+	*x = QueueGetResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_queue_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueueGetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueGetResponse) ProtoMessage() {}
+
+func (x *QueueGetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_queue_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueGetResponse.ProtoReflect.Descriptor instead.
+func (*QueueGetResponse) Descriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *QueueGetResponse) GetItems() []*QueueItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+// QueueItem is an item in the queue as returned by '/queue.peek' and '/queue.get'. Retrieving
+// an item this way does not reserve the item.
+type QueueItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// A unique id which identifies an item in a queue
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// A user specified field which indicates the encoding the user used to encode the 'payload'
+	Encoding string `protobuf:"bytes,2,opt,name=encoding,proto3" json:"encoding,omitempty"`
+	// A Kind or Type the payload contains
+	Kind string `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"`
+	// A user specified field that can be used to determine handling of the item
+	Reference string `protobuf:"bytes,4,opt,name=reference,proto3" json:"reference,omitempty"`
+	// The payload of the item as an array of raw bytes
+	Bytes []byte `protobuf:"bytes,5,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	// The number of times this item has been attempted
+	Attempts int32 `protobuf:"varint,6,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	// The maximum number of attempts to process this item
+	MaxAttempts int32 `protobuf:"varint,7,opt,name=maxAttempts,json=max_attempts,proto3" json:"maxAttempts,omitempty"`
+	// The priority level the item was produced with
+	Priority int32 `protobuf:"varint,8,opt,name=priority,proto3" json:"priority,omitempty"`
+	// The ordering key the item was produced with
+	OrderingKey string `protobuf:"bytes,9,opt,name=orderingKey,json=ordering_key,proto3" json:"orderingKey,omitempty"`
+	// True if the item is currently reserved by a consumer
+	IsReserved bool `protobuf:"varint,10,opt,name=isReserved,json=is_reserved,proto3" json:"isReserved,omitempty"`
+	// If reserved, the date time the reservation expires
+	ReserveDeadline *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=reserveDeadline,json=reserve_deadline,proto3" json:"reserveDeadline,omitempty"`
+	// The date time the item is considered dead
+	DeadDeadline *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=deadDeadline,json=dead_deadline,proto3" json:"deadDeadline,omitempty"`
+	// The date time the item was produced
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=createdAt,json=created_at,proto3" json:"createdAt,omitempty"`
+	// The date time the item expires, if the item has an expiry
+	ExpireDeadline *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=expireDeadline,json=expire_deadline,proto3" json:"expireDeadline,omitempty"`
+}
+
+func (x *QueueItem) Reset() {
+	*x = QueueItem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_queue_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueueItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueItem) ProtoMessage() {}
+
+func (x *QueueItem) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_queue_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueItem.ProtoReflect.Descriptor instead.
+func (*QueueItem) Descriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *QueueItem) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *QueueItem) GetEncoding() string {
+	if x != nil {
+		return x.Encoding
+	}
+	return ""
+}
+
+func (x *QueueItem) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *QueueItem) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+func (x *QueueItem) GetBytes() []byte {
+	if x != nil {
+		return x.Bytes
+	}
+	return nil
+}
+
+func (x *QueueItem) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *QueueItem) GetMaxAttempts() int32 {
+	if x != nil {
+		return x.MaxAttempts
+	}
+	return 0
+}
+
+func (x *QueueItem) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *QueueItem) GetOrderingKey() string {
+	if x != nil {
+		return x.OrderingKey
+	}
+	return ""
+}
+
+func (x *QueueItem) GetIsReserved() bool {
+	if x != nil {
+		return x.IsReserved
+	}
+	return false
+}
+
+func (x *QueueItem) GetReserveDeadline() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ReserveDeadline
+	}
+	return nil
+}
+
+func (x *QueueItem) GetDeadDeadline() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeadDeadline
+	}
+	return nil
+}
+
+func (x *QueueItem) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *QueueItem) GetExpireDeadline() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpireDeadline
+	}
+	return nil
+}
+
+type QueueRegisterRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	QueueName string `protobuf:"bytes,1,opt,name=queueName,json=queue_name,proto3" json:"queueName,omitempty"`
+	// The id of the client, the same id the client provides to '/queue.reserve'
+	ClientId string `protobuf:"bytes,2,opt,name=clientId,json=client_id,proto3" json:"clientId,omitempty"`
+	// How long the consumer can go without a heartbeat or reserve request before it is considered
+	// gone and its reserved items are released. Defaults to 30s, cannot be greater than 15m
+	HeartbeatTimeout string `protobuf:"bytes,3,opt,name=heartbeatTimeout,json=heartbeat_timeout,proto3" json:"heartbeatTimeout,omitempty"`
+}
+
+func (x *QueueRegisterRequest) Reset() {
+	*x = QueueRegisterRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_queue_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueueRegisterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueRegisterRequest) ProtoMessage() {}
+
+func (x *QueueRegisterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_queue_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueRegisterRequest.ProtoReflect.Descriptor instead.
+func (*QueueRegisterRequest) Descriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *QueueRegisterRequest) GetQueueName() string {
+	if x != nil {
+		return x.QueueName
+	}
+	return ""
+}
+
+func (x *QueueRegisterRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *QueueRegisterRequest) GetHeartbeatTimeout() string {
+	if x != nil {
+		return x.HeartbeatTimeout
+	}
+	return ""
+}
+
+type QueueHeartbeatRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	QueueName string `protobuf:"bytes,1,opt,name=queueName,json=queue_name,proto3" json:"queueName,omitempty"`
+	// The id the client registered with
+	ClientId string `protobuf:"bytes,2,opt,name=clientId,json=client_id,proto3" json:"clientId,omitempty"`
+}
+
+func (x *QueueHeartbeatRequest) Reset() {
+	*x = QueueHeartbeatRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_queue_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueueHeartbeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueHeartbeatRequest) ProtoMessage() {}
+
+func (x *QueueHeartbeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_queue_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueHeartbeatRequest.ProtoReflect.Descriptor instead.
+func (*QueueHeartbeatRequest) Descriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *QueueHeartbeatRequest) GetQueueName() string {
+	if x != nil {
+		return x.QueueName
+	}
+	return ""
+}
+
+func (x *QueueHeartbeatRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+// QueueHeartbeatResponse is returned by '/queue.register' and '/queue.heartbeat'
+type QueueHeartbeatResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The date time by which the consumer must send the next heartbeat or reserve request
+	HeartbeatDeadline *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=heartbeatDeadline,json=heartbeat_deadline,proto3" json:"heartbeatDeadline,omitempty"`
+}
+
+func (x *QueueHeartbeatResponse) Reset() {
+	*x = QueueHeartbeatResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_queue_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueueHeartbeatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueHeartbeatResponse) ProtoMessage() {}
+
+func (x *QueueHeartbeatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_queue_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueHeartbeatResponse.ProtoReflect.Descriptor instead.
+func (*QueueHeartbeatResponse) Descriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *QueueHeartbeatResponse) GetHeartbeatDeadline() *timestamppb.Timestamp {
+	if x != nil {
+		return x.HeartbeatDeadline
+	}
+	return nil
+}
+
+type QueueDeregisterRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	QueueName string `protobuf:"bytes,1,opt,name=queueName,json=queue_name,proto3" json:"queueName,omitempty"`
+	// The id the client registered with
+	ClientId string `protobuf:"bytes,2,opt,name=clientId,json=client_id,proto3" json:"clientId,omitempty"`
+}
+
+func (x *QueueDeregisterRequest) Reset() {
+	*x = QueueDeregisterRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_queue_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueueDeregisterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueDeregisterRequest) ProtoMessage() {}
+
+func (x *QueueDeregisterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_queue_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueDeregisterRequest.ProtoReflect.Descriptor instead.
+func (*QueueDeregisterRequest) Descriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *QueueDeregisterRequest) GetQueueName() string {
+	if x != nil {
+		return x.QueueName
+	}
+	return ""
+}
+
+func (x *QueueDeregisterRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+type QueueConsumersRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	QueueName string `protobuf:"bytes,1,opt,name=queueName,json=queue_name,proto3" json:"queueName,omitempty"`
+}
+
+func (x *QueueConsumersRequest) Reset() {
+	*x = QueueConsumersRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_queue_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueueConsumersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueConsumersRequest) ProtoMessage() {}
+
+func (x *QueueConsumersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_queue_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueConsumersRequest.ProtoReflect.Descriptor instead.
+func (*QueueConsumersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *QueueConsumersRequest) GetQueueName() string {
+	if x != nil {
+		return x.QueueName
+	}
+	return ""
+}
+
+type QueueConsumersResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The consumers registered with the queue ordered by client id
+	Consumers []*QueueConsumer `protobuf:"bytes,1,rep,name=consumers,proto3" json:"consumers,omitempty"`
+}
+
+func (x *QueueConsumersResponse) Reset() {
+	*x = QueueConsumersResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_queue_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueueConsumersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueConsumersResponse) ProtoMessage() {}
+
+func (x *QueueConsumersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_queue_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueConsumersResponse.ProtoReflect.Descriptor instead.
+func (*QueueConsumersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *QueueConsumersResponse) GetConsumers() []*QueueConsumer {
+	if x != nil {
+		return x.Consumers
+	}
+	return nil
+}
+
+type QueueConsumer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The id the client registered with
+	ClientId string `protobuf:"bytes,1,opt,name=clientId,json=client_id,proto3" json:"clientId,omitempty"`
+	// How long the consumer can go without a heartbeat
+	HeartbeatTimeout string `protobuf:"bytes,2,opt,name=heartbeatTimeout,json=heartbeat_timeout,proto3" json:"heartbeatTimeout,omitempty"`
+	// The date time the consumer registered
+	RegisteredAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=registeredAt,json=registered_at,proto3" json:"registeredAt,omitempty"`
+	// The date time of the last register, heartbeat or reserve request from the consumer
+	LastActivity *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=lastActivity,json=last_activity,proto3" json:"lastActivity,omitempty"`
+	// The date time after which the consumer is considered gone
+	HeartbeatDeadline *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=heartbeatDeadline,json=heartbeat_deadline,proto3" json:"heartbeatDeadline,omitempty"`
+	// The number of items reserved by the consumer which have not been completed or released
+	Reserved int32 `protobuf:"varint,6,opt,name=reserved,proto3" json:"reserved,omitempty"`
+}
+
+func (x *QueueConsumer) Reset() {
+	*x = QueueConsumer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_queue_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueueConsumer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueConsumer) ProtoMessage() {}
+
+func (x *QueueConsumer) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_queue_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueConsumer.ProtoReflect.Descriptor instead.
+func (*QueueConsumer) Descriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *QueueConsumer) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *QueueConsumer) GetHeartbeatTimeout() string {
+	if x != nil {
+		return x.HeartbeatTimeout
+	}
+	return ""
+}
+
+func (x *QueueConsumer) GetRegisteredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RegisteredAt
+	}
+	return nil
+}
+
+func (x *QueueConsumer) GetLastActivity() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastActivity
+	}
+	return nil
+}
+
+func (x *QueueConsumer) GetHeartbeatDeadline() *timestamppb.Timestamp {
+	if x != nil {
+		return x.HeartbeatDeadline
+	}
+	return nil
+}
+
+func (x *QueueConsumer) GetReserved() int32 {
+	if x != nil {
+		return x.Reserved
+	}
+	return 0
+}
+
+type QueueReleaseRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	QueueName string `protobuf:"bytes,1,opt,name=queueName,json=queue_name,proto3" json:"queueName,omitempty"`
+	// The duration the client expects to wait for the items to be released before timing out.
+	// Maximum timeout duration is 15 minutes
+	// Example: '5m', '10s'
+	RequestTimeout string `protobuf:"bytes,2,opt,name=requestTimeout,json=request_timeout,proto3" json:"requestTimeout,omitempty"` // TODO: OpenAPI
+	// A list of reserved ids to release
+	Ids []string `protobuf:"bytes,3,rep,name=ids,proto3" json:"ids,omitempty"`
+	// If true, the release is not counted as an attempt to process the items. Useful when a consumer
+	// releases items it has not attempted to process, for instance during a local resource shortage.
+	SkipAttempt bool `protobuf:"varint,4,opt,name=skipAttempt,json=skip_attempt,proto3" json:"skipAttempt,omitempty"` // TODO: OpenAPI
+}
+
+func (x *QueueReleaseRequest) Reset() {
+	*x = QueueReleaseRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_queue_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueueReleaseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueReleaseRequest) ProtoMessage() {}
+
+func (x *QueueReleaseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_queue_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueReleaseRequest.ProtoReflect.Descriptor instead.
+func (*QueueReleaseRequest) Descriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *QueueReleaseRequest) GetQueueName() string {
+	if x != nil {
+		return x.QueueName
+	}
+	return ""
+}
+
+func (x *QueueReleaseRequest) GetRequestTimeout() string {
+	if x != nil {
+		return x.RequestTimeout
+	}
+	return ""
+}
+
+func (x *QueueReleaseRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *QueueReleaseRequest) GetSkipAttempt() bool {
+	if x != nil {
+		return x.SkipAttempt
+	}
+	return false
+}
+
+type QueueInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The name of the queue
+	QueueName string `protobuf:"bytes,1,opt,name=queueName,json=queue_name,proto3" json:"queueName,omitempty"`
+	// The date the queue was created
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=createdAt,json=created_at,proto3" json:"createdAt,omitempty"`
+	// The date the queue was last updated
+	UpdatedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=updatedAt,json=updated_at,proto3" json:"updatedAt,omitempty"`
+	// The name of the dead letter queue for this queue. If this is a dead letter queue then
+	// this field will be empty when retrieved via '/queue.list'
+	DeadQueue string `protobuf:"bytes,4,opt,name=deadQueue,json=dead_queue,proto3" json:"deadQueue,omitempty"`
+	// This is a user supplied field which could contain metadata or specify who owns this queue
+	// Examples: "jake@statefarm.com", "stapler@office-space.com", "account-0001"
+	Reference string `protobuf:"bytes,5,opt,name=reference,proto3" json:"reference,omitempty"`
+	// The reservation timeout for this queue.
+	// Example: '60m', '24h', '10s'
+	ReserveTimeout string `protobuf:"bytes,6,opt,name=reserveTimeout,json=reserve_timeout,proto3" json:"reserveTimeout,omitempty"`
+	// How long the item can wait in the queue regardless of attempts before it is moved
+	// to the dead letter queue. Example: '24h', '60m', '10s'
+	DeadTimeout string `protobuf:"bytes,7,opt,name=deadTimeout,json=dead_timeout,proto3" json:"deadTimeout,omitempty"`
+	// The maximum number of times this message can be deferred by a consumer before it is
+	// placed in the dead letter queue.
+	MaxAttempts int32 `protobuf:"varint,8,opt,name=maxAttempts,json=max_attempts,proto3" json:"maxAttempts,omitempty"`
+	// The number of partitions the queue is requesting. This might be different than the
+	// actual number of partitions if the partition count was recently changed.
+	Partitions int32 `protobuf:"varint,9,opt,name=partitions,proto3" json:"partitions,omitempty"`
+	// The namespace the queue belongs too. Namespaces are selected by the client via the
+	// `Querator-Namespace` header, as such this field is ignored when creating or updating
+	// a queue and is only populated when retrieved via '/queues.list'
+	Namespace string `protobuf:"bytes,10,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// The maximum size in bytes of an item payload produced to this queue. Must not exceed
+	// the maximum item size configured for the service. If zero, the service maximum is used.
+	MaxItemSize int32 `protobuf:"varint,11,opt,name=maxItemSize,json=max_item_size,proto3" json:"maxItemSize,omitempty"`
+	// How long a dedup_id of a produced item is remembered. Items produced with the same dedup_id
+	// within this window are not added to the queue. If empty, the default of '5m' is used.
+	// Example: '5m', '1h'
+	DedupWindow string `protobuf:"bytes,12,opt,name=dedupWindow,json=dedup_window,proto3" json:"dedupWindow,omitempty"`
+	// The relative weight of each priority level, starting with priority 0. When items of more than
+	// one priority are waiting, each priority is reserved in proportion to its weight such that
+	// lower priorities are not starved. If empty or all zero, higher priorities are always reserved
+	// before lower priorities. Example: [1, 2, 4, 8]
+	PriorityWeights []int32 `protobuf:"varint,13,rep,packed,name=priorityWeights,json=priority_weights,proto3" json:"priorityWeights,omitempty"`
+	// How long an item is useful for after it is produced. Expired items are never reserved and are removed
+	// from the queue without being moved to the dead letter queue. If empty, items do not expire unless the
+	// item was produced with an expire_timeout. Example: '30s', '5m'
+	ExpireTimeout string `protobuf:"bytes,14,opt,name=expireTimeout,json=expire_timeout,proto3" json:"expireTimeout,omitempty"`
+}
+
+func (x *QueueInfo) Reset() {
+	*x = QueueInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_queue_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueueInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueInfo) ProtoMessage() {}
+
+func (x *QueueInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_queue_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueInfo.ProtoReflect.Descriptor instead.
+func (*QueueInfo) Descriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *QueueInfo) GetQueueName() string {
+	if x != nil {
+		return x.QueueName
+	}
+	return ""
+}
+
+func (x *QueueInfo) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *QueueInfo) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *QueueInfo) GetDeadQueue() string {
+	if x != nil {
+		return x.DeadQueue
+	}
+	return ""
+}
+
+func (x *QueueInfo) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+func (x *QueueInfo) GetReserveTimeout() string {
+	if x != nil {
+		return x.ReserveTimeout
+	}
+	return ""
+}
+
+func (x *QueueInfo) GetDeadTimeout() string {
+	if x != nil {
+		return x.DeadTimeout
+	}
+	return ""
+}
+
+func (x *QueueInfo) GetMaxAttempts() int32 {
+	if x != nil {
+		return x.MaxAttempts
+	}
+	return 0
+}
+
+func (x *QueueInfo) GetPartitions() int32 {
+	if x != nil {
+		return x.Partitions
+	}
+	return 0
+}
+
+func (x *QueueInfo) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *QueueInfo) GetMaxItemSize() int32 {
+	if x != nil {
+		return x.MaxItemSize
+	}
+	return 0
+}
+
+func (x *QueueInfo) GetDedupWindow() string {
+	if x != nil {
+		return x.DedupWindow
+	}
+	return ""
+}
+
+func (x *QueueInfo) GetPriorityWeights() []int32 {
+	if x != nil {
+		return x.PriorityWeights
+	}
+	return nil
+}
+
+func (x *QueueInfo) GetExpireTimeout() string {
+	if x != nil {
+		return x.ExpireTimeout
+	}
+	return ""
+}
+
+type QueueClearRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The name of the queue
+	QueueName string `protobuf:"bytes,1,opt,name=queueName,json=queue_name,proto3" json:"queueName,omitempty"`
+	// Defer indicates the 'defer' queue will be cleared. If true, any items
+	// scheduled to be retried at a future date will be removed.
+	Defer bool `protobuf:"varint,2,opt,name=defer,proto3" json:"defer,omitempty"` // TODO: Implement
+	// Scheduled indicates any 'scheduled' items in the queue will be
+	// cleared. If true, any items scheduled to be enqueued at a future date
+	// will be removed.
+	Scheduled bool `protobuf:"varint,3,opt,name=scheduled,proto3" json:"scheduled,omitempty"` // TODO: Implement
+	// Queue indicates any items currently waiting in the FIFO queue will
+	// clear. If true, any items in the queue which have NOT been reserved
+	// will be removed.
+	Queue bool `protobuf:"varint,4,opt,name=Queue,proto3" json:"Queue,omitempty"`
+	// Destructive indicates the Defer,Scheduled,Queue operations should be
+	// destructive in that all data regardless of status will be removed.
+	// For example, if used with ClearRequest.Queue = true, then ALL items
+	// in the queue regardless of reserve status will be removed. This means
+	// that clients who currently have ownership of those items will not be able
+	// to "complete" those items, as querator will have no knowledge of those items.
+	Destructive bool `protobuf:"varint,5,opt,name=destructive,proto3" json:"destructive,omitempty"`
+}
+
+func (x *QueueClearRequest) Reset() {
+	*x = QueueClearRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_queue_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueueClearRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueClearRequest) ProtoMessage() {}
+
+func (x *QueueClearRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_queue_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueClearRequest.ProtoReflect.Descriptor instead.
+func (*QueueClearRequest) Descriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *QueueClearRequest) GetQueueName() string {
+	if x != nil {
+		return x.QueueName
+	}
+	return ""
+}
+
+func (x *QueueClearRequest) GetDefer() bool {
+	if x != nil {
+		return x.Defer
+	}
+	return false
+}
+
+func (x *QueueClearRequest) GetScheduled() bool {
+	if x != nil {
+		return x.Scheduled
+	}
+	return false
+}
+
+func (x *QueueClearRequest) GetQueue() bool {
+	if x != nil {
+		return x.Queue
+	}
+	return false
+}
+
+func (x *QueueClearRequest) GetDestructive() bool {
+	if x != nil {
+		return x.Destructive
+	}
+	return false
+}
+
+type QueueStatsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	QueueName string `protobuf:"bytes,1,opt,name=queueName,json=queue_name,proto3" json:"queueName,omitempty"`
+}
+
+func (x *QueueStatsRequest) Reset() {
+	*x = QueueStatsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_queue_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueueStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueStatsRequest) ProtoMessage() {}
+
+func (x *QueueStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_queue_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueStatsRequest.ProtoReflect.Descriptor instead.
+func (*QueueStatsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_queue_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *QueueStatsRequest) GetQueueName() string {
+	if x != nil {
+		return x.QueueName
+	}
+	return ""
+}
+
+type QueueStatsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Total is the number of items in the queue
+	Total int32 `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	// TotalReserved is the number of items in the queue that are in reserved state
+	TotalReserved int32 `protobuf:"varint,2,opt,name=TotalReserved,json=total_reserved,proto3" json:"TotalReserved,omitempty"`
+	// AverageAge is the average age of all items in the queue
+	AverageAge string `protobuf:"bytes,3,opt,name=AverageAge,json=average_age,proto3" json:"AverageAge,omitempty"`
+	// AverageReservedAge is the average age of reserved items in the queue
+	AverageReservedAge string `protobuf:"bytes,4,opt,name=AverageReservedAge,json=average_reserved_age,proto3" json:"AverageReservedAge,omitempty"`
+	// ProduceWaiting is the number of `/queue.produce` requests currently waiting
+	// to be processed by the sync loop
+	ProduceWaiting int32 `protobuf:"varint,5,opt,name=ProduceWaiting,json=produce_waiting,proto3" json:"ProduceWaiting,omitempty"`
+	// ReserveWaiting is the number of `/queue.reserve` requests currently waiting
 	// to be processed by the sync loop
 	ReserveWaiting int32 `protobuf:"varint,6,opt,name=ReserveWaiting,json=reserve_waiting,proto3" json:"ReserveWaiting,omitempty"`
 	// CompleteWaiting is the number of `/queue.complete` requests currently waiting
@@ -929,12 +2260,18 @@ type QueueStatsResponse struct {
 	ReserveBlocked int32 `protobuf:"varint,8,opt,name=ReserveBlocked,json=reserve_blocked,proto3" json:"ReserveBlocked,omitempty"`
 	// InFlight is the number of requests currently in flight
 	InFlight int32 `protobuf:"varint,9,opt,name=InFlight,json=in_flight,proto3" json:"InFlight,omitempty"`
+	// TotalBytes is the sum of all the item payloads in the queue
+	TotalBytes int64 `protobuf:"varint,10,opt,name=TotalBytes,json=total_bytes,proto3" json:"TotalBytes,omitempty"`
+	// TotalExpired is the number of items removed from the queue because they expired since the
+	// partition was opened, including expired items which have not yet been removed. The count of removed
+	// items is held in memory only and resets when the service restarts.
+	TotalExpired int32 `protobuf:"varint,11,opt,name=TotalExpired,json=total_expired,proto3" json:"TotalExpired,omitempty"`
 }
 
 func (x *QueueStatsResponse) Reset() {
 	*x = QueueStatsResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_queue_proto_msgTypes[11]
+		mi := &file_proto_queue_proto_msgTypes[28]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -947,7 +2284,7 @@ func (x *QueueStatsResponse) String() string {
 func (*QueueStatsResponse) ProtoMessage() {}
 
 func (x *QueueStatsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_queue_proto_msgTypes[11]
+	mi := &file_proto_queue_proto_msgTypes[28]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -960,7 +2297,7 @@ func (x *QueueStatsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use QueueStatsResponse.ProtoReflect.Descriptor instead.
 func (*QueueStatsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_queue_proto_rawDescGZIP(), []int{11}
+	return file_proto_queue_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *QueueStatsResponse) GetTotal() int32 {
@@ -1026,6 +2363,20 @@ func (x *QueueStatsResponse) GetInFlight() int32 {
 	return 0
 }
 
+func (x *QueueStatsResponse) GetTotalBytes() int64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *QueueStatsResponse) GetTotalExpired() int32 {
+	if x != nil {
+		return x.TotalExpired
+	}
+	return 0
+}
+
 var File_proto_queue_proto protoreflect.FileDescriptor
 
 var file_proto_queue_proto_rawDesc = []byte{
@@ -1042,7 +2393,7 @@ var file_proto_queue_proto_rawDesc = []byte{
 	0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
 	0x71, 0x75, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x51, 0x75, 0x65, 0x75, 0x65, 0x50, 0x72,
 	0x6f, 0x64, 0x75, 0x63, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73,
-	0x22, 0x8a, 0x01, 0x0a, 0x10, 0x51, 0x75, 0x65, 0x75, 0x65, 0x50, 0x72, 0x6f, 0x64, 0x75, 0x63,
+	0x22, 0xfa, 0x02, 0x0a, 0x10, 0x51, 0x75, 0x65, 0x75, 0x65, 0x50, 0x72, 0x6f, 0x64, 0x75, 0x63,
 	0x65, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e,
 	0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e,
 	0x67, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
@@ -1050,7 +2401,22 @@ var file_proto_queue_proto_rawDesc = []byte{
 	0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65,
 	0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01,
 	0x28, 0x0c, 0x52, 0x05, 0x62, 0x79, 0x74, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x74, 0x66,
-	0x38, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x74, 0x66, 0x38, 0x22, 0x99, 0x01,
+	0x38, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x74, 0x66, 0x38, 0x12, 0x27, 0x0a,
+	0x0e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x5f, 0x74,
+	0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x21, 0x0a, 0x0b, 0x6d, 0x61, 0x78, 0x41, 0x74, 0x74,
+	0x65, 0x6d, 0x70, 0x74, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x6d, 0x61, 0x78,
+	0x5f, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x0b, 0x64, 0x65, 0x61,
+	0x64, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c,
+	0x64, 0x65, 0x61, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x19, 0x0a, 0x07,
+	0x64, 0x65, 0x64, 0x75, 0x70, 0x49, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64,
+	0x65, 0x64, 0x75, 0x70, 0x5f, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72,
+	0x69, 0x74, 0x79, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72,
+	0x69, 0x74, 0x79, 0x12, 0x21, 0x0a, 0x0b, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x4b,
+	0x65, 0x79, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x69,
+	0x6e, 0x67, 0x5f, 0x6b, 0x65, 0x79, 0x12, 0x25, 0x0a, 0x0d, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65,
+	0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x65,
+	0x78, 0x70, 0x69, 0x72, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x22, 0x99, 0x01,
 	0x0a, 0x13, 0x51, 0x75, 0x65, 0x75, 0x65, 0x52, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x52, 0x65,
 	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x09, 0x71, 0x75, 0x65, 0x75, 0x65, 0x4e, 0x61,
 	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x71, 0x75, 0x65, 0x75, 0x65, 0x5f,
@@ -1060,7 +2426,7 @@ var file_proto_queue_proto_rawDesc = []byte{
 	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64,
 	0x12, 0x27, 0x0a, 0x0e, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x6f,
 	0x75, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x22, 0xe9, 0x01, 0x0a, 0x10, 0x51, 0x75,
+	0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x22, 0xa8, 0x02, 0x0a, 0x10, 0x51, 0x75,
 	0x65, 0x75, 0x65, 0x52, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x1a,
 	0x0a, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
 	0x52, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x69,
@@ -1075,92 +2441,265 @@ var file_proto_queue_proto_rawDesc = []byte{
 	0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x10, 0x72,
 	0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x5f, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x12,
 	0x14, 0x0a, 0x05, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05,
-	0x62, 0x79, 0x74, 0x65, 0x73, 0x22, 0x48, 0x0a, 0x14, 0x51, 0x75, 0x65, 0x75, 0x65, 0x52, 0x65,
-	0x73, 0x65, 0x72, 0x76, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x30, 0x0a,
-	0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x71,
-	0x75, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x51, 0x75, 0x65, 0x75, 0x65, 0x52, 0x65, 0x73,
-	0x65, 0x72, 0x76, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x22,
-	0x43, 0x0a, 0x11, 0x51, 0x75, 0x65, 0x75, 0x65, 0x44, 0x65, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x51,
-	0x75, 0x65, 0x75, 0x65, 0x44, 0x65, 0x66, 0x65, 0x72, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69,
-	0x74, 0x65, 0x6d, 0x73, 0x22, 0x6b, 0x0a, 0x0e, 0x51, 0x75, 0x65, 0x75, 0x65, 0x44, 0x65, 0x66,
-	0x65, 0x72, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x35, 0x0a, 0x07, 0x6f, 0x66, 0x66, 0x65, 0x72, 0x41,
-	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
-	0x61, 0x6d, 0x70, 0x52, 0x08, 0x6f, 0x66, 0x66, 0x65, 0x72, 0x5f, 0x61, 0x74, 0x12, 0x12, 0x0a,
-	0x04, 0x64, 0x65, 0x61, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x64, 0x65, 0x61,
-	0x64, 0x22, 0x70, 0x0a, 0x14, 0x51, 0x75, 0x65, 0x75, 0x65, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65,
+	0x62, 0x79, 0x74, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74,
+	0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74,
+	0x79, 0x12, 0x21, 0x0a, 0x0b, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x4b, 0x65, 0x79,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x69, 0x6e, 0x67,
+	0x5f, 0x6b, 0x65, 0x79, 0x22, 0x48, 0x0a, 0x14, 0x51, 0x75, 0x65, 0x75, 0x65, 0x52, 0x65, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x30, 0x0a, 0x05,
+	0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x71, 0x75,
+	0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x51, 0x75, 0x65, 0x75, 0x65, 0x52, 0x65, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x22, 0x43,
+	0x0a, 0x11, 0x51, 0x75, 0x65, 0x75, 0x65, 0x44, 0x65, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x18, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x51, 0x75,
+	0x65, 0x75, 0x65, 0x44, 0x65, 0x66, 0x65, 0x72, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69, 0x74,
+	0x65, 0x6d, 0x73, 0x22, 0x6b, 0x0a, 0x0e, 0x51, 0x75, 0x65, 0x75, 0x65, 0x44, 0x65, 0x66, 0x65,
+	0x72, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x35, 0x0a, 0x07, 0x6f, 0x66, 0x66, 0x65, 0x72, 0x41, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x08, 0x6f, 0x66, 0x66, 0x65, 0x72, 0x5f, 0x61, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x64, 0x65, 0x61, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x64, 0x65, 0x61, 0x64,
+	0x22, 0x8c, 0x01, 0x0a, 0x14, 0x51, 0x75, 0x65, 0x75, 0x65, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65,
 	0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x09, 0x71, 0x75, 0x65,
 	0x75, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x71, 0x75,
 	0x65, 0x75, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x27, 0x0a, 0x0e, 0x72, 0x65, 0x71, 0x75,
 	0x65, 0x73, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
 	0x52, 0x0f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75,
 	0x74, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x03,
-	0x69, 0x64, 0x73, 0x22, 0xec, 0x02, 0x0a, 0x09, 0x51, 0x75, 0x65, 0x75, 0x65, 0x49, 0x6e, 0x66,
-	0x6f, 0x12, 0x1d, 0x0a, 0x09, 0x71, 0x75, 0x65, 0x75, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x71, 0x75, 0x65, 0x75, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
-	0x12, 0x39, 0x0a, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
-	0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x12, 0x39, 0x0a, 0x09, 0x75,
-	0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x69, 0x64, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x22,
+	0x71, 0x0a, 0x13, 0x51, 0x75, 0x65, 0x75, 0x65, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65,
+	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x30, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x61, 0x74, 0x6f,
+	0x72, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x22, 0x50, 0x0a, 0x15, 0x51, 0x75, 0x65, 0x75, 0x65, 0x43, 0x6f, 0x6d, 0x70, 0x6c,
+	0x65, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x07, 0x72,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x71,
+	0x75, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x51, 0x75, 0x65, 0x75, 0x65, 0x43, 0x6f, 0x6d,
+	0x70, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x73, 0x22, 0x97, 0x01, 0x0a, 0x12, 0x51, 0x75, 0x65, 0x75, 0x65, 0x45, 0x78,
+	0x74, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x09, 0x71,
+	0x75, 0x65, 0x75, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x71, 0x75, 0x65, 0x75, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x27, 0x0a, 0x0e, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65,
+	0x6f, 0x75, 0x74, 0x12, 0x27, 0x0a, 0x0e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x54, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x72, 0x65, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x10, 0x0a, 0x03,
+	0x69, 0x64, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x03, 0x69, 0x64, 0x73, 0x22, 0x5c,
+	0x0a, 0x13, 0x51, 0x75, 0x65, 0x75, 0x65, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x45, 0x0a, 0x0f, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x44, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
 	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
-	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x75, 0x70, 0x64, 0x61,
-	0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x12, 0x1d, 0x0a, 0x09, 0x64, 0x65, 0x61, 0x64, 0x51, 0x75,
-	0x65, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x64, 0x65, 0x61, 0x64, 0x5f,
-	0x71, 0x75, 0x65, 0x75, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e,
-	0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65,
-	0x6e, 0x63, 0x65, 0x12, 0x27, 0x0a, 0x0e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x54, 0x69,
-	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x72, 0x65, 0x73,
-	0x65, 0x72, 0x76, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x21, 0x0a, 0x0b,
-	0x64, 0x65, 0x61, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x0c, 0x64, 0x65, 0x61, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12,
-	0x21, 0x0a, 0x0b, 0x6d, 0x61, 0x78, 0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x18, 0x08,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x10, 0x72, 0x65, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x5f, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x22, 0x50, 0x0a, 0x10,
+	0x51, 0x75, 0x65, 0x75, 0x65, 0x50, 0x65, 0x65, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1d, 0x0a, 0x09, 0x71, 0x75, 0x65, 0x75, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0a, 0x71, 0x75, 0x65, 0x75, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x1d, 0x0a, 0x09, 0x62, 0x61, 0x74, 0x63, 0x68, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0a, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x22, 0x3e,
+	0x0a, 0x11, 0x51, 0x75, 0x65, 0x75, 0x65, 0x50, 0x65, 0x65, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x13, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x51, 0x75,
+	0x65, 0x75, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x22, 0x42,
+	0x0a, 0x0f, 0x51, 0x75, 0x65, 0x75, 0x65, 0x47, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x1d, 0x0a, 0x09, 0x71, 0x75, 0x65, 0x75, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x71, 0x75, 0x65, 0x75, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x12, 0x10, 0x0a, 0x03, 0x69, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x03, 0x69,
+	0x64, 0x73, 0x22, 0x3d, 0x0a, 0x10, 0x51, 0x75, 0x65, 0x75, 0x65, 0x47, 0x65, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72,
+	0x2e, 0x51, 0x75, 0x65, 0x75, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d,
+	0x73, 0x22, 0xa6, 0x04, 0x0a, 0x09, 0x51, 0x75, 0x65, 0x75, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x12,
+	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12,
+	0x1a, 0x0a, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x6b,
+	0x69, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12,
+	0x1c, 0x0a, 0x09, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a,
+	0x05, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x62, 0x79,
+	0x74, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x12,
+	0x21, 0x0a, 0x0b, 0x6d, 0x61, 0x78, 0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x18, 0x07,
 	0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x6d, 0x61, 0x78, 0x5f, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70,
-	0x74, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73,
-	0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f,
-	0x6e, 0x73, 0x22, 0x9e, 0x01, 0x0a, 0x11, 0x51, 0x75, 0x65, 0x75, 0x65, 0x43, 0x6c, 0x65, 0x61,
-	0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x09, 0x71, 0x75, 0x65, 0x75,
-	0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x71, 0x75, 0x65,
-	0x75, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x64, 0x65, 0x66, 0x65, 0x72,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x64, 0x65, 0x66, 0x65, 0x72, 0x12, 0x1c, 0x0a,
-	0x09, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08,
-	0x52, 0x09, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x51,
-	0x75, 0x65, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x51, 0x75, 0x65, 0x75,
-	0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x69, 0x76, 0x65,
-	0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74,
-	0x69, 0x76, 0x65, 0x22, 0x32, 0x0a, 0x11, 0x51, 0x75, 0x65, 0x75, 0x65, 0x53, 0x74, 0x61, 0x74,
-	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x09, 0x71, 0x75, 0x65, 0x75,
-	0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x71, 0x75, 0x65,
-	0x75, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0xe7, 0x02, 0x0a, 0x12, 0x51, 0x75, 0x65, 0x75,
-	0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14,
-	0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x74,
-	0x6f, 0x74, 0x61, 0x6c, 0x12, 0x25, 0x0a, 0x0d, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x52, 0x65, 0x73,
-	0x65, 0x72, 0x76, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x74, 0x6f, 0x74,
-	0x61, 0x6c, 0x5f, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x12, 0x1f, 0x0a, 0x0a, 0x41,
-	0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x41, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x0b, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x61, 0x67, 0x65, 0x12, 0x30, 0x0a, 0x12,
-	0x41, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x41,
-	0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67,
-	0x65, 0x5f, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x5f, 0x61, 0x67, 0x65, 0x12, 0x27,
-	0x0a, 0x0e, 0x50, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x65, 0x57, 0x61, 0x69, 0x74, 0x69, 0x6e, 0x67,
-	0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x65, 0x5f,
-	0x77, 0x61, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x27, 0x0a, 0x0e, 0x52, 0x65, 0x73, 0x65, 0x72,
-	0x76, 0x65, 0x57, 0x61, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52,
-	0x0f, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x5f, 0x77, 0x61, 0x69, 0x74, 0x69, 0x6e, 0x67,
-	0x12, 0x29, 0x0a, 0x0f, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x57, 0x61, 0x69, 0x74,
-	0x69, 0x6e, 0x67, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x10, 0x63, 0x6f, 0x6d, 0x70, 0x6c,
-	0x65, 0x74, 0x65, 0x5f, 0x77, 0x61, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x27, 0x0a, 0x0e, 0x52,
-	0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x18, 0x08, 0x20,
-	0x01, 0x28, 0x05, 0x52, 0x0f, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x5f, 0x62, 0x6c, 0x6f,
-	0x63, 0x6b, 0x65, 0x64, 0x12, 0x1b, 0x0a, 0x08, 0x49, 0x6e, 0x46, 0x6c, 0x69, 0x67, 0x68, 0x74,
-	0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x69, 0x6e, 0x5f, 0x66, 0x6c, 0x69, 0x67, 0x68,
-	0x74, 0x42, 0x26, 0x5a, 0x24, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
-	0x6b, 0x61, 0x70, 0x65, 0x74, 0x61, 0x6e, 0x2d, 0x69, 0x6f, 0x2f, 0x71, 0x75, 0x65, 0x72, 0x61,
-	0x74, 0x6f, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x33,
+	0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x21,
+	0x0a, 0x0b, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x4b, 0x65, 0x79, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0c, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x6b, 0x65,
+	0x79, 0x12, 0x1f, 0x0a, 0x0a, 0x69, 0x73, 0x52, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x69, 0x73, 0x5f, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x64, 0x12, 0x45, 0x0a, 0x0f, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x44, 0x65, 0x61,
+	0x64, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x10, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x5f, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x3f, 0x0a, 0x0c, 0x64, 0x65, 0x61,
+	0x64, 0x44, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0d, 0x64, 0x65, 0x61,
+	0x64, 0x5f, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x39, 0x0a, 0x09, 0x63, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x64, 0x5f, 0x61, 0x74, 0x12, 0x43, 0x0a, 0x0e, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x44,
+	0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0f, 0x65, 0x78, 0x70, 0x69, 0x72,
+	0x65, 0x5f, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x22, 0x7f, 0x0a, 0x14, 0x51, 0x75,
+	0x65, 0x75, 0x65, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1d, 0x0a, 0x09, 0x71, 0x75, 0x65, 0x75, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x71, 0x75, 0x65, 0x75, 0x65, 0x5f, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x1b, 0x0a, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x12, 0x2b,
+	0x0a, 0x10, 0x68, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x6f,
+	0x75, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x68, 0x65, 0x61, 0x72, 0x74, 0x62,
+	0x65, 0x61, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x22, 0x53, 0x0a, 0x15, 0x51,
+	0x75, 0x65, 0x75, 0x65, 0x48, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x09, 0x71, 0x75, 0x65, 0x75, 0x65, 0x4e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x71, 0x75, 0x65, 0x75, 0x65, 0x5f, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64,
+	0x22, 0x63, 0x0a, 0x16, 0x51, 0x75, 0x65, 0x75, 0x65, 0x48, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65,
+	0x61, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x49, 0x0a, 0x11, 0x68, 0x65,
+	0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x44, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x12, 0x68, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x5f, 0x64, 0x65, 0x61,
+	0x64, 0x6c, 0x69, 0x6e, 0x65, 0x22, 0x54, 0x0a, 0x16, 0x51, 0x75, 0x65, 0x75, 0x65, 0x44, 0x65,
+	0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1d, 0x0a, 0x09, 0x71, 0x75, 0x65, 0x75, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x71, 0x75, 0x65, 0x75, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1b,
+	0x0a, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x22, 0x36, 0x0a, 0x15, 0x51,
+	0x75, 0x65, 0x75, 0x65, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x09, 0x71, 0x75, 0x65, 0x75, 0x65, 0x4e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x71, 0x75, 0x65, 0x75, 0x65, 0x5f, 0x6e,
+	0x61, 0x6d, 0x65, 0x22, 0x4f, 0x0a, 0x16, 0x51, 0x75, 0x65, 0x75, 0x65, 0x43, 0x6f, 0x6e, 0x73,
+	0x75, 0x6d, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x35, 0x0a,
+	0x09, 0x63, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x17, 0x2e, 0x71, 0x75, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x51, 0x75, 0x65, 0x75,
+	0x65, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x72, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x73, 0x75,
+	0x6d, 0x65, 0x72, 0x73, 0x22, 0xc2, 0x02, 0x0a, 0x0d, 0x51, 0x75, 0x65, 0x75, 0x65, 0x43, 0x6f,
+	0x6e, 0x73, 0x75, 0x6d, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x5f, 0x69, 0x64, 0x12, 0x2b, 0x0a, 0x10, 0x68, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74,
+	0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x68,
+	0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74,
+	0x12, 0x3f, 0x0a, 0x0c, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x65, 0x64, 0x41, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x0d, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x65, 0x64, 0x5f, 0x61,
+	0x74, 0x12, 0x3f, 0x0a, 0x0c, 0x6c, 0x61, 0x73, 0x74, 0x41, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74,
+	0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x0d, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x61, 0x63, 0x74, 0x69, 0x76, 0x69,
+	0x74, 0x79, 0x12, 0x49, 0x0a, 0x11, 0x68, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x44,
+	0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x12, 0x68, 0x65, 0x61, 0x72, 0x74,
+	0x62, 0x65, 0x61, 0x74, 0x5f, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x08, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x22, 0x92, 0x01, 0x0a, 0x13, 0x51, 0x75,
+	0x65, 0x75, 0x65, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x1d, 0x0a, 0x09, 0x71, 0x75, 0x65, 0x75, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x71, 0x75, 0x65, 0x75, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x12, 0x27, 0x0a, 0x0e, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x6f,
+	0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x64, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x03, 0x69, 0x64, 0x73, 0x12, 0x21, 0x0a, 0x0b, 0x73,
+	0x6b, 0x69, 0x70, 0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0c, 0x73, 0x6b, 0x69, 0x70, 0x5f, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x22, 0xa3,
+	0x04, 0x0a, 0x09, 0x51, 0x75, 0x65, 0x75, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1d, 0x0a, 0x09,
+	0x71, 0x75, 0x65, 0x75, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0a, 0x71, 0x75, 0x65, 0x75, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x39, 0x0a, 0x09, 0x63,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x12, 0x39, 0x0a, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x64, 0x41, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61,
+	0x74, 0x12, 0x1d, 0x0a, 0x09, 0x64, 0x65, 0x61, 0x64, 0x51, 0x75, 0x65, 0x75, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x64, 0x65, 0x61, 0x64, 0x5f, 0x71, 0x75, 0x65, 0x75, 0x65,
+	0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x27,
+	0x0a, 0x0e, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x5f,
+	0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x21, 0x0a, 0x0b, 0x64, 0x65, 0x61, 0x64, 0x54,
+	0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x64, 0x65,
+	0x61, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x21, 0x0a, 0x0b, 0x6d, 0x61,
+	0x78, 0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0c, 0x6d, 0x61, 0x78, 0x5f, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x12, 0x1e, 0x0a,
+	0x0a, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0a, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1c, 0x0a,
+	0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x22, 0x0a, 0x0b, 0x6d,
+	0x61, 0x78, 0x49, 0x74, 0x65, 0x6d, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0d, 0x6d, 0x61, 0x78, 0x5f, 0x69, 0x74, 0x65, 0x6d, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x12,
+	0x21, 0x0a, 0x0b, 0x64, 0x65, 0x64, 0x75, 0x70, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x18, 0x0c,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x64, 0x65, 0x64, 0x75, 0x70, 0x5f, 0x77, 0x69, 0x6e, 0x64,
+	0x6f, 0x77, 0x12, 0x29, 0x0a, 0x0f, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x57, 0x65,
+	0x69, 0x67, 0x68, 0x74, 0x73, 0x18, 0x0d, 0x20, 0x03, 0x28, 0x05, 0x52, 0x10, 0x70, 0x72, 0x69,
+	0x6f, 0x72, 0x69, 0x74, 0x79, 0x5f, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x12, 0x25, 0x0a,
+	0x0d, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x0e,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x5f, 0x74, 0x69, 0x6d,
+	0x65, 0x6f, 0x75, 0x74, 0x22, 0x9e, 0x01, 0x0a, 0x11, 0x51, 0x75, 0x65, 0x75, 0x65, 0x43, 0x6c,
+	0x65, 0x61, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x09, 0x71, 0x75,
+	0x65, 0x75, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x71,
+	0x75, 0x65, 0x75, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x64, 0x65, 0x66,
+	0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x64, 0x65, 0x66, 0x65, 0x72, 0x12,
+	0x1c, 0x0a, 0x09, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x09, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x64, 0x12, 0x14, 0x0a,
+	0x05, 0x51, 0x75, 0x65, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x51, 0x75,
+	0x65, 0x75, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x69,
+	0x76, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x74, 0x72, 0x75,
+	0x63, 0x74, 0x69, 0x76, 0x65, 0x22, 0x32, 0x0a, 0x11, 0x51, 0x75, 0x65, 0x75, 0x65, 0x53, 0x74,
+	0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x09, 0x71, 0x75,
+	0x65, 0x75, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x71,
+	0x75, 0x65, 0x75, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0xad, 0x03, 0x0a, 0x12, 0x51, 0x75,
+	0x65, 0x75, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x25, 0x0a, 0x0d, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x52,
+	0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x12, 0x1f, 0x0a,
+	0x0a, 0x41, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x41, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x61, 0x67, 0x65, 0x12, 0x30,
+	0x0a, 0x12, 0x41, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x64, 0x41, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x14, 0x61, 0x76, 0x65, 0x72,
+	0x61, 0x67, 0x65, 0x5f, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x5f, 0x61, 0x67, 0x65,
+	0x12, 0x27, 0x0a, 0x0e, 0x50, 0x72, 0x6f, 0x64, 0x75, 0x63, 0x65, 0x57, 0x61, 0x69, 0x74, 0x69,
+	0x6e, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x70, 0x72, 0x6f, 0x64, 0x75, 0x63,
+	0x65, 0x5f, 0x77, 0x61, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x27, 0x0a, 0x0e, 0x52, 0x65, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x57, 0x61, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0f, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x5f, 0x77, 0x61, 0x69, 0x74, 0x69,
+	0x6e, 0x67, 0x12, 0x29, 0x0a, 0x0f, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x57, 0x61,
+	0x69, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x10, 0x63, 0x6f, 0x6d,
+	0x70, 0x6c, 0x65, 0x74, 0x65, 0x5f, 0x77, 0x61, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x27, 0x0a,
+	0x0e, 0x52, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x5f, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x12, 0x1b, 0x0a, 0x08, 0x49, 0x6e, 0x46, 0x6c, 0x69, 0x67,
+	0x68, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x69, 0x6e, 0x5f, 0x66, 0x6c, 0x69,
+	0x67, 0x68, 0x74, 0x12, 0x1f, 0x0a, 0x0a, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x42, 0x79, 0x74, 0x65,
+	0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x62,
+	0x79, 0x74, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x0c, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x45, 0x78, 0x70,
+	0x69, 0x72, 0x65, 0x64, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x74, 0x6f, 0x74, 0x61,
+	0x6c, 0x5f, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x64, 0x2a, 0x87, 0x01, 0x0a, 0x0e, 0x43, 0x6f,
+	0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x14,
+	0x43, 0x4f, 0x4d, 0x50, 0x4c, 0x45, 0x54, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49,
+	0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x43, 0x4f, 0x4d, 0x50, 0x4c, 0x45,
+	0x54, 0x45, 0x5f, 0x4f, 0x4b, 0x10, 0x01, 0x12, 0x16, 0x0a, 0x12, 0x43, 0x4f, 0x4d, 0x50, 0x4c,
+	0x45, 0x54, 0x45, 0x5f, 0x4e, 0x4f, 0x54, 0x5f, 0x46, 0x4f, 0x55, 0x4e, 0x44, 0x10, 0x02, 0x12,
+	0x19, 0x0a, 0x15, 0x43, 0x4f, 0x4d, 0x50, 0x4c, 0x45, 0x54, 0x45, 0x5f, 0x4e, 0x4f, 0x54, 0x5f,
+	0x52, 0x45, 0x53, 0x45, 0x52, 0x56, 0x45, 0x44, 0x10, 0x03, 0x12, 0x17, 0x0a, 0x13, 0x43, 0x4f,
+	0x4d, 0x50, 0x4c, 0x45, 0x54, 0x45, 0x5f, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x5f, 0x49,
+	0x44, 0x10, 0x04, 0x42, 0x26, 0x5a, 0x24, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x6b, 0x61, 0x70, 0x65, 0x74, 0x61, 0x6e, 0x2d, 0x69, 0x6f, 0x2f, 0x71, 0x75, 0x65,
+	0x72, 0x61, 0x74, 0x6f, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1175,35 +2714,68 @@ func file_proto_queue_proto_rawDescGZIP() []byte {
 	return file_proto_queue_proto_rawDescData
 }
 
-var file_proto_queue_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_proto_queue_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_queue_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
 var file_proto_queue_proto_goTypes = []interface{}{
-	(*QueueProduceRequest)(nil),   // 0: querator.QueueProduceRequest
-	(*QueueProduceItem)(nil),      // 1: querator.QueueProduceItem
-	(*QueueReserveRequest)(nil),   // 2: querator.QueueReserveRequest
-	(*QueueReserveItem)(nil),      // 3: querator.QueueReserveItem
-	(*QueueReserveResponse)(nil),  // 4: querator.QueueReserveResponse
-	(*QueueDeferRequest)(nil),     // 5: querator.QueueDeferRequest
-	(*QueueDeferItem)(nil),        // 6: querator.QueueDeferItem
-	(*QueueCompleteRequest)(nil),  // 7: querator.QueueCompleteRequest
-	(*QueueInfo)(nil),             // 8: querator.QueueInfo
-	(*QueueClearRequest)(nil),     // 9: querator.QueueClearRequest
-	(*QueueStatsRequest)(nil),     // 10: querator.QueueStatsRequest
-	(*QueueStatsResponse)(nil),    // 11: querator.QueueStatsResponse
-	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+	(CompleteStatus)(0),            // 0: querator.CompleteStatus
+	(*QueueProduceRequest)(nil),    // 1: querator.QueueProduceRequest
+	(*QueueProduceItem)(nil),       // 2: querator.QueueProduceItem
+	(*QueueReserveRequest)(nil),    // 3: querator.QueueReserveRequest
+	(*QueueReserveItem)(nil),       // 4: querator.QueueReserveItem
+	(*QueueReserveResponse)(nil),   // 5: querator.QueueReserveResponse
+	(*QueueDeferRequest)(nil),      // 6: querator.QueueDeferRequest
+	(*QueueDeferItem)(nil),         // 7: querator.QueueDeferItem
+	(*QueueCompleteRequest)(nil),   // 8: querator.QueueCompleteRequest
+	(*QueueCompleteResult)(nil),    // 9: querator.QueueCompleteResult
+	(*QueueCompleteResponse)(nil),  // 10: querator.QueueCompleteResponse
+	(*QueueExtendRequest)(nil),     // 11: querator.QueueExtendRequest
+	(*QueueExtendResponse)(nil),    // 12: querator.QueueExtendResponse
+	(*QueuePeekRequest)(nil),       // 13: querator.QueuePeekRequest
+	(*QueuePeekResponse)(nil),      // 14: querator.QueuePeekResponse
+	(*QueueGetRequest)(nil),        // 15: querator.QueueGetRequest
+	(*QueueGetResponse)(nil),       // 16: querator.QueueGetResponse
+	(*QueueItem)(nil),              // 17: querator.QueueItem
+	(*QueueRegisterRequest)(nil),   // 18: querator.QueueRegisterRequest
+	(*QueueHeartbeatRequest)(nil),  // 19: querator.QueueHeartbeatRequest
+	(*QueueHeartbeatResponse)(nil), // 20: querator.QueueHeartbeatResponse
+	(*QueueDeregisterRequest)(nil), // 21: querator.QueueDeregisterRequest
+	(*QueueConsumersRequest)(nil),  // 22: querator.QueueConsumersRequest
+	(*QueueConsumersResponse)(nil), // 23: querator.QueueConsumersResponse
+	(*QueueConsumer)(nil),          // 24: querator.QueueConsumer
+	(*QueueReleaseRequest)(nil),    // 25: querator.QueueReleaseRequest
+	(*QueueInfo)(nil),              // 26: querator.QueueInfo
+	(*QueueClearRequest)(nil),      // 27: querator.QueueClearRequest
+	(*QueueStatsRequest)(nil),      // 28: querator.QueueStatsRequest
+	(*QueueStatsResponse)(nil),     // 29: querator.QueueStatsResponse
+	(*timestamppb.Timestamp)(nil),  // 30: google.protobuf.Timestamp
 }
 var file_proto_queue_proto_depIdxs = []int32{
-	1,  // 0: querator.QueueProduceRequest.items:type_name -> querator.QueueProduceItem
-	12, // 1: querator.QueueReserveItem.reserveDeadline:type_name -> google.protobuf.Timestamp
-	3,  // 2: querator.QueueReserveResponse.items:type_name -> querator.QueueReserveItem
-	6,  // 3: querator.QueueDeferRequest.items:type_name -> querator.QueueDeferItem
-	12, // 4: querator.QueueDeferItem.offerAt:type_name -> google.protobuf.Timestamp
-	12, // 5: querator.QueueInfo.createdAt:type_name -> google.protobuf.Timestamp
-	12, // 6: querator.QueueInfo.updatedAt:type_name -> google.protobuf.Timestamp
-	7,  // [7:7] is the sub-list for method output_type
-	7,  // [7:7] is the sub-list for method input_type
-	7,  // [7:7] is the sub-list for extension type_name
-	7,  // [7:7] is the sub-list for extension extendee
-	0,  // [0:7] is the sub-list for field type_name
+	2,  // 0: querator.QueueProduceRequest.items:type_name -> querator.QueueProduceItem
+	30, // 1: querator.QueueReserveItem.reserveDeadline:type_name -> google.protobuf.Timestamp
+	4,  // 2: querator.QueueReserveResponse.items:type_name -> querator.QueueReserveItem
+	7,  // 3: querator.QueueDeferRequest.items:type_name -> querator.QueueDeferItem
+	30, // 4: querator.QueueDeferItem.offerAt:type_name -> google.protobuf.Timestamp
+	0,  // 5: querator.QueueCompleteResult.status:type_name -> querator.CompleteStatus
+	9,  // 6: querator.QueueCompleteResponse.results:type_name -> querator.QueueCompleteResult
+	30, // 7: querator.QueueExtendResponse.reserveDeadline:type_name -> google.protobuf.Timestamp
+	17, // 8: querator.QueuePeekResponse.items:type_name -> querator.QueueItem
+	17, // 9: querator.QueueGetResponse.items:type_name -> querator.QueueItem
+	30, // 10: querator.QueueItem.reserveDeadline:type_name -> google.protobuf.Timestamp
+	30, // 11: querator.QueueItem.deadDeadline:type_name -> google.protobuf.Timestamp
+	30, // 12: querator.QueueItem.createdAt:type_name -> google.protobuf.Timestamp
+	30, // 13: querator.QueueItem.expireDeadline:type_name -> google.protobuf.Timestamp
+	30, // 14: querator.QueueHeartbeatResponse.heartbeatDeadline:type_name -> google.protobuf.Timestamp
+	24, // 15: querator.QueueConsumersResponse.consumers:type_name -> querator.QueueConsumer
+	30, // 16: querator.QueueConsumer.registeredAt:type_name -> google.protobuf.Timestamp
+	30, // 17: querator.QueueConsumer.lastActivity:type_name -> google.protobuf.Timestamp
+	30, // 18: querator.QueueConsumer.heartbeatDeadline:type_name -> google.protobuf.Timestamp
+	30, // 19: querator.QueueInfo.createdAt:type_name -> google.protobuf.Timestamp
+	30, // 20: querator.QueueInfo.updatedAt:type_name -> google.protobuf.Timestamp
+	21, // [21:21] is the sub-list for method output_type
+	21, // [21:21] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
 }
 
 func init() { file_proto_queue_proto_init() }
@@ -1309,7 +2881,7 @@ func file_proto_queue_proto_init() {
 			}
 		}
 		file_proto_queue_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*QueueInfo); i {
+			switch v := v.(*QueueCompleteResult); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1321,7 +2893,7 @@ func file_proto_queue_proto_init() {
 			}
 		}
 		file_proto_queue_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*QueueClearRequest); i {
+			switch v := v.(*QueueCompleteResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1333,7 +2905,7 @@ func file_proto_queue_proto_init() {
 			}
 		}
 		file_proto_queue_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*QueueStatsRequest); i {
+			switch v := v.(*QueueExtendRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1345,6 +2917,210 @@ func file_proto_queue_proto_init() {
 			}
 		}
 		file_proto_queue_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueueExtendResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_queue_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueuePeekRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_queue_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueuePeekResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_queue_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueueGetRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_queue_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueueGetResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_queue_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueueItem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_queue_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueueRegisterRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_queue_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueueHeartbeatRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_queue_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueueHeartbeatResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_queue_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueueDeregisterRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_queue_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueueConsumersRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_queue_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueueConsumersResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_queue_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueueConsumer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_queue_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueueReleaseRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_queue_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueueInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_queue_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueueClearRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_queue_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueueStatsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_queue_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*QueueStatsResponse); i {
 			case 0:
 				return &v.state
@@ -1362,13 +3138,14 @@ func file_proto_queue_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_proto_queue_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   12,
+			NumEnums:      1,
+			NumMessages:   29,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_proto_queue_proto_goTypes,
 		DependencyIndexes: file_proto_queue_proto_depIdxs,
+		EnumInfos:         file_proto_queue_proto_enumTypes,
 		MessageInfos:      file_proto_queue_proto_msgTypes,
 	}.Build()
 	File_proto_queue_proto = out.File
@@ -217,7 +217,85 @@ func testQueue(t *testing.T, setup NewStorageFunc, tearDown func()) {
 			// TODO: Reserve and defer one of the items multiple clocks until we exhaust the MaxAttempts,
 			//  then assert item was deleted.
 		})
-		t.Run("ReserveTimeout", func(t *testing.T) {})
+		t.Run("ReserveTimeout", func(t *testing.T) {
+			// Use a separate queue such that reserving items doesn't affect the following tests
+			itemQueue := random.String("queue-", 10)
+			require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+				DeadTimeout:    "20h0m0s",
+				QueueName:      itemQueue,
+				ReserveTimeout: "1m0s",
+				MaxAttempts:    256,
+				Partitions:     1,
+			}))
+
+			now := clock.Now().UTC()
+			require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+				QueueName:      itemQueue,
+				RequestTimeout: "1m",
+				Items: []*pb.QueueProduceItem{
+					{
+						Utf8:           "Are you feeling all right?",
+						ReserveTimeout: "5m",
+						DeadTimeout:    "2h",
+						MaxAttempts:    10,
+					},
+					{
+						Utf8: "I'm just feeling a little... under the weather",
+					},
+				}}))
+
+			var list pb.StorageQueueListResponse
+			require.NoError(t, c.StorageQueueList(ctx, itemQueue, &list, &que.ListOptions{Limit: 20}))
+			require.Equal(t, 2, len(list.Items))
+			assert.Equal(t, "5m0s", list.Items[0].ReserveTimeout)
+			assert.Equal(t, int32(10), list.Items[0].MaxAttempts)
+			assert.True(t, list.Items[0].DeadDeadline.AsTime().After(now.Add(2*clock.Hour).Add(-clock.Second)))
+			assert.True(t, list.Items[0].DeadDeadline.AsTime().Before(clock.Now().UTC().Add(2*clock.Hour).Add(clock.Second)))
+			assert.Equal(t, "", list.Items[1].ReserveTimeout)
+			assert.Equal(t, int32(256), list.Items[1].MaxAttempts)
+			assert.True(t, list.Items[1].DeadDeadline.AsTime().After(now.Add(20*clock.Hour).Add(-clock.Second)))
+
+			now = clock.Now().UTC()
+			var reserve pb.QueueReserveResponse
+			require.NoError(t, c.QueueReserve(ctx, &pb.QueueReserveRequest{
+				ClientId:       random.String("client-", 10),
+				QueueName:      itemQueue,
+				RequestTimeout: "1m",
+				BatchSize:      2,
+			}, &reserve))
+			require.Equal(t, 2, len(reserve.Items))
+			assertDeadline := func(t *testing.T, expected clock.Time, actual clock.Time) {
+				t.Helper()
+				assert.True(t, actual.After(expected.Add(-clock.Second)), "expected %s got %s", expected, actual)
+				assert.True(t, actual.Before(expected.Add(clock.Second)), "expected %s got %s", expected, actual)
+			}
+			assertDeadline(t, now.Add(5*clock.Minute), reserve.Items[0].ReserveDeadline.AsTime())
+			assertDeadline(t, now.Add(clock.Minute), reserve.Items[1].ReserveDeadline.AsTime())
+
+			// Extending without a reserve timeout uses the reserve timeout of the item
+			now = clock.Now().UTC()
+			require.NoError(t, c.QueueExtend(ctx, &pb.QueueExtendRequest{
+				QueueName:      itemQueue,
+				RequestTimeout: "1m",
+				Ids:            que.CollectIDs(reserve.Items),
+			}, &pb.QueueExtendResponse{}))
+
+			require.NoError(t, c.StorageQueueList(ctx, itemQueue, &list, &que.ListOptions{Limit: 20}))
+			require.Equal(t, 2, len(list.Items))
+			assertDeadline(t, now.Add(5*clock.Minute), list.Items[0].ReserveDeadline.AsTime())
+			assertDeadline(t, now.Add(clock.Minute), list.Items[1].ReserveDeadline.AsTime())
+
+			// Items cannot exceed the max attempts of the queue
+			err := c.QueueProduce(ctx, &pb.QueueProduceRequest{
+				QueueName:      itemQueue,
+				RequestTimeout: "1m",
+				Items:          []*pb.QueueProduceItem{{MaxAttempts: 257}},
+			})
+			var e duh.Error
+			require.True(t, errors.As(err, &e))
+			assert.Equal(t, "item max attempts is invalid; 257 cannot be greater than the queue max attempts 256", e.Message())
+			assert.Equal(t, duh.CodeBadRequest, e.Code())
+		})
 		t.Run("DeadTimeout", func(t *testing.T) {
 			// TODO: Fast Forward to the future, and ensure the item is removed after the dead clockout
 		})
@@ -541,6 +619,64 @@ func testQueue(t *testing.T, setup NewStorageFunc, tearDown func()) {
 		})
 	})
 
+	t.Run("MaxReserveSize", func(t *testing.T) {
+		_store := setup(clock.NewProvider())
+		defer tearDown()
+
+		var queueName = random.String("queue-", 10)
+		d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{
+			StorageConfig:  _store,
+			MaxReserveSize: 2_500,
+		})
+		defer d.Shutdown(t)
+
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			ReserveTimeout: ReserveTimeout,
+			DeadTimeout:    DeadTimeout,
+			QueueName:      queueName,
+			Partitions:     1,
+		}))
+
+		items := make([]*pb.QueueProduceItem, 0, 5)
+		for i := 0; i < 5; i++ {
+			items = append(items, &pb.QueueProduceItem{Bytes: make([]byte, 1_000)})
+		}
+		// An item larger than MaxReserveSize
+		items = append(items, &pb.QueueProduceItem{Bytes: make([]byte, 3_000)})
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          items,
+		}))
+
+		reserve := func() *pb.QueueReserveResponse {
+			var res pb.QueueReserveResponse
+			require.NoError(t, c.QueueReserve(ctx, &pb.QueueReserveRequest{
+				ClientId:       random.String("client-", 10),
+				QueueName:      queueName,
+				BatchSize:      10,
+				RequestTimeout: "1m",
+			}, &res))
+			return &res
+		}
+
+		// Only as many items as fit within MaxReserveSize are returned
+		assert.Equal(t, 2, len(reserve().Items))
+		assert.Equal(t, 2, len(reserve().Items))
+		assert.Equal(t, 1, len(reserve().Items))
+
+		// A single item larger than MaxReserveSize is always returned
+		res := reserve()
+		require.Equal(t, 1, len(res.Items))
+		assert.Equal(t, 3_000, len(res.Items[0].Bytes))
+
+		// Items which did not fit remain available
+		var stats pb.QueueStatsResponse
+		require.NoError(t, c.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: queueName}, &stats))
+		assert.Equal(t, int32(6), stats.Total)
+		assert.Equal(t, int32(6), stats.TotalReserved)
+	})
+
 	t.Run("Complete", func(t *testing.T) {
 		_store := setup(clock.NewProvider())
 		defer tearDown()
@@ -624,6 +760,86 @@ func testQueue(t *testing.T, setup NewStorageFunc, tearDown func()) {
 			assert.Contains(t, e.Message(), "invalid storage id; 'another-invalid-id'")
 			assert.Equal(t, 400, e.Code())
 		})
+
+		t.Run("AllOrNothing", func(t *testing.T) {
+			var reserved pb.QueueReserveResponse
+			require.NoError(t, c.QueueReserve(ctx, &pb.QueueReserveRequest{
+				ClientId:       clientID,
+				QueueName:      queueName,
+				BatchSize:      1,
+				RequestTimeout: "1m",
+			}, &reserved))
+			require.Len(t, reserved.Items, 1)
+
+			var peek pb.QueuePeekResponse
+			require.NoError(t, c.QueuePeek(ctx, &pb.QueuePeekRequest{QueueName: queueName, BatchSize: 1}, &peek))
+			require.Len(t, peek.Items, 1)
+
+			// None of the ids are completed if any of the ids cannot be completed
+			err := c.QueueComplete(ctx, &pb.QueueCompleteRequest{
+				Ids:            []string{reserved.Items[0].Id, peek.Items[0].Id},
+				QueueName:      queueName,
+				RequestTimeout: "1m",
+			})
+			require.Error(t, err)
+			var e duh.Error
+			require.True(t, errors.As(err, &e))
+			assert.Equal(t, fmt.Sprintf("item(s) cannot be completed; '%s' is not marked as reserved",
+				peek.Items[0].Id), e.Message())
+
+			var get pb.QueueGetResponse
+			require.NoError(t, c.QueueGet(ctx, &pb.QueueGetRequest{
+				Ids:       []string{reserved.Items[0].Id},
+				QueueName: queueName,
+			}, &get))
+			assert.True(t, get.Items[0].IsReserved)
+		})
+
+		t.Run("Detailed", func(t *testing.T) {
+			var reserved pb.QueueReserveResponse
+			require.NoError(t, c.QueueReserve(ctx, &pb.QueueReserveRequest{
+				ClientId:       clientID,
+				QueueName:      queueName,
+				BatchSize:      1,
+				RequestTimeout: "1m",
+			}, &reserved))
+			require.Len(t, reserved.Items, 1)
+
+			var peek pb.QueuePeekResponse
+			require.NoError(t, c.QueuePeek(ctx, &pb.QueuePeekRequest{QueueName: queueName, BatchSize: 1}, &peek))
+			require.Len(t, peek.Items, 1)
+
+			// Each id is completed independently, and the outcome of each is returned
+			ids := []string{reserved.Items[0].Id, peek.Items[0].Id, "another-invalid-id", reserved.Items[0].Id}
+			var res pb.QueueCompleteResponse
+			require.NoError(t, c.QueueCompleteDetailed(ctx, &pb.QueueCompleteRequest{
+				QueueName:      queueName,
+				RequestTimeout: "1m",
+				Ids:            ids,
+			}, &res))
+			require.Len(t, res.Results, 4)
+			for i, status := range []pb.CompleteStatus{
+				pb.CompleteStatus_COMPLETE_OK,
+				pb.CompleteStatus_COMPLETE_NOT_RESERVED,
+				pb.CompleteStatus_COMPLETE_INVALID_ID,
+				pb.CompleteStatus_COMPLETE_NOT_FOUND,
+			} {
+				assert.Equal(t, ids[i], res.Results[i].Id)
+				assert.Equal(t, status, res.Results[i].Status)
+			}
+			assert.Empty(t, res.Results[0].Message)
+			assert.Equal(t, fmt.Sprintf("item(s) cannot be completed; '%s' is not marked as reserved",
+				peek.Items[0].Id), res.Results[1].Message)
+			assert.Contains(t, res.Results[2].Message, "invalid storage id; 'another-invalid-id'")
+			assert.Equal(t, fmt.Sprintf("invalid storage id; '%s' does not exist", reserved.Items[0].Id),
+				res.Results[3].Message)
+
+			err := c.QueueGet(ctx, &pb.QueueGetRequest{
+				Ids:       []string{reserved.Items[0].Id},
+				QueueName: queueName,
+			}, &pb.QueueGetResponse{})
+			require.Error(t, err)
+		})
 	})
 
 	t.Run("Stats", func(t *testing.T) {
@@ -751,6 +967,7 @@ func testQueue(t *testing.T, setup NewStorageFunc, tearDown func()) {
 				ReserveTimeout: ReserveTimeout,
 				DeadTimeout:    DeadTimeout,
 				QueueName:      queueName,
+				MaxItemSize:    1_024,
 				Partitions:     1,
 			}))
 
@@ -852,6 +1069,138 @@ func testQueue(t *testing.T, setup NewStorageFunc, tearDown func()) {
 					Msg:  "items is invalid; max_produce_batch_size is 1000 but received 1001",
 					Code: duh.CodeBadRequest,
 				},
+				{
+					Name: "MaxItemSize",
+					Req: &pb.QueueProduceRequest{
+						QueueName:      queueName,
+						RequestTimeout: "1m",
+						Items: []*pb.QueueProduceItem{
+							{Bytes: make([]byte, 1_025)},
+						},
+					},
+					Msg:  "item payload is invalid; max_item_size is 1024 bytes but received 1025 bytes",
+					Code: duh.CodeBadRequest,
+				},
+				{
+					Name: "MaxItemSizeUtf8",
+					Req: &pb.QueueProduceRequest{
+						QueueName:      queueName,
+						RequestTimeout: "1m",
+						Items: []*pb.QueueProduceItem{
+							{Utf8: random.String("", 1_025)},
+						},
+					},
+					Msg:  "item payload is invalid; max_item_size is 1024 bytes but received 1025 bytes",
+					Code: duh.CodeBadRequest,
+				},
+				{
+					Name: "ItemReferenceMaxLength",
+					Req: &pb.QueueProduceRequest{
+						QueueName:      queueName,
+						RequestTimeout: "1m",
+						Items: []*pb.QueueProduceItem{
+							{Reference: random.String("", 2_001)},
+						},
+					},
+					Msg:  "item reference is invalid; cannot be greater than '2000' characters",
+					Code: duh.CodeBadRequest,
+				},
+				{
+					Name: "ItemKindMaxLength",
+					Req: &pb.QueueProduceRequest{
+						QueueName:      queueName,
+						RequestTimeout: "1m",
+						Items: []*pb.QueueProduceItem{
+							{Kind: random.String("", 513)},
+						},
+					},
+					Msg:  "item kind is invalid; cannot be greater than '512' characters",
+					Code: duh.CodeBadRequest,
+				},
+				{
+					Name: "ItemEncodingMaxLength",
+					Req: &pb.QueueProduceRequest{
+						QueueName:      queueName,
+						RequestTimeout: "1m",
+						Items: []*pb.QueueProduceItem{
+							{Encoding: random.String("", 513)},
+						},
+					},
+					Msg:  "item encoding is invalid; cannot be greater than '512' characters",
+					Code: duh.CodeBadRequest,
+				},
+				{
+					Name: "InvalidItemReserveTimeout",
+					Req: &pb.QueueProduceRequest{
+						QueueName:      queueName,
+						RequestTimeout: "1m",
+						Items: []*pb.QueueProduceItem{
+							{ReserveTimeout: "foo"},
+						},
+					},
+					Msg:  "item reserve timeout is invalid; time: invalid duration \"foo\" - expected format: 30s, 5m or 1h",
+					Code: duh.CodeBadRequest,
+				},
+				{
+					Name: "ItemReserveTimeoutTooLong",
+					Req: &pb.QueueProduceRequest{
+						QueueName:      queueName,
+						RequestTimeout: "1m",
+						Items: []*pb.QueueProduceItem{
+							{ReserveTimeout: "25h"},
+						},
+					},
+					Msg:  "item reserve timeout is too long; 25h0m0s cannot be greater than the dead timeout 24h0m0s",
+					Code: duh.CodeBadRequest,
+				},
+				{
+					Name: "ItemReserveTimeoutGreaterThanItemDeadTimeout",
+					Req: &pb.QueueProduceRequest{
+						QueueName:      queueName,
+						RequestTimeout: "1m",
+						Items: []*pb.QueueProduceItem{
+							{ReserveTimeout: "2h", DeadTimeout: "1h"},
+						},
+					},
+					Msg:  "item reserve timeout is too long; 2h0m0s cannot be greater than the dead timeout 1h0m0s",
+					Code: duh.CodeBadRequest,
+				},
+				{
+					Name: "InvalidItemDeadTimeout",
+					Req: &pb.QueueProduceRequest{
+						QueueName:      queueName,
+						RequestTimeout: "1m",
+						Items: []*pb.QueueProduceItem{
+							{DeadTimeout: "-1h"},
+						},
+					},
+					Msg:  "item dead timeout is invalid; '-1h' must be greater than zero",
+					Code: duh.CodeBadRequest,
+				},
+				{
+					Name: "ItemDeadTimeoutTooLong",
+					Req: &pb.QueueProduceRequest{
+						QueueName:      queueName,
+						RequestTimeout: "1m",
+						Items: []*pb.QueueProduceItem{
+							{DeadTimeout: "25h"},
+						},
+					},
+					Msg:  "item dead timeout is too long; 25h0m0s cannot be greater than the queue dead timeout 24h0m0s",
+					Code: duh.CodeBadRequest,
+				},
+				{
+					Name: "InvalidItemMaxAttempts",
+					Req: &pb.QueueProduceRequest{
+						QueueName:      queueName,
+						RequestTimeout: "1m",
+						Items: []*pb.QueueProduceItem{
+							{MaxAttempts: -1},
+						},
+					},
+					Msg:  "item max attempts is invalid; cannot be negative number",
+					Code: duh.CodeBadRequest,
+				},
 			} {
 				t.Run(test.Name, func(t *testing.T) {
 					err := c.QueueProduce(ctx, test.Req)
@@ -455,6 +455,30 @@ func testQueues(t *testing.T, setup NewStorageFunc, tearDown func()) {
 					Msg:  "reference field is invalid; cannot be greater than '2000' characters",
 					Code: duh.CodeBadRequest,
 				},
+				{
+					Name: "MaxItemSizeTooLarge",
+					Req: &pb.QueueInfo{
+						QueueName:      random.String("queue-", 10),
+						ReserveTimeout: ReserveTimeout,
+						DeadTimeout:    DeadTimeout,
+						MaxItemSize:    que.DefaultMaxItemSize + 1,
+						Partitions:     1,
+					},
+					Msg:  "max item size is invalid; cannot be greater than the service max_item_size of 524288 bytes",
+					Code: duh.CodeBadRequest,
+				},
+				{
+					Name: "MaxItemSizeNegative",
+					Req: &pb.QueueInfo{
+						QueueName:      random.String("queue-", 10),
+						ReserveTimeout: ReserveTimeout,
+						DeadTimeout:    DeadTimeout,
+						MaxItemSize:    -1,
+						Partitions:     1,
+					},
+					Msg:  "max item size is invalid; cannot be negative number",
+					Code: duh.CodeBadRequest,
+				},
 				{
 					Name: "ReserveTimeoutMaxLength",
 					Req: &pb.QueueInfo{
@@ -0,0 +1,207 @@
+package querator_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/duh-rpc/duh-go"
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/querator/transport"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRateLimits(t *testing.T) {
+	bdb := boltTestSetup{Dir: t.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			testRateLimits(t, tc.Setup, tc.TearDown)
+		})
+	}
+}
+
+func testRateLimits(t *testing.T, setup NewStorageFunc, tearDown func()) {
+	t.Run("PerQueue", func(t *testing.T) {
+		var queueName = random.String("queue-", 10)
+		cp := clock.NewProvider()
+		cp.Freeze(clock.Now())
+		_store := setup(clock.NewProvider())
+		defer tearDown()
+
+		d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{
+			StorageConfig: _store,
+			Clock:         cp,
+			RateLimits: que.RateLimits{
+				ProducePerQueue: que.RateLimit{Rate: 1, Burst: 2},
+			},
+		})
+		defer d.Shutdown(t)
+
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      queueName,
+			ReserveTimeout: "1m",
+			DeadTimeout:    "10m",
+			Partitions:     1,
+		}))
+
+		produce := func(c *que.Client) error {
+			return c.QueueProduce(ctx, &pb.QueueProduceRequest{
+				QueueName:      queueName,
+				RequestTimeout: "1m",
+				Items:          randomProduceItems(1),
+			})
+		}
+
+		// The burst allows two requests
+		require.NoError(t, produce(c))
+		require.NoError(t, produce(c))
+
+		// All clients share the same per queue limit
+		other := namespaceClient(t, d, "")
+		err := produce(other)
+		require.Error(t, err)
+		var e duh.Error
+		require.True(t, errors.As(err, &e))
+		assert.Equal(t, fmt.Sprintf("%s; too many produce requests for queue '%s', retry after 1s",
+			que.MsgRateLimited, queueName), e.Message())
+		assert.Equal(t, duh.CodeRetryRequest, e.Code())
+		assert.Equal(t, "1s", e.Details()[transport.DetailsRetryAfter])
+
+		// Other queue operations are not limited
+		var stats pb.QueueStatsResponse
+		require.NoError(t, c.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: queueName}, &stats))
+		assert.Equal(t, int32(2), stats.Total)
+
+		// After the hint has elapsed, the client may produce again
+		cp.Advance(clock.Second)
+		require.NoError(t, produce(c))
+		require.Error(t, produce(c))
+
+		t.Run("Metrics", func(t *testing.T) {
+			assert.Contains(t, scrapeMetrics(t, d), `rate_limit_rejections{limit="queue",method="produce"} 2`)
+		})
+	})
+
+	t.Run("PerClient", func(t *testing.T) {
+		var queueName = random.String("queue-", 10)
+		cp := clock.NewProvider()
+		cp.Freeze(clock.Now())
+		_store := setup(clock.NewProvider())
+		defer tearDown()
+
+		d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{
+			StorageConfig: _store,
+			Clock:         cp,
+			RateLimits: que.RateLimits{
+				ProducePerQueue:  que.RateLimit{Rate: 0.001, Burst: 2},
+				ProducePerClient: que.RateLimit{Rate: 2, Burst: 1},
+				ReservePerClient: que.RateLimit{Rate: 2, Burst: 1},
+			},
+		})
+		defer d.Shutdown(t)
+
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      queueName,
+			ReserveTimeout: "1m",
+			DeadTimeout:    "10m",
+			Partitions:     1,
+		}))
+
+		conf := que.WithNoTLS(d.d.Listener.Addr().String())
+		conf.Identity = "client-1"
+		client1, err := que.NewClient(conf)
+		require.NoError(t, err)
+		conf.Identity = "client-2"
+		client2, err := que.NewClient(conf)
+		require.NoError(t, err)
+
+		t.Run("Produce", func(t *testing.T) {
+			req := pb.QueueProduceRequest{
+				QueueName:      queueName,
+				RequestTimeout: "1m",
+				Items:          randomProduceItems(5),
+			}
+			require.NoError(t, client1.QueueProduce(ctx, &req))
+			err := client1.QueueProduce(ctx, &req)
+			require.Error(t, err)
+			var e duh.Error
+			require.True(t, errors.As(err, &e))
+			assert.Equal(t, fmt.Sprintf("%s; too many produce requests from client 'client-1' for queue '%s', "+
+				"retry after 500ms", que.MsgRateLimited, queueName), e.Message())
+			assert.Equal(t, duh.CodeRetryRequest, e.Code())
+			assert.Equal(t, "500ms", e.Details()[transport.DetailsRetryAfter])
+
+			// A different client is not limited
+			require.NoError(t, client2.QueueProduce(ctx, &req))
+		})
+
+		t.Run("DoesNotDrainQueueLimit", func(t *testing.T) {
+			queueName := random.String("queue-", 10)
+			require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+				QueueName:      queueName,
+				ReserveTimeout: "1m",
+				DeadTimeout:    "10m",
+				Partitions:     1,
+			}))
+			req := pb.QueueProduceRequest{
+				QueueName:      queueName,
+				RequestTimeout: "1m",
+				Items:          randomProduceItems(1),
+			}
+			require.NoError(t, client1.QueueProduce(ctx, &req))
+
+			// Requests rejected by the per client limit do not take tokens from the per queue limit
+			for i := 0; i < 5; i++ {
+				require.ErrorContains(t, client1.QueueProduce(ctx, &req), "too many produce requests from client")
+			}
+			require.NoError(t, client2.QueueProduce(ctx, &req))
+		})
+
+		t.Run("Reserve", func(t *testing.T) {
+			// Reserve requests are limited by ClientID
+			reserve := func(clientID string) error {
+				var res pb.QueueReserveResponse
+				return client1.QueueReserve(ctx, &pb.QueueReserveRequest{
+					ClientId:       clientID,
+					QueueName:      queueName,
+					RequestTimeout: "1m",
+					BatchSize:      1,
+				}, &res)
+			}
+			require.NoError(t, reserve("reserve-1"))
+			err := reserve("reserve-1")
+			require.Error(t, err)
+			var e duh.Error
+			require.True(t, errors.As(err, &e))
+			assert.Equal(t, fmt.Sprintf("%s; too many reserve requests from client 'reserve-1' for queue '%s', "+
+				"retry after 500ms", que.MsgRateLimited, queueName), e.Message())
+			require.NoError(t, reserve("reserve-2"))
+		})
+	})
+}
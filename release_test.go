@@ -0,0 +1,221 @@
+package querator_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/duh-rpc/duh-go"
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelease(t *testing.T) {
+	bdb := boltTestSetup{Dir: t.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			testRelease(t, tc.Setup, tc.TearDown)
+		})
+	}
+}
+
+func testRelease(t *testing.T, setup NewStorageFunc, tearDown func()) {
+	_store := setup(clock.NewProvider())
+	defer tearDown()
+	d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store})
+	defer d.Shutdown(t)
+
+	createQueue := func(t *testing.T) string {
+		queueName := random.String("queue-", 10)
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      queueName,
+			ReserveTimeout: "1m",
+			DeadTimeout:    "10m",
+			Partitions:     1,
+		}))
+		return queueName
+	}
+
+	reserve := func(t *testing.T, queueName string, batchSize int32) []*pb.QueueReserveItem {
+		var res pb.QueueReserveResponse
+		require.NoError(t, c.QueueReserve(ctx, &pb.QueueReserveRequest{
+			ClientId:       random.String("client-", 10),
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			BatchSize:      batchSize,
+		}, &res))
+		return res.Items
+	}
+
+	release := func(queueName string, skipAttempt bool, ids ...string) error {
+		return c.QueueRelease(ctx, &pb.QueueReleaseRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			SkipAttempt:    skipAttempt,
+			Ids:            ids,
+		})
+	}
+
+	t.Run("KeepsPosition", func(t *testing.T) {
+		queueName := createQueue(t)
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          randomProduceItems(3),
+		}))
+
+		items := reserve(t, queueName, 2)
+		require.Len(t, items, 2)
+		assert.Equal(t, int32(0), items[0].Attempts)
+		require.NoError(t, release(queueName, false, que.CollectIDs(items)...))
+
+		var stats pb.QueueStatsResponse
+		require.NoError(t, c.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: queueName}, &stats))
+		assert.Equal(t, int32(3), stats.Total)
+		assert.Equal(t, int32(0), stats.TotalReserved)
+
+		// The released items are offered again before the items produced after them
+		again := reserve(t, queueName, 3)
+		require.Len(t, again, 3)
+		assert.Equal(t, items[0].Id, again[0].Id)
+		assert.Equal(t, items[1].Id, again[1].Id)
+		assert.Equal(t, int32(1), again[0].Attempts)
+		assert.Equal(t, int32(0), again[2].Attempts)
+
+		t.Run("SkipAttempt", func(t *testing.T) {
+			require.NoError(t, release(queueName, true, again[0].Id))
+			items := reserve(t, queueName, 1)
+			require.Len(t, items, 1)
+			assert.Equal(t, again[0].Id, items[0].Id)
+			assert.Equal(t, int32(1), items[0].Attempts)
+		})
+
+		t.Run("NotReserved", func(t *testing.T) {
+			require.NoError(t, release(queueName, false, again[1].Id))
+
+			// None of the ids are released if any id cannot be released
+			err := release(queueName, false, again[2].Id, again[1].Id)
+			require.Error(t, err)
+			var e duh.Error
+			require.True(t, errors.As(err, &e))
+			assert.Equal(t, duh.CodeBadRequest, e.Code())
+			assert.Contains(t, e.Message(), "item(s) cannot be released;")
+			assert.Contains(t, e.Message(), "is not marked as reserved")
+
+			require.NoError(t, c.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: queueName}, &stats))
+			assert.Equal(t, int32(2), stats.TotalReserved)
+		})
+
+		t.Run("Errors", func(t *testing.T) {
+			err := release(queueName, false)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "ids is invalid; list of ids cannot be empty")
+
+			err = release(queueName, false, "invalid-id")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid storage id; 'invalid-id'")
+		})
+	})
+
+	t.Run("WaitingReservation", func(t *testing.T) {
+		queueName := createQueue(t)
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          randomProduceItems(1),
+		}))
+		items := reserve(t, queueName, 1)
+		require.Len(t, items, 1)
+
+		// Long poll a reserve on the now empty queue
+		polled := make(chan []*pb.QueueReserveItem)
+		go func() {
+			polled <- reserve(t, queueName, 1)
+		}()
+		require.Eventually(t, func() bool {
+			var stats pb.QueueStatsResponse
+			require.NoError(t, c.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: queueName}, &stats))
+			return stats.ReserveBlocked == 1
+		}, 5*clock.Second, 10*clock.Millisecond)
+
+		require.NoError(t, release(queueName, false, items[0].Id))
+		select {
+		case r := <-polled:
+			require.Len(t, r, 1)
+			assert.Equal(t, items[0].Id, r[0].Id)
+		case <-clock.After(5 * clock.Second):
+			t.Fatal("released item was not offered to the waiting reservation")
+		}
+	})
+
+	t.Run("ConsumerReleaseOnError", func(t *testing.T) {
+		queueName := createQueue(t)
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          randomProduceItems(10),
+		}))
+
+		// Every item fails the first attempt, which would block for the reserve timeout of one
+		// minute if the items were not released
+		var mutex sync.Mutex
+		attempts := make(map[string]int)
+		consumer, err := que.NewConsumer(que.ConsumerConfig{
+			Client:         c,
+			QueueName:      queueName,
+			RequestTimeout: 100 * clock.Millisecond,
+			ReleaseOnError: true,
+			Logger:         log,
+			Handler: func(ctx context.Context, item *pb.QueueReserveItem) error {
+				mutex.Lock()
+				defer mutex.Unlock()
+				attempts[item.Id]++
+				if item.Attempts == 0 {
+					return errors.New("failed")
+				}
+				return nil
+			},
+		})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			var stats pb.QueueStatsResponse
+			require.NoError(t, c.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: queueName}, &stats))
+			return stats.Total == 0
+		}, 5*clock.Second, 10*clock.Millisecond)
+		require.NoError(t, consumer.Shutdown(ctx))
+
+		require.Len(t, attempts, 10)
+		for _, count := range attempts {
+			assert.Equal(t, 2, count)
+		}
+	})
+}
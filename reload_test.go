@@ -0,0 +1,244 @@
+package querator_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/daemon"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	bdb := boltTestSetup{Dir: t.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			testReload(t, tc.Setup, tc.TearDown)
+		})
+	}
+}
+
+func testReload(t *testing.T, setup NewStorageFunc, tearDown func()) {
+	t.Run("Limits", func(t *testing.T) {
+		_store := setup(clock.NewProvider())
+		defer tearDown()
+		conf := que.ServiceConfig{
+			StorageConfig:       _store,
+			MaxProduceBatchSize: 5,
+			MaxReserveBatchSize: 5,
+		}
+		d, c, ctx := newDaemon(t, 10*clock.Second, conf)
+		defer d.Shutdown(t)
+
+		queueName := random.String("queue-", 10)
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      queueName,
+			ReserveTimeout: "1m",
+			DeadTimeout:    "10m",
+			Partitions:     1,
+		}))
+
+		// The queue is now running with the original limits
+		err := c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          randomProduceItems(6),
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "items is invalid; max_produce_batch_size is 5 but received 6")
+
+		conf.MaxProduceBatchSize = 10
+		conf.MaxReserveBatchSize = 10
+		conf.MaxItemSize = 20
+		require.NoError(t, d.Service().UpdateConfig(ctx, conf))
+
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          randomProduceItems(6),
+		}))
+
+		var res pb.QueueReserveResponse
+		err = c.QueueReserve(ctx, &pb.QueueReserveRequest{
+			ClientId:       random.String("client-", 10),
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			BatchSize:      11,
+		}, &res)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "max_reserve_batch_size is 10, but 11 was requested")
+
+		// The service max item size is also reloaded
+		err = c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          []*pb.QueueProduceItem{{Bytes: make([]byte, 21)}},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "max_item_size is 20 bytes but received 21 bytes")
+
+		// Invalid limits are rejected, and the running limits remain in effect
+		conf.MaxProduceBatchSize = 20
+		conf.MaxReserveBatchSize = -1
+		err = d.Service().UpdateConfig(ctx, conf)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "max_reserve_batch_size is invalid; cannot be negative")
+
+		err = c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          randomProduceItems(11),
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "items is invalid; max_produce_batch_size is 10 but received 11")
+	})
+
+	t.Run("Backends", func(t *testing.T) {
+		_store := setup(clock.NewProvider())
+		defer tearDown()
+		conf := que.ServiceConfig{StorageConfig: _store}
+		d, _, ctx := newDaemon(t, 10*clock.Second, conf)
+		defer d.Shutdown(t)
+
+		added := store.Backend{
+			PartitionStore: store.NewMemoryPartitionStore(_store),
+			Name:           "added",
+			Affinity:       1,
+		}
+		conf.StorageConfig.Backends = append(conf.StorageConfig.Backends, added)
+		require.NoError(t, d.Service().UpdateConfig(ctx, conf))
+
+		var health pb.HealthCheckResponse
+		require.NoError(t, d.Service().HealthCheck(ctx, &health))
+		require.Len(t, health.Checks, 4)
+		assert.Equal(t, "backend/added", health.Checks[3].Name)
+
+		// Backends which may hold partitions cannot be removed
+		conf.StorageConfig.Backends = []store.Backend{added}
+		err := d.Service().UpdateConfig(ctx, conf)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), fmt.Sprintf("backend '%s' cannot be removed",
+			_store.Backends[0].Name))
+
+		// The settings of an existing backend cannot be changed
+		changed := added
+		changed.Settings = "driver=BoltDB"
+		conf.StorageConfig.Backends = []store.Backend{_store.Backends[0], changed}
+		err = d.Service().UpdateConfig(ctx, conf)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "backend 'added' is invalid; settings cannot be changed")
+
+		// New backends which are unreachable are not registered
+		dir := filepath.Join(t.TempDir(), "does-not-exist")
+		conf.StorageConfig.Backends = append(_store.Backends, added, store.Backend{
+			PartitionStore: store.NewBoltPartitionStore(store.BoltConfig{StorageDir: dir}),
+			Name:           "unreachable",
+		})
+		err = d.Service().UpdateConfig(ctx, conf)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "backend 'unreachable' is invalid")
+
+		health.Reset()
+		require.NoError(t, d.Service().HealthCheck(ctx, &health))
+		assert.Len(t, health.Checks, 4)
+	})
+}
+
+func TestReloadConfigFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(maxProduce, maxItems int) {
+		require.NoError(t, os.WriteFile(file, []byte(fmt.Sprintf("listen-address: localhost:0\n"+
+			"max-produce-batch-size: %d\ndefault-namespace-quota:\n  max-items: %d\n", maxProduce, maxItems)), 0600))
+	}
+	write(5, 0)
+
+	conf, err := daemon.LoadConfigFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, file, conf.ConfigFile)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*clock.Second)
+	defer cancel()
+	d, err := daemon.NewDaemon(ctx, conf)
+	require.NoError(t, err)
+	defer func() { _ = d.Shutdown(context.Background()) }()
+
+	c := d.MustClient()
+	require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+		QueueName:      "queue-00",
+		ReserveTimeout: "1m",
+		DeadTimeout:    "10m",
+		Partitions:     1,
+	}))
+	produce := func() error {
+		return c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      "queue-00",
+			RequestTimeout: "1m",
+			Items:          randomProduceItems(6),
+		})
+	}
+	require.ErrorContains(t, produce(), "max_produce_batch_size is 5")
+
+	write(10, 0)
+	require.NoError(t, c.AdminReload(ctx))
+	require.NoError(t, produce())
+
+	// Namespace quotas are reloaded from the file
+	write(10, 8)
+	require.NoError(t, c.AdminReload(ctx))
+	require.ErrorContains(t, produce(), "namespace quota exceeded")
+
+	// New backends are registered, but the settings of existing backends cannot change
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(file, []byte(fmt.Sprintf("listen-address: localhost:0\n"+
+		"max-produce-batch-size: 10\ndefault-namespace-quota:\n  max-items: 8\nbackends:\n"+
+		"  - {name: memory-0, driver: InMemory, affinity: 1}\n"+
+		"  - {name: bolt-0, driver: BoltDB, config: {storage-dir: %s}}\n", dir)), 0600))
+	require.NoError(t, c.AdminReload(ctx))
+	var health pb.HealthCheckResponse
+	require.NoError(t, d.Service().HealthCheck(ctx, &health))
+	assert.Equal(t, "backend/bolt-0", health.Checks[len(health.Checks)-1].Name)
+
+	require.NoError(t, os.WriteFile(file, []byte(fmt.Sprintf("listen-address: localhost:0\n"+
+		"backends:\n  - {name: memory-0, driver: BoltDB, config: {storage-dir: %s}}\n"+
+		"  - {name: bolt-0, driver: BoltDB, config: {storage-dir: %s}}\n", dir, dir)), 0600))
+	err = c.AdminReload(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "backend 'memory-0' is invalid; settings cannot be changed")
+
+	// An invalid config file is rejected, and the running config remains in effect
+	require.NoError(t, os.WriteFile(file, []byte("max-produce-batch-size: -1\n"), 0600))
+	err = c.AdminReload(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reload failed; config file")
+	require.ErrorContains(t, produce(), "namespace quota exceeded")
+}
@@ -0,0 +1,183 @@
+package querator
+
+import (
+	"errors"
+	"github.com/duh-rpc/duh-go"
+	"github.com/kapetan-io/querator/transport"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/set"
+	"google.golang.org/protobuf/proto"
+	"math/rand/v2"
+	"net/http"
+)
+
+const (
+	DefaultRetryInitialBackoff = 100 * clock.Millisecond
+	DefaultRetryMaxBackoff     = 5 * clock.Second
+	DefaultRetryJitter         = 0.2
+)
+
+// RetryPolicy is the policy the Client uses to retry requests which failed with a retryable error.
+//
+// Requests which are not idempotent, such as `/queue.produce` and `/storage/queue.add` are only retried
+// if the error guarantees the request was not processed by the server, such as when the request was
+// rate limited, the queue was overloaded or the service was shutting down. Retrying a non-idempotent
+// request after a timeout or a network error could result in duplicate items. Set RetryNonIdempotent
+// to retry such requests anyway.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts made for a single request, including the
+	// first attempt. The default is 1, which disables retries.
+	MaxAttempts int
+	// InitialBackoff is the time to wait before the first retry. The backoff doubles with each
+	// retry up to MaxBackoff. If the server provided a retry after hint, the client waits for the
+	// hint instead. The default is 100 milliseconds.
+	InitialBackoff clock.Duration
+	// MaxBackoff is the maximum amount of time to wait between retries. The default is 5 seconds.
+	MaxBackoff clock.Duration
+	// Jitter is the fraction of the backoff which is randomized to avoid many clients retrying
+	// in lock step. A Jitter of 0.2 will wait for the backoff +/- 20%. The default is 0.2
+	Jitter float64
+	// RetryNonIdempotent allows requests which are not idempotent to be retried on any retryable error
+	RetryNonIdempotent bool
+}
+
+// notIdempotent is the set of RPCs which are not idempotent, retrying these could result in duplicate items
+var notIdempotent = map[string]bool{
+	transport.RPCQueueProduce:    true,
+	transport.RPCStorageQueueAdd: true,
+}
+
+// do performs the request according to the retry policy of the client
+func (c *Client) do(r *http.Request, res proto.Message) error {
+	return c.retry(r, res, c.conf.RetryPolicy)
+}
+
+// retry performs the request according to the retry policy provided. If an endpoint reports the service
+// is shutting down, draining or cannot be reached, the request fails over to the next endpoint in
+// ClientConfig.FailoverEndpoints.
+func (c *Client) retry(r *http.Request, res proto.Message, p RetryPolicy) error {
+	set.Default(&p.MaxAttempts, 1)
+	set.Default(&p.InitialBackoff, DefaultRetryInitialBackoff)
+	set.Default(&p.MaxBackoff, DefaultRetryMaxBackoff)
+	set.Default(&p.Jitter, DefaultRetryJitter)
+
+	backoff := p.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		req := r
+		if attempt > 1 || len(c.endpoints) > 1 {
+			var err error
+			if req, err = c.retryRequest(r); err != nil {
+				return err
+			}
+		}
+
+		err := c.client.Do(req, res)
+		if err == nil || attempt >= p.MaxAttempts || r.Context().Err() != nil {
+			return err
+		}
+
+		shutdown, unreachable := isShutdown(err), isUnreachable(err)
+		if shutdown || unreachable {
+			c.failover(req.URL.Host)
+		}
+
+		safe := shutdown || isRejected(err)
+		retryable := safe || unreachable || isRetryRequest(err)
+		if !retryable || (!safe && notIdempotent[r.URL.Path] && !p.RetryNonIdempotent) {
+			return err
+		}
+
+		// Fail over to the next endpoint immediately, unless we have tried them all
+		if shutdown && attempt%len(c.endpoints) != 0 {
+			continue
+		}
+
+		select {
+		case <-clock.After(jitter(retryAfter(err, backoff), p.Jitter)):
+		case <-r.Context().Done():
+			return err
+		}
+		backoff = min(backoff*2, p.MaxBackoff)
+	}
+}
+
+// retryRequest returns a copy of the request with a new body, addressed to the current endpoint
+func (c *Client) retryRequest(r *http.Request) (*http.Request, error) {
+	req := r.Clone(r.Context())
+	if r.GetBody != nil {
+		body, err := r.GetBody()
+		if err != nil {
+			return nil, duh.NewClientError("while copying request body: %w", err, nil)
+		}
+		req.Body = body
+	}
+	e := c.endpoints[c.endpoint.Load()%int32(len(c.endpoints))]
+	req.URL.Scheme, req.URL.Host, req.Host = e.Scheme, e.Host, e.Host
+	return req, nil
+}
+
+// failover advances to the next endpoint, if the failed host is still the current endpoint
+func (c *Client) failover(host string) {
+	cur := c.endpoint.Load()
+	if c.endpoints[cur%int32(len(c.endpoints))].Host == host {
+		c.endpoint.CompareAndSwap(cur, cur+1)
+	}
+}
+
+func jitter(d clock.Duration, fraction float64) clock.Duration {
+	if fraction <= 0 {
+		return d
+	}
+	return clock.Duration(float64(d) * (1 + fraction*(rand.Float64()*2-1)))
+}
+
+// isShutdown returns true if the error indicates the service or queue is shutting down, or the
+// service is draining in preparation for shutdown
+func isShutdown(err error) bool {
+	var e duh.Error
+	if !errors.As(err, &e) {
+		return false
+	}
+	if e.Code() == duh.CodeRetryRequest {
+		return e.Message() == MsgServiceDraining
+	}
+	return e.Code() == duh.CodeRequestFailed &&
+		(e.Message() == MsgServiceInShutdown || e.Message() == MsgQueueInShutdown)
+}
+
+// isUnreachable returns true if the error occurred while attempting to communicate with the server
+func isUnreachable(err error) bool {
+	var e duh.Error
+	return errors.As(err, &e) && e.Code() == duh.CodeClientError && e.Details()[duh.DetailsHttpUrl] != ""
+}
+
+// isRejected returns true if the server rejected the request without processing it
+func isRejected(err error) bool {
+	var e duh.Error
+	if !errors.As(err, &e) || e.Code() != duh.CodeRetryRequest {
+		return false
+	}
+	return e.Message() == MsgQueueOverLoaded || e.Details()[transport.DetailsRetryAfter] != ""
+}
+
+func isRequestTimeout(err error) bool {
+	var e duh.Error
+	return errors.As(err, &e) && e.Code() == duh.CodeRetryRequest && e.Message() == MsgRequestTimeout
+}
+
+func isRetryRequest(err error) bool {
+	var e duh.Error
+	return errors.As(err, &e) && e.Code() == duh.CodeRetryRequest
+}
+
+// retryAfter returns the retry after hint provided by the server, or the backoff provided
+// if the server did not provide a hint.
+func retryAfter(err error, backoff clock.Duration) clock.Duration {
+	var e duh.Error
+	if errors.As(err, &e) {
+		if d, err := clock.ParseDuration(e.Details()[transport.DetailsRetryAfter]); err == nil && d > 0 {
+			return d
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,249 @@
+package querator_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/duh-rpc/duh-go"
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/querator/transport"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	bdb := boltTestSetup{Dir: t.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			testRetryPolicy(t, tc.Setup, tc.TearDown)
+		})
+	}
+}
+
+func testRetryPolicy(t *testing.T, setup NewStorageFunc, tearDown func()) {
+	createQueue := func(t *testing.T, ctx context.Context, c *que.Client, queueName string) {
+		require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+			QueueName:      queueName,
+			ReserveTimeout: "1m",
+			DeadTimeout:    "10m",
+			Partitions:     1,
+		}))
+	}
+
+	produce := func(ctx context.Context, c *que.Client, queueName string) error {
+		return c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          randomProduceItems(1),
+		})
+	}
+
+	t.Run("Rejected", func(t *testing.T) {
+		var queueName = random.String("queue-", 10)
+		_store := setup(clock.NewProvider())
+		defer tearDown()
+		d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{
+			StorageConfig: _store,
+			RateLimits: que.RateLimits{
+				ProducePerQueue: que.RateLimit{Rate: 20, Burst: 1},
+			},
+		})
+		defer d.Shutdown(t)
+		createQueue(t, ctx, c, queueName)
+
+		conf := que.WithNoTLS(d.d.Listener.Addr().String())
+		conf.RetryPolicy = que.RetryPolicy{MaxAttempts: 5, InitialBackoff: 10 * clock.Millisecond}
+		retry, err := que.NewClient(conf)
+		require.NoError(t, err)
+
+		// Rate limited requests were not processed by the server, and are safe
+		// to retry even though produce is not idempotent
+		for i := 0; i < 3; i++ {
+			require.NoError(t, produce(ctx, retry, queueName))
+		}
+
+		// Without a retry policy, the client returns the error. Since the burst is one,
+		// one of two consecutive requests is rate limited.
+		for i := 0; i < 2 && err == nil; i++ {
+			err = produce(ctx, c, queueName)
+		}
+		var e duh.Error
+		require.True(t, errors.As(err, &e))
+		assert.Equal(t, duh.CodeRetryRequest, e.Code())
+	})
+
+	t.Run("NotIdempotent", func(t *testing.T) {
+		var queueName = random.String("queue-", 10)
+		_store := setup(clock.NewProvider())
+		defer tearDown()
+		d, _, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{
+			StorageConfig: _store,
+			NamespaceQuotas: map[string]que.NamespaceQuota{
+				"limited": {MaxItems: 1},
+			},
+		})
+		defer d.Shutdown(t)
+
+		conf := que.WithNoTLS(d.d.Listener.Addr().String())
+		conf.Namespace = "limited"
+		conf.RetryPolicy = que.RetryPolicy{MaxAttempts: 3, InitialBackoff: 10 * clock.Millisecond}
+		c, err := que.NewClient(conf)
+		require.NoError(t, err)
+		createQueue(t, ctx, c, queueName)
+		require.NoError(t, produce(ctx, c, queueName))
+
+		// The namespace quota is exceeded, which is a retry request error which does not guarantee
+		// the request was rejected without being processed, so produce requests are not retried.
+		before := requestCount(t, d, transport.RPCQueueProduce)
+		err = produce(ctx, c, queueName)
+		var e duh.Error
+		require.True(t, errors.As(err, &e))
+		assert.Equal(t, duh.CodeRetryRequest, e.Code())
+		assert.Equal(t, before+1, requestCount(t, d, transport.RPCQueueProduce))
+
+		// Unless the policy allows it
+		conf.RetryPolicy.RetryNonIdempotent = true
+		c, err = que.NewClient(conf)
+		require.NoError(t, err)
+		require.Error(t, produce(ctx, c, queueName))
+		assert.Equal(t, before+4, requestCount(t, d, transport.RPCQueueProduce))
+	})
+
+	t.Run("Failover", func(t *testing.T) {
+		var queueName = random.String("queue-", 10)
+		_store := setup(clock.NewProvider())
+		defer tearDown()
+
+		primary, pc, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{
+			StorageConfig: setupMemoryStorage(store.StorageConfig{}),
+		})
+		defer primary.Shutdown(t)
+		secondary, sc, _ := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store})
+		defer secondary.Shutdown(t)
+		createQueue(t, ctx, pc, queueName)
+		createQueue(t, ctx, sc, queueName)
+
+		conf := que.WithNoTLS(primary.d.Listener.Addr().String())
+		conf.FailoverEndpoints = []string{fmt.Sprintf("http://%s", secondary.d.Listener.Addr().String())}
+		conf.RetryPolicy = que.RetryPolicy{MaxAttempts: 3, InitialBackoff: 10 * clock.Millisecond}
+		c, err := que.NewClient(conf)
+		require.NoError(t, err)
+
+		// The primary reports the service is shutting down
+		require.NoError(t, primary.d.Service().Shutdown(ctx))
+
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          randomProduceItems(5),
+		}))
+
+		// Subsequent requests are sent to the secondary
+		var stats pb.QueueStatsResponse
+		require.NoError(t, c.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: queueName}, &stats))
+		assert.Equal(t, int32(5), stats.Total)
+	})
+
+	t.Run("Draining", func(t *testing.T) {
+		var queueName = random.String("queue-", 10)
+		_store := setup(clock.NewProvider())
+		defer tearDown()
+
+		primary, pc, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{
+			StorageConfig: setupMemoryStorage(store.StorageConfig{Clock: clock.NewProvider()}),
+		})
+		defer primary.Shutdown(t)
+		secondary, sc, _ := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store})
+		defer secondary.Shutdown(t)
+		createQueue(t, ctx, pc, queueName)
+		createQueue(t, ctx, sc, queueName)
+
+		conf := que.WithNoTLS(primary.d.Listener.Addr().String())
+		conf.FailoverEndpoints = []string{fmt.Sprintf("http://%s", secondary.d.Listener.Addr().String())}
+		conf.RetryPolicy = que.RetryPolicy{MaxAttempts: 3, InitialBackoff: 10 * clock.Millisecond}
+		c, err := que.NewClient(conf)
+		require.NoError(t, err)
+
+		// The primary is draining, produce is not idempotent but was not processed by the primary
+		var drain pb.AdminDrainResponse
+		require.NoError(t, pc.AdminDrain(ctx, &drain))
+
+		require.NoError(t, c.QueueProduce(ctx, &pb.QueueProduceRequest{
+			QueueName:      queueName,
+			RequestTimeout: "1m",
+			Items:          randomProduceItems(5),
+		}))
+
+		var stats pb.QueueStatsResponse
+		require.NoError(t, sc.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: queueName}, &stats))
+		assert.Equal(t, int32(5), stats.Total)
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		var queueName = random.String("queue-", 10)
+		_store := setup(clock.NewProvider())
+		defer tearDown()
+
+		gone, _, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{
+			StorageConfig: setupMemoryStorage(store.StorageConfig{}),
+		})
+		address := gone.d.Listener.Addr().String()
+		gone.Shutdown(t)
+
+		d, dc, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{StorageConfig: _store})
+		defer d.Shutdown(t)
+		createQueue(t, ctx, dc, queueName)
+
+		conf := que.WithNoTLS(address)
+		conf.FailoverEndpoints = []string{fmt.Sprintf("http://%s", d.d.Listener.Addr().String())}
+		conf.RetryPolicy = que.RetryPolicy{MaxAttempts: 3, InitialBackoff: 10 * clock.Millisecond}
+		c, err := que.NewClient(conf)
+		require.NoError(t, err)
+
+		var stats pb.QueueStatsResponse
+		require.NoError(t, c.QueueStats(ctx, &pb.QueueStatsRequest{QueueName: queueName}, &stats))
+	})
+}
+
+// requestCount returns the number of requests the daemon has handled for the path provided
+func requestCount(t *testing.T, d *testDaemon, path string) int {
+	t.Helper()
+
+	m := regexp.MustCompile(fmt.Sprintf(`http_handler_duration_count{path="%s"} (\d+)`, regexp.QuoteMeta(path))).
+		FindStringSubmatch(scrapeMetrics(t, d))
+	if m == nil {
+		return 0
+	}
+	count, err := strconv.Atoi(m[1])
+	require.NoError(t, err)
+	return count
+}
@@ -18,19 +18,30 @@ package querator
 
 import (
 	"context"
+	"fmt"
 	"github.com/duh-rpc/duh-go"
 	"github.com/kapetan-io/querator/internal"
 	"github.com/kapetan-io/querator/internal/store"
 	"github.com/kapetan-io/querator/internal/types"
 	"github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/querator/transport"
 	"github.com/kapetan-io/tackle/clock"
 	"github.com/kapetan-io/tackle/set"
+	"github.com/prometheus/client_golang/prometheus"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 const (
 	DefaultListLimit = 1_000
+	// TracerName is the name of the tracer used to create spans within the service
+	TracerName = "github.com/kapetan-io/querator"
 )
 
 type ServiceConfig struct {
@@ -44,57 +55,181 @@ type ServiceConfig struct {
 	WriteTimeout clock.Duration
 	// ReadTimeout The time it should take for a single batched read to complete
 	ReadTimeout clock.Duration
-	// MaxReserveBatchSize is the maximum number of items a client can request in a single reserve request
+	// MaxReserveBatchSize is the maximum number of items a client can request in a single reserve request.
+	// It also limits the number of items returned by a single peek request.
 	MaxReserveBatchSize int
 	// MaxProduceBatchSize is the maximum number of items a client can produce in a single produce request
 	MaxProduceBatchSize int
-	// MaxCompleteBatchSize is the maximum number of ids a client can mark complete in a single complete request
+	// MaxCompleteBatchSize is the maximum number of ids a client can mark complete in a single complete request.
+	// It also limits the number of ids in a single extend, release or get request.
 	MaxCompleteBatchSize int
 	// MaxRequestsPerQueue is the maximum number of client requests a queue can handle before it returns an
-	// queue overloaded message
+	// queue overloaded message. UpdateConfig() can only lower it for queues which are already running.
 	MaxRequestsPerQueue int
+	// MaxItemSize is the maximum size in bytes of a single item payload. Queues may be configured
+	// with a smaller maximum item size, but never larger. The default is 512KB.
+	MaxItemSize int
+	// MaxReserveSize is the maximum total size in bytes of the items returned by a single
+	// reserve request. A reservation will always include at least one item, regardless of the
+	// size of the item. The default is 4MB.
+	MaxReserveSize int
+	// NamespaceQuotas is a map of namespace names to the quota enforced for that namespace.
+	// See NamespaceQuota for details
+	NamespaceQuotas map[string]NamespaceQuota
+	// DefaultNamespaceQuota is the quota enforced for any namespace not found in NamespaceQuotas.
+	// The default is no quota.
+	DefaultNamespaceQuota NamespaceQuota
+	// RateLimits are the token bucket rate limits enforced on produce, reserve and complete requests.
+	// See RateLimits for details. The default is no rate limits.
+	RateLimits RateLimits
+	// TraceExporter is the OpenTelemetry exporter which spans created by the service are exported to.
+	// Spans are created for each HTTP request, each batch processed by a queue and each call to the
+	// storage backend. The default is to create no spans.
+	TraceExporter sdktrace.SpanExporter
 	// Clock is a time provider used to preform time related calculations. It is configurable so that it can
 	// be overridden for testing.
 	Clock *clock.Provider
+	// ConfigLoader if provided, is called by Service.Reload() to load a new config which is then
+	// applied via Service.UpdateConfig()
+	ConfigLoader func() (ServiceConfig, error)
 }
 
+// limits returns the limits enforced by each logical queue
+func (c ServiceConfig) limits() internal.Limits {
+	return internal.Limits{
+		WriteTimeout:         c.WriteTimeout,
+		ReadTimeout:          c.ReadTimeout,
+		MaxReserveBatchSize:  c.MaxReserveBatchSize,
+		MaxProduceBatchSize:  c.MaxProduceBatchSize,
+		MaxCompleteBatchSize: c.MaxCompleteBatchSize,
+		MaxRequestsPerQueue:  c.MaxRequestsPerQueue,
+		MaxReserveSize:       c.MaxReserveSize,
+	}
+}
+
+// NamespaceQuota is the limits imposed upon all the queues within a namespace.
+// A limit of zero means the limit is not enforced.
+type NamespaceQuota = types.NamespaceQuota
+
+// RateLimits are the rate limits enforced on requests to a queue. A rate limit with
+// a Rate of zero is not enforced.
+type RateLimits = types.RateLimits
+
+// RateLimit is a token bucket rate limit
+type RateLimit = types.RateLimit
+
 type Service struct {
-	queues *internal.QueuesManager
-	conf   ServiceConfig
+	tracerProvider trace.TracerProvider
+	rateLimited    *prometheus.CounterVec
+	limiter        *internal.RateLimiter
+	queues         *internal.QueuesManager
+	// conf is replaced when the config is reloaded, see UpdateConfig()
+	conf        atomic.Pointer[ServiceConfig]
+	reloadMutex sync.Mutex
 }
 
 func NewService(conf ServiceConfig) (*Service, error) {
 	set.Default(&conf.Logger, slog.Default())
+	set.Default(&conf.MaxItemSize, internal.DefaultMaxItemSize)
+
+	var tp trace.TracerProvider = noop.NewTracerProvider()
+	if conf.TraceExporter != nil {
+		tp = sdktrace.NewTracerProvider(sdktrace.WithBatcher(conf.TraceExporter))
+	}
 
 	qm, err := internal.NewQueuesManager(internal.QueuesManagerConfig{
 		LogicalConfig: internal.LogicalConfig{
-			MaxReserveBatchSize:  conf.MaxReserveBatchSize,
-			MaxProduceBatchSize:  conf.MaxProduceBatchSize,
-			MaxCompleteBatchSize: conf.MaxCompleteBatchSize,
-			MaxRequestsPerQueue:  conf.MaxRequestsPerQueue,
-			Clock:                conf.Clock,
+			Limits: conf.limits(),
+			Tracer: tp.Tracer(TracerName),
+			Clock:  conf.Clock,
 		},
-		StorageConfig: conf.StorageConfig,
-		Logger:        conf.Logger,
+		NamespaceQuotas:       conf.NamespaceQuotas,
+		DefaultNamespaceQuota: conf.DefaultNamespaceQuota,
+		StorageConfig:         conf.StorageConfig,
+		Logger:                conf.Logger,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &Service{
-		conf:   conf,
-		queues: qm,
-	}, nil
+	s := &Service{
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rate_limit_rejections",
+			Help: "The number of requests rejected because they exceeded a rate limit",
+		}, []string{"method", "limit"}),
+		limiter:        internal.NewRateLimiter(internal.RateLimiterConfig{Clock: conf.Clock}),
+		tracerProvider: tp,
+		queues:         qm,
+	}
+	s.conf.Store(&conf)
+	return s, nil
+}
+
+// UpdateConfig applies the limits, timeouts, namespace quotas, rate limits and storage backends
+// from the config provided to the running service. Limits and timeouts are propagated to all
+// running queues, and any new storage backends are registered. All other fields are ignored, as
+// they cannot be changed without a restart.
+func (s *Service) UpdateConfig(ctx context.Context, conf ServiceConfig) error {
+	defer s.reloadMutex.Unlock()
+	s.reloadMutex.Lock()
+
+	set.Default(&conf.MaxItemSize, internal.DefaultMaxItemSize)
+	if err := s.queues.Reload(ctx, internal.ReloadConfig{
+		Limits:                conf.limits(),
+		NamespaceQuotas:       conf.NamespaceQuotas,
+		DefaultNamespaceQuota: conf.DefaultNamespaceQuota,
+		Backends:              conf.StorageConfig.Backends,
+	}); err != nil {
+		return err
+	}
+
+	next := *s.conf.Load()
+	next.WriteTimeout = conf.WriteTimeout
+	next.ReadTimeout = conf.ReadTimeout
+	next.MaxReserveBatchSize = conf.MaxReserveBatchSize
+	next.MaxProduceBatchSize = conf.MaxProduceBatchSize
+	next.MaxCompleteBatchSize = conf.MaxCompleteBatchSize
+	next.MaxRequestsPerQueue = conf.MaxRequestsPerQueue
+	next.MaxItemSize = conf.MaxItemSize
+	next.MaxReserveSize = conf.MaxReserveSize
+	next.NamespaceQuotas = conf.NamespaceQuotas
+	next.DefaultNamespaceQuota = conf.DefaultNamespaceQuota
+	next.RateLimits = conf.RateLimits
+	s.conf.Store(&next)
+	return nil
+}
+
+// Reload loads a new config using ServiceConfig.ConfigLoader and applies it via UpdateConfig()
+func (s *Service) Reload(ctx context.Context) error {
+	loader := s.conf.Load().ConfigLoader
+	if loader == nil {
+		return transport.NewRequestFailed("%s; no config loader configured", internal.MsgReloadFailed)
+	}
+
+	conf, err := loader()
+	if err != nil {
+		return transport.NewRequestFailed("%s; %s", internal.MsgReloadFailed, err)
+	}
+	return s.UpdateConfig(ctx, conf)
 }
 
 func (s *Service) QueueProduce(ctx context.Context, req *proto.QueueProduceRequest) error {
-	queue, err := s.queues.Get(ctx, req.QueueName)
+	queue, err := s.queues.Get(ctx, transport.NamespaceFromContext(ctx), req.QueueName)
 	if err != nil {
 		return err
 	}
 
+	rl := s.conf.Load().RateLimits
+	if err := s.rateLimit(ctx, "produce", req.QueueName, "", rl.ProducePerQueue, rl.ProducePerClient); err != nil {
+		return err
+	}
+
 	var r types.ProduceRequest
-	if err := s.validateQueueProduceProto(req, &r); err != nil {
+	if err := s.validateQueueProduceProto(req, &r, s.maxItemSize(queue.Info())); err != nil {
+		return err
+	}
+
+	if err := s.checkQuota(ctx, r.Items); err != nil {
 		return err
 	}
 
@@ -109,11 +244,17 @@ func (s *Service) QueueProduce(ctx context.Context, req *proto.QueueProduceReque
 func (s *Service) QueueReserve(ctx context.Context, req *proto.QueueReserveRequest,
 	res *proto.QueueReserveResponse) error {
 
-	queue, err := s.queues.Get(ctx, req.QueueName)
+	queue, err := s.queues.Get(ctx, transport.NamespaceFromContext(ctx), req.QueueName)
 	if err != nil {
 		return err
 	}
 
+	rl := s.conf.Load().RateLimits
+	if err := s.rateLimit(ctx, "reserve", req.QueueName, req.ClientId, rl.ReservePerQueue,
+		rl.ReservePerClient); err != nil {
+		return err
+	}
+
 	var r types.ReserveRequest
 	if err := s.validateQueueReserveProto(req, &r); err != nil {
 		return err
@@ -128,6 +269,8 @@ func (s *Service) QueueReserve(ctx context.Context, req *proto.QueueReserveReque
 		res.Items = append(res.Items, &proto.QueueReserveItem{
 			ReserveDeadline: timestamppb.New(item.ReserveDeadline),
 			Attempts:        int32(item.Attempts),
+			Priority:        int32(item.Priority),
+			OrderingKey:     item.OrderingKey,
 			Id:              string(item.ID),
 			Reference:       item.Reference,
 			Encoding:        item.Encoding,
@@ -139,12 +282,21 @@ func (s *Service) QueueReserve(ctx context.Context, req *proto.QueueReserveReque
 	return nil
 }
 
-func (s *Service) QueueComplete(ctx context.Context, req *proto.QueueCompleteRequest) error {
-	queue, err := s.queues.Get(ctx, req.QueueName)
+// QueueComplete marks reserved items as complete. If the request is detailed, the outcome of each
+// id is returned in the response instead of failing the request if any id cannot be completed.
+func (s *Service) QueueComplete(ctx context.Context, req *proto.QueueCompleteRequest,
+	res *proto.QueueCompleteResponse) error {
+
+	queue, err := s.queues.Get(ctx, transport.NamespaceFromContext(ctx), req.QueueName)
 	if err != nil {
 		return err
 	}
 
+	rl := s.conf.Load().RateLimits
+	if err := s.rateLimit(ctx, "complete", req.QueueName, "", rl.CompletePerQueue, rl.CompletePerClient); err != nil {
+		return err
+	}
+
 	var r types.CompleteRequest
 	if err := s.validateQueueCompleteProto(req, &r); err != nil {
 		return err
@@ -155,11 +307,254 @@ func (s *Service) QueueComplete(ctx context.Context, req *proto.QueueCompleteReq
 		return err
 	}
 
+	for _, result := range r.Results {
+		res.Results = append(res.Results, &proto.QueueCompleteResult{
+			Id:      string(result.ID),
+			Status:  result.Status,
+			Message: result.Message,
+		})
+	}
 	return nil
 }
 
+// QueueExtend extends the reservation of items a consumer is still working on, such that they are not
+// offered to another consumer before the work is complete.
+func (s *Service) QueueExtend(ctx context.Context, req *proto.QueueExtendRequest,
+	res *proto.QueueExtendResponse) error {
+	queue, err := s.queues.Get(ctx, transport.NamespaceFromContext(ctx), req.QueueName)
+	if err != nil {
+		return err
+	}
+
+	// Extending a reservation is treated as a complete for the purpose of rate limiting
+	rl := s.conf.Load().RateLimits
+	if err := s.rateLimit(ctx, "extend", req.QueueName, "", rl.CompletePerQueue, rl.CompletePerClient); err != nil {
+		return err
+	}
+
+	var r types.ExtendRequest
+	if err := s.validateQueueExtendProto(req, &r); err != nil {
+		return err
+	}
+
+	// Extend will block until success, context cancel or timeout
+	if err := queue.Extend(ctx, &r); err != nil {
+		return err
+	}
+
+	// Items produced with their own reserve timeout may have been extended by a different timeout,
+	// as such the earliest of the new deadlines is returned.
+	deadline := r.ReserveDeadlines[0]
+	for _, d := range r.ReserveDeadlines[1:] {
+		if d.Before(deadline) {
+			deadline = d
+		}
+	}
+	res.ReserveDeadline = timestamppb.New(deadline)
+	return nil
+}
+
+// QueueRelease releases the reservation of items such that they can be reserved by another consumer
+// immediately, instead of waiting for the reservation to expire.
+func (s *Service) QueueRelease(ctx context.Context, req *proto.QueueReleaseRequest) error {
+	queue, err := s.queues.Get(ctx, transport.NamespaceFromContext(ctx), req.QueueName)
+	if err != nil {
+		return err
+	}
+
+	// Releasing items is treated as a complete for the purpose of rate limiting
+	rl := s.conf.Load().RateLimits
+	if err := s.rateLimit(ctx, "release", req.QueueName, "", rl.CompletePerQueue, rl.CompletePerClient); err != nil {
+		return err
+	}
+
+	var r types.ReleaseRequest
+	if err := s.validateQueueReleaseProto(req, &r); err != nil {
+		return err
+	}
+
+	// Release will block until success, context cancel or timeout
+	if err := queue.Release(ctx, &r); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// QueuePeek returns the next items that would be reserved without reserving them
+func (s *Service) QueuePeek(ctx context.Context, req *proto.QueuePeekRequest,
+	res *proto.QueuePeekResponse) error {
+
+	queue, err := s.queues.Get(ctx, transport.NamespaceFromContext(ctx), req.QueueName)
+	if err != nil {
+		return err
+	}
+
+	// Peeking at items is treated as a reserve for the purpose of rate limiting
+	rl := s.conf.Load().RateLimits
+	if err := s.rateLimit(ctx, "peek", req.QueueName, "", rl.ReservePerQueue, rl.ReservePerClient); err != nil {
+		return err
+	}
+
+	var items []*types.Item
+	if err := queue.Peek(ctx, &items, int(req.BatchSize)); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		res.Items = append(res.Items, toQueueItem(item))
+	}
+	return nil
+}
+
+// QueueGet returns the items requested by id without reserving them
+func (s *Service) QueueGet(ctx context.Context, req *proto.QueueGetRequest, res *proto.QueueGetResponse) error {
+	queue, err := s.queues.Get(ctx, transport.NamespaceFromContext(ctx), req.QueueName)
+	if err != nil {
+		return err
+	}
+
+	rl := s.conf.Load().RateLimits
+	if err := s.rateLimit(ctx, "get", req.QueueName, "", rl.ReservePerQueue, rl.ReservePerClient); err != nil {
+		return err
+	}
+
+	ids := make([]types.ItemID, 0, len(req.Ids))
+	for _, id := range req.Ids {
+		ids = append(ids, types.ItemID(id))
+	}
+
+	var items []*types.Item
+	if err := queue.Get(ctx, ids, &items); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		res.Items = append(res.Items, toQueueItem(item))
+	}
+	return nil
+}
+
+// QueueRegister registers the client as a consumer of the queue, such that the items reserved by the
+// consumer are released if the consumer stops sending heartbeats.
+func (s *Service) QueueRegister(ctx context.Context, req *proto.QueueRegisterRequest,
+	res *proto.QueueHeartbeatResponse) error {
+	queue, err := s.queues.Get(ctx, transport.NamespaceFromContext(ctx), req.QueueName)
+	if err != nil {
+		return err
+	}
+
+	// Consumer lifecycle requests are treated as a reserve for the purpose of rate limiting
+	rl := s.conf.Load().RateLimits
+	if err := s.rateLimit(ctx, "register", req.QueueName, req.ClientId, rl.ReservePerQueue,
+		rl.ReservePerClient); err != nil {
+		return err
+	}
+
+	var r types.ConsumerRequest
+	if err := s.validateQueueRegisterProto(req, &r); err != nil {
+		return err
+	}
+
+	if err := queue.Register(ctx, &r); err != nil {
+		return err
+	}
+
+	res.HeartbeatDeadline = timestamppb.New(r.HeartbeatDeadline)
+	return nil
+}
+
+// QueueHeartbeat informs the queue the registered consumer is still alive
+func (s *Service) QueueHeartbeat(ctx context.Context, req *proto.QueueHeartbeatRequest,
+	res *proto.QueueHeartbeatResponse) error {
+	queue, err := s.queues.Get(ctx, transport.NamespaceFromContext(ctx), req.QueueName)
+	if err != nil {
+		return err
+	}
+
+	rl := s.conf.Load().RateLimits
+	if err := s.rateLimit(ctx, "heartbeat", req.QueueName, req.ClientId, rl.ReservePerQueue,
+		rl.ReservePerClient); err != nil {
+		return err
+	}
+
+	r := types.ConsumerRequest{ClientID: req.ClientId}
+	if err := queue.Heartbeat(ctx, &r); err != nil {
+		return err
+	}
+
+	res.HeartbeatDeadline = timestamppb.New(r.HeartbeatDeadline)
+	return nil
+}
+
+// QueueDeregister removes the consumer from the queue and releases any items the consumer has reserved
+func (s *Service) QueueDeregister(ctx context.Context, req *proto.QueueDeregisterRequest) error {
+	queue, err := s.queues.Get(ctx, transport.NamespaceFromContext(ctx), req.QueueName)
+	if err != nil {
+		return err
+	}
+
+	rl := s.conf.Load().RateLimits
+	if err := s.rateLimit(ctx, "deregister", req.QueueName, req.ClientId, rl.ReservePerQueue,
+		rl.ReservePerClient); err != nil {
+		return err
+	}
+
+	return queue.Deregister(ctx, &types.ConsumerRequest{ClientID: req.ClientId})
+}
+
+// QueueConsumers lists the consumers registered with the queue
+func (s *Service) QueueConsumers(ctx context.Context, req *proto.QueueConsumersRequest,
+	res *proto.QueueConsumersResponse) error {
+	queue, err := s.queues.Get(ctx, transport.NamespaceFromContext(ctx), req.QueueName)
+	if err != nil {
+		return err
+	}
+
+	var consumers []types.ConsumerInfo
+	if err := queue.Consumers(ctx, &consumers); err != nil {
+		return err
+	}
+
+	for _, c := range consumers {
+		res.Consumers = append(res.Consumers, &proto.QueueConsumer{
+			HeartbeatDeadline: timestamppb.New(c.HeartbeatDeadline),
+			HeartbeatTimeout:  c.HeartbeatTimeout.String(),
+			RegisteredAt:      timestamppb.New(c.RegisteredAt),
+			LastActivity:      timestamppb.New(c.LastActivity),
+			Reserved:          int32(c.Reserved),
+			ClientId:          c.ClientID,
+		})
+	}
+	return nil
+}
+
+func toQueueItem(item *types.Item) *proto.QueueItem {
+	qi := &proto.QueueItem{
+		DeadDeadline: timestamppb.New(item.DeadDeadline),
+		CreatedAt:    timestamppb.New(item.CreatedAt),
+		MaxAttempts:  int32(item.MaxAttempts),
+		Attempts:     int32(item.Attempts),
+		Priority:     int32(item.Priority),
+		OrderingKey:  item.OrderingKey,
+		IsReserved:   item.IsReserved,
+		Id:           string(item.ID),
+		Reference:    item.Reference,
+		Encoding:     item.Encoding,
+		Bytes:        item.Payload,
+		Kind:         item.Kind,
+	}
+	if item.IsReserved {
+		qi.ReserveDeadline = timestamppb.New(item.ReserveDeadline)
+	}
+	if !item.ExpireDeadline.IsZero() {
+		qi.ExpireDeadline = timestamppb.New(item.ExpireDeadline)
+	}
+	return qi
+}
+
 func (s *Service) QueueClear(ctx context.Context, req *proto.QueueClearRequest) error {
-	queue, err := s.queues.Get(ctx, req.QueueName)
+	queue, err := s.queues.Get(ctx, transport.NamespaceFromContext(ctx), req.QueueName)
 	if err != nil {
 		return err
 	}
@@ -181,7 +576,7 @@ func (s *Service) QueueClear(ctx context.Context, req *proto.QueueClearRequest)
 // PauseQueue is used to temporarily pause processing of queue requests to simulate various high contention scenarios
 // in testing; it is not exposed to the users via API calls.
 func (s *Service) PauseQueue(ctx context.Context, queueName string, pause bool) error {
-	queue, err := s.queues.Get(ctx, queueName)
+	queue, err := s.queues.Get(ctx, transport.NamespaceFromContext(ctx), queueName)
 	if err != nil {
 		return err
 	}
@@ -203,6 +598,7 @@ func (s *Service) QueuesCreate(ctx context.Context, req *proto.QueueInfo) error
 	if err := s.validateQueueOptionsProto(req, &info); err != nil {
 		return err
 	}
+	info.Namespace = transport.NamespaceFromContext(ctx)
 
 	_, err := s.queues.Create(ctx, info)
 	if err != nil {
@@ -220,7 +616,7 @@ func (s *Service) QueuesList(ctx context.Context, req *proto.QueuesListRequest,
 	}
 
 	items := make([]types.QueueInfo, 0, allocInt32(req.Limit))
-	if err := s.queues.List(ctx, &items, types.ListOptions{
+	if err := s.queues.List(ctx, transport.NamespaceFromContext(ctx), &items, types.ListOptions{
 		Pivot: types.ToItemID(req.Pivot),
 		Limit: int(req.Limit),
 	}); err != nil {
@@ -239,6 +635,7 @@ func (s *Service) QueuesUpdate(ctx context.Context, req *proto.QueueInfo) error
 	if err := s.validateQueueOptionsProto(req, &info); err != nil {
 		return err
 	}
+	info.Namespace = transport.NamespaceFromContext(ctx)
 
 	if err := s.queues.Update(ctx, info); err != nil {
 		return err
@@ -248,7 +645,7 @@ func (s *Service) QueuesUpdate(ctx context.Context, req *proto.QueueInfo) error
 
 func (s *Service) QueuesDelete(ctx context.Context, req *proto.QueuesDeleteRequest) error {
 
-	if err := s.queues.Delete(ctx, req.QueueName); err != nil {
+	if err := s.queues.Delete(ctx, transport.NamespaceFromContext(ctx), req.QueueName); err != nil {
 		return err
 	}
 	return nil
@@ -261,7 +658,7 @@ func (s *Service) QueuesDelete(ctx context.Context, req *proto.QueuesDeleteReque
 func (s *Service) StorageQueueList(ctx context.Context, req *proto.StorageQueueListRequest,
 	res *proto.StorageQueueListResponse) error {
 
-	queue, err := s.queues.Get(ctx, req.QueueName)
+	queue, err := s.queues.Get(ctx, transport.NamespaceFromContext(ctx), req.QueueName)
 	if err != nil {
 		return err
 	}
@@ -288,15 +685,23 @@ func (s *Service) StorageQueueList(ctx context.Context, req *proto.StorageQueueL
 func (s *Service) StorageQueueAdd(ctx context.Context, req *proto.StorageQueueAddRequest,
 	res *proto.StorageQueueAddResponse) error {
 
-	queue, err := s.queues.Get(ctx, req.QueueName)
+	queue, err := s.queues.Get(ctx, transport.NamespaceFromContext(ctx), req.QueueName)
 	if err != nil {
 		return err
 	}
 
+	maxItemSize := s.maxItemSize(queue.Info())
 	items := make([]*types.Item, 0, len(req.Items))
 	for _, item := range req.Items {
 		i := new(types.Item)
-		items = append(items, i.FromProto(item))
+		if err := s.validateItem(i.FromProto(item), maxItemSize); err != nil {
+			return err
+		}
+		items = append(items, i)
+	}
+
+	if err := s.checkQuota(ctx, items); err != nil {
+		return err
 	}
 
 	if err := queue.StorageQueueAdd(ctx, &items); err != nil {
@@ -312,7 +717,7 @@ func (s *Service) StorageQueueAdd(ctx context.Context, req *proto.StorageQueueAd
 
 func (s *Service) StorageQueueDelete(ctx context.Context, req *proto.StorageQueueDeleteRequest) error {
 
-	queue, err := s.queues.Get(ctx, req.QueueName)
+	queue, err := s.queues.Get(ctx, transport.NamespaceFromContext(ctx), req.QueueName)
 	if err != nil {
 		return err
 	}
@@ -331,7 +736,7 @@ func (s *Service) StorageQueueDelete(ctx context.Context, req *proto.StorageQueu
 func (s *Service) QueueStats(ctx context.Context, req *proto.QueueStatsRequest,
 	res *proto.QueueStatsResponse) error {
 
-	queue, err := s.queues.Get(ctx, req.QueueName)
+	queue, err := s.queues.Get(ctx, transport.NamespaceFromContext(ctx), req.QueueName)
 	if err != nil {
 		return err
 	}
@@ -345,6 +750,8 @@ func (s *Service) QueueStats(ctx context.Context, req *proto.QueueStatsRequest,
 	res.TotalReserved = int32(stats.TotalReserved)
 	res.AverageAge = stats.AverageAge.String()
 	res.Total = int32(stats.Total)
+	res.TotalBytes = stats.TotalBytes
+	res.TotalExpired = int32(stats.TotalExpired)
 	res.ProduceWaiting = int32(stats.ProduceWaiting)
 	res.ReserveWaiting = int32(stats.ReserveWaiting)
 	res.CompleteWaiting = int32(stats.CompleteWaiting)
@@ -353,7 +760,125 @@ func (s *Service) QueueStats(ctx context.Context, req *proto.QueueStatsRequest,
 	return nil
 }
 
+// HealthCheck verifies the service is not shutting down and that the queue store and all the
+// storage backends are reachable. The result of each check is written to res, if any check
+// fails HealthCheck returns an error.
+func (s *Service) HealthCheck(ctx context.Context, res *proto.HealthCheckResponse) error {
+	var failed []string
+	res.Status = HealthPass
+	for _, c := range s.queues.HealthCheck(ctx) {
+		check := &proto.HealthCheck{Name: c.Name, Status: HealthPass}
+		if c.Err != nil {
+			check.Status, check.Message = HealthFail, c.Err.Error()
+			failed = append(failed, c.Name)
+		}
+		res.Checks = append(res.Checks, check)
+	}
+
+	if len(failed) != 0 {
+		res.Status = HealthFail
+		return transport.NewRequestFailed(fmt.Sprintf("%s; '%s' failed", MsgHealthCheckFailed,
+			strings.Join(failed, "', '")))
+	}
+	return nil
+}
+
+// Drain places the service into drain mode in preparation for shutdown, and reports the progress
+// of the drain. While draining, new produce and reserve requests are rejected with a retry request
+// error telling clients to retry with another instance, reserve requests waiting for items return
+// without items, and complete requests are processed as normal. Once the response reports drained,
+// the service can be shutdown without failing any client requests.
+func (s *Service) Drain(ctx context.Context, res *proto.AdminDrainResponse) error {
+	if err := s.queues.Drain(ctx); err != nil {
+		return err
+	}
+	return s.DrainStatus(ctx, res)
+}
+
+// DrainStatus reports the progress of a drain started by Drain()
+func (s *Service) DrainStatus(ctx context.Context, res *proto.AdminDrainResponse) error {
+	var status types.DrainStatus
+	if err := s.queues.DrainStatus(ctx, &status); err != nil {
+		return err
+	}
+
+	res.Draining = status.Draining
+	res.Drained = status.Draining && status.InFlight == 0
+	res.Queues = int32(status.Queues)
+	res.InFlight = int32(status.InFlight)
+	res.Reserved = int32(status.Reserved)
+	return nil
+}
+
+// maxItemSize returns the maximum item size for the queue provided
+func (s *Service) maxItemSize(info types.QueueInfo) int {
+	if info.MaxItemSize != 0 {
+		return info.MaxItemSize
+	}
+	return s.conf.Load().MaxItemSize
+}
+
+// checkQuota returns an error if adding the items provided would exceed the quota
+// of the namespace the request operates within.
+func (s *Service) checkQuota(ctx context.Context, items []*types.Item) error {
+	var size int64
+	for _, item := range items {
+		size += int64(len(item.Payload))
+	}
+	return s.queues.CheckQuota(ctx, transport.NamespaceFromContext(ctx), int64(len(items)), size)
+}
+
+// rateLimit returns an error if the request exceeds either the per client or per queue rate limit provided.
+// If clientID is empty, the client is identified by the identity provided by the transport. The per client
+// limit is checked first, such that a client which exceeds its own limit does not drain the per queue limit
+// shared by all clients.
+func (s *Service) rateLimit(ctx context.Context, method, queueName, clientID string,
+	perQueue, perClient RateLimit) error {
+
+	key := method + "/" + types.QueueKey(transport.NamespaceFromContext(ctx), queueName)
+	if clientID == "" {
+		clientID = transport.ClientIdentityFromContext(ctx)
+	}
+	if ok, after := s.limiter.Allow(key+"/"+clientID, perClient); !ok {
+		s.rateLimited.WithLabelValues(method, "client").Inc()
+		return transport.NewRetryRequestAfter(after, "%s; too many %s requests from client '%s' for "+
+			"queue '%s', retry after %s", internal.MsgRateLimited, method, clientID, queueName, after)
+	}
+
+	if ok, after := s.limiter.Allow(key, perQueue); !ok {
+		s.rateLimited.WithLabelValues(method, "queue").Inc()
+		return transport.NewRetryRequestAfter(after, "%s; too many %s requests for queue '%s', retry after %s",
+			internal.MsgRateLimited, method, queueName, after)
+	}
+	return nil
+}
+
+// Describe fetches prometheus metrics to be registered
+func (s *Service) Describe(ch chan<- *prometheus.Desc) {
+	s.rateLimited.Describe(ch)
+	s.queues.Describe(ch)
+}
+
+// Collect fetches metrics from the service for use by prometheus
+func (s *Service) Collect(ch chan<- prometheus.Metric) {
+	s.rateLimited.Collect(ch)
+	s.queues.Collect(ch)
+}
+
 func (s *Service) Shutdown(ctx context.Context) error {
 	// See 0015-shutdown-errors.md for a discussion of shutdown operation
-	return s.queues.Shutdown(ctx)
+	if err := s.queues.Shutdown(ctx); err != nil {
+		return err
+	}
+
+	// Flush any spans which remain to the exporter
+	if tp, ok := s.tracerProvider.(*sdktrace.TracerProvider); ok {
+		return tp.Shutdown(ctx)
+	}
+	return nil
+}
+
+// TracerProvider returns the OpenTelemetry tracer provider used by the service
+func (s *Service) TracerProvider() trace.TracerProvider {
+	return s.tracerProvider
 }
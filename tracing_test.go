@@ -0,0 +1,115 @@
+package querator_test
+
+import (
+	"context"
+	que "github.com/kapetan-io/querator"
+	"github.com/kapetan-io/querator/internal/store"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/querator/transport"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/kapetan-io/tackle/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+)
+
+// spanRecorder is an in memory exporter which retains the spans exported after shutdown
+type spanRecorder struct {
+	*tracetest.InMemoryExporter
+}
+
+func (r spanRecorder) Shutdown(context.Context) error {
+	return nil
+}
+
+func (r spanRecorder) Find(name string) (tracetest.SpanStub, bool) {
+	for _, s := range r.GetSpans() {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return tracetest.SpanStub{}, false
+}
+
+func TestTracing(t *testing.T) {
+	bdb := boltTestSetup{Dir: t.TempDir()}
+
+	for _, tc := range []struct {
+		Setup    NewStorageFunc
+		TearDown func()
+		Name     string
+	}{
+		{
+			Name: "InMemory",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return setupMemoryStorage(store.StorageConfig{Clock: cp})
+			},
+			TearDown: func() {},
+		},
+		{
+			Name: "BoltDB",
+			Setup: func(cp *clock.Provider) store.StorageConfig {
+				return bdb.Setup(store.BoltConfig{Clock: cp})
+			},
+			TearDown: func() {
+				bdb.Teardown()
+			},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			testTracing(t, tc.Setup, tc.TearDown)
+		})
+	}
+}
+
+func testTracing(t *testing.T, setup NewStorageFunc, tearDown func()) {
+	var queueName = random.String("queue-", 10)
+	recorder := spanRecorder{tracetest.NewInMemoryExporter()}
+	_store := setup(clock.NewProvider())
+	defer tearDown()
+
+	d, c, ctx := newDaemon(t, 10*clock.Second, que.ServiceConfig{
+		StorageConfig: _store,
+		TraceExporter: recorder,
+	})
+
+	require.NoError(t, c.QueuesCreate(ctx, &pb.QueueInfo{
+		QueueName:      queueName,
+		ReserveTimeout: "1m",
+		DeadTimeout:    "10m",
+		Partitions:     1,
+	}))
+
+	// The client propagates the trace of the caller to the server
+	tp := sdktrace.NewTracerProvider()
+	callerCtx, caller := tp.Tracer("test").Start(ctx, "caller")
+	require.NoError(t, c.QueueProduce(callerCtx, &pb.QueueProduceRequest{
+		QueueName:      queueName,
+		RequestTimeout: "1m",
+		Items:          randomProduceItems(10),
+	}))
+	caller.End()
+
+	// Spans are flushed to the exporter on shutdown
+	d.Shutdown(t)
+
+	request, ok := recorder.Find(transport.RPCQueueProduce)
+	require.True(t, ok)
+	assert.Equal(t, caller.SpanContext().TraceID(), request.SpanContext.TraceID())
+	assert.Equal(t, caller.SpanContext().SpanID(), request.Parent.SpanID())
+
+	// The batch span is linked to the request span it served
+	batch, ok := recorder.Find("Logical.Produce")
+	require.True(t, ok)
+	require.Equal(t, 1, len(batch.Links))
+	assert.Equal(t, request.SpanContext.SpanID(), batch.Links[0].SpanContext.SpanID())
+	assert.NotEqual(t, request.SpanContext.TraceID(), batch.SpanContext.TraceID())
+
+	// The storage call is a child of the batch span
+	partition, ok := recorder.Find("Partition.Produce")
+	require.True(t, ok)
+	assert.Equal(t, batch.SpanContext.SpanID(), partition.Parent.SpanID())
+	assert.Equal(t, batch.SpanContext.TraceID(), partition.SpanContext.TraceID())
+}
@@ -0,0 +1,64 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+const (
+	// HeaderNamespace is the HTTP header clients use to select the namespace (tenant) their requests
+	// operate within. If the header is omitted, requests operate within the default namespace.
+	HeaderNamespace = "Querator-Namespace"
+	// HeaderClientIdentity is the HTTP header clients use to identify themselves for the purpose
+	// of rate limiting. If the header is omitted, the remote address of the client is used. The
+	// header is provided by the client, such that a client can avoid its per client rate limits
+	// by changing the header. Only rely on per client rate limits when Querator is deployed behind
+	// an authenticating proxy which sets or strips this header.
+	HeaderClientIdentity = "Querator-Client-Identity"
+)
+
+type namespaceKey struct{}
+type clientIdentityKey struct{}
+
+// ContextWithNamespace returns a new context which holds the namespace provided. Users who embed
+// Querator as a library can use this to select the namespace a `Service` call operates within.
+func ContextWithNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, namespace)
+}
+
+// NamespaceFromContext returns the namespace held by the context. Returns an empty string which
+// is the default namespace if no namespace was set.
+func NamespaceFromContext(ctx context.Context) string {
+	ns, _ := ctx.Value(namespaceKey{}).(string)
+	return ns
+}
+
+// ContextWithClientIdentity returns a new context which holds the identity of the client making the request.
+func ContextWithClientIdentity(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, clientIdentityKey{}, identity)
+}
+
+// ClientIdentityFromContext returns the client identity held by the context. Returns an empty string
+// if no identity was set.
+func ClientIdentityFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(clientIdentityKey{}).(string)
+	return id
+}
+
+// requestContext returns the request context along with the namespace and identity provided by the client
+func requestContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	if ns := r.Header.Get(HeaderNamespace); ns != "" {
+		ctx = ContextWithNamespace(ctx, ns)
+	}
+
+	id := r.Header.Get(HeaderClientIdentity)
+	if id == "" {
+		id = r.RemoteAddr
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			id = host
+		}
+	}
+	return ContextWithClientIdentity(ctx, id)
+}
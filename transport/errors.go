@@ -5,9 +5,18 @@ import (
 	"github.com/duh-rpc/duh-go"
 	v1 "github.com/duh-rpc/duh-go/proto/v1"
 	"github.com/kapetan-io/errors"
+	"github.com/kapetan-io/tackle/clock"
 	"google.golang.org/protobuf/proto"
 )
 
+const (
+	// MsgInternalError is the message returned to clients when the server encounters an unexpected error
+	MsgInternalError = "internal error"
+	// DetailsErrorID is the key in the reply details which holds the id of an internal error. The
+	// id is included in the server logs, such that operators can correlate the error with the logs.
+	DetailsErrorID = "error-id"
+)
+
 // -------------------------------------------------
 
 // ErrRequestFailed is used to tell the client that the request was valid, but it failed for some reason.
@@ -96,16 +105,33 @@ var _ duh.Error = &ErrInvalidOption{}
 
 // -------------------------------------------------
 
+// DetailsRetryAfter is the key in the reply details which holds the duration the client
+// should wait before retrying the request. The value is a duration string IE: "1.5s"
+const DetailsRetryAfter = "retry-after"
+
 // ErrRetryRequest is used to tell the client that the request was valid, the server did not encounter a failure, but
 // the request did not succeed. The client should retry
 type ErrRetryRequest struct {
-	msg string
+	retryAfter clock.Duration
+	msg        string
 }
 
 func NewRetryRequest(msg string, args ...any) *ErrRetryRequest {
 	return &ErrRetryRequest{msg: fmt.Sprintf(msg, args...)}
 }
 
+// NewRetryRequestAfter is identical to NewRetryRequest but includes a hint
+// telling the client how long it should wait before retrying.
+func NewRetryRequestAfter(after clock.Duration, msg string, args ...any) *ErrRetryRequest {
+	return &ErrRetryRequest{msg: fmt.Sprintf(msg, args...), retryAfter: after}
+}
+
+// RetryAfter returns the duration the client should wait before retrying the request,
+// returns zero if the client may retry immediately.
+func (e *ErrRetryRequest) RetryAfter() clock.Duration {
+	return e.retryAfter
+}
+
 func (e *ErrRetryRequest) Error() string {
 	return e.msg
 }
@@ -124,12 +150,15 @@ func (e *ErrRetryRequest) ProtoMessage() proto.Message {
 		Message:  e.msg,
 		CodeText: duh.CodeText(duh.CodeRetryRequest),
 		Code:     int32(duh.CodeRetryRequest),
-		Details:  nil,
+		Details:  e.Details(),
 	}
 }
 
 func (e *ErrRetryRequest) Details() map[string]string {
-	return nil
+	if e.retryAfter == 0 {
+		return nil
+	}
+	return map[string]string{DetailsRetryAfter: e.retryAfter.String()}
 }
 
 func (e *ErrRetryRequest) Message() string {
@@ -18,14 +18,23 @@ package transport
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"github.com/duh-rpc/duh-go"
 	v1 "github.com/duh-rpc/duh-go/proto/v1"
+	"github.com/kapetan-io/errors"
 	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
 	"github.com/kapetan-io/tackle/set"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/segmentio/ksuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"math"
 	"net/http"
+	"strconv"
 )
 
 // TODO: Document pause in OpenAPI, "Pauses queue processing such that requests to produce, reserve,
@@ -33,13 +42,24 @@ import (
 //  until the pause is lifted". /v1/queue API requests can still timeout
 //  NOTE: This does not effect /v1/storage/ or /v1/queue.list,create,delete,update API requests.
 
+// TracerName is the name of the tracer used to create spans for HTTP requests
+const TracerName = "github.com/kapetan-io/querator/transport"
+
 const (
-	RPCQueueProduce  = "/v1/queue.produce"
-	RPCQueueReserve  = "/v1/queue.reserve"
-	RPCQueueDefer    = "/v1/queue.defer"
-	RPCQueueComplete = "/v1/queue.complete"
-	RPCQueueStats    = "/v1/queue.stats"
-	RPCQueueClear    = "/v1/queue.clear"
+	RPCQueueProduce    = "/v1/queue.produce"
+	RPCQueueReserve    = "/v1/queue.reserve"
+	RPCQueueDefer      = "/v1/queue.defer"
+	RPCQueueComplete   = "/v1/queue.complete"
+	RPCQueueExtend     = "/v1/queue.extend"
+	RPCQueueRelease    = "/v1/queue.release"
+	RPCQueuePeek       = "/v1/queue.peek"
+	RPCQueueGet        = "/v1/queue.get"
+	RPCQueueRegister   = "/v1/queue.register"
+	RPCQueueHeartbeat  = "/v1/queue.heartbeat"
+	RPCQueueDeregister = "/v1/queue.deregister"
+	RPCQueueConsumers  = "/v1/queue.consumers"
+	RPCQueueStats      = "/v1/queue.stats"
+	RPCQueueClear      = "/v1/queue.clear"
 
 	RPCQueuesInfo      = "/v1/queues.info"
 	RPCQueuesRebalance = "/v1/queues.rebalance"
@@ -53,8 +73,8 @@ const (
 	//  pivot is the first item, because if the pivot is missing from the data store the API will return the next
 	//  item after the pivot. This is an extremely efficient way to iterate through a SQL RDBMS `primary_key > pivot`
 
-	// TODO: We should define a maximum payload size and now allow clients to send or receive larger than expected
-	//   payloads. I think DUH should do this for us when via duh.ReadRequest(r, &req, maxSize)
+	// NOTE: The size of request payloads is limited by duh.ReadRequest(r, &req, maxSize), the size of individual
+	//  items is limited by the queue 'max_item_size' and the size of reserve responses by `MaxReserveSize`.
 
 	RPCStorageQueueList   = "/v1/storage/queue.list"
 	RPCStorageQueueAdd    = "/v1/storage/queue.add"
@@ -64,8 +84,31 @@ const (
 	RPCStorageScheduleQueueAdd = "/v1/storage/schedule.add"
 	RPCStorageScheduleDelete   = "/v1/storage/schedule.delete"
 	RPCStorageScheduleStats    = "/v1/storage/schedule.stats"
+
+	// RPCAdminReload reloads the service config, see Service.Reload() for details
+	RPCAdminReload = "/v1/admin.reload"
+	// RPCAdminDrain places the service into drain mode before shutdown, and RPCAdminDrainStatus
+	// reports the progress of the drain. See Service.Drain() for details.
+	RPCAdminDrain       = "/v1/admin.drain"
+	RPCAdminDrainStatus = "/v1/admin.drain_status"
+
+	// PathLiveness and PathReadiness are GET endpoints intended for use by orchestration systems
+	// like Kubernetes. Liveness reports the process is able to serve HTTP requests, readiness reports
+	// the service is not shutting down and all the storage backends are reachable.
+	PathLiveness  = "/healthz"
+	PathReadiness = "/readyz"
+)
+
+const (
+	// HealthPass is the status of a health check which passed
+	HealthPass = "pass"
+	// HealthFail is the status of a health check which failed
+	HealthFail = "fail"
 )
 
+// HealthCheckTimeout is the maximum amount of time a readiness check will wait for the storage backends
+const HealthCheckTimeout = 5 * clock.Second
+
 // Service is an abstraction separating the public protocol from the underlying implementation.
 //
 // Abstraction rules dictate that the `transport` package should NOT access any other public interfaces or types.
@@ -78,7 +121,15 @@ const (
 type Service interface {
 	QueueProduce(context.Context, *pb.QueueProduceRequest) error
 	QueueReserve(context.Context, *pb.QueueReserveRequest, *pb.QueueReserveResponse) error
-	QueueComplete(context.Context, *pb.QueueCompleteRequest) error
+	QueueComplete(context.Context, *pb.QueueCompleteRequest, *pb.QueueCompleteResponse) error
+	QueueExtend(context.Context, *pb.QueueExtendRequest, *pb.QueueExtendResponse) error
+	QueueRelease(context.Context, *pb.QueueReleaseRequest) error
+	QueuePeek(context.Context, *pb.QueuePeekRequest, *pb.QueuePeekResponse) error
+	QueueGet(context.Context, *pb.QueueGetRequest, *pb.QueueGetResponse) error
+	QueueRegister(context.Context, *pb.QueueRegisterRequest, *pb.QueueHeartbeatResponse) error
+	QueueHeartbeat(context.Context, *pb.QueueHeartbeatRequest, *pb.QueueHeartbeatResponse) error
+	QueueDeregister(context.Context, *pb.QueueDeregisterRequest) error
+	QueueConsumers(context.Context, *pb.QueueConsumersRequest, *pb.QueueConsumersResponse) error
 	QueueStats(context.Context, *pb.QueueStatsRequest, *pb.QueueStatsResponse) error
 	QueueClear(context.Context, *pb.QueueClearRequest) error
 
@@ -90,18 +141,29 @@ type Service interface {
 	StorageQueueList(context.Context, *pb.StorageQueueListRequest, *pb.StorageQueueListResponse) error
 	StorageQueueAdd(context.Context, *pb.StorageQueueAddRequest, *pb.StorageQueueAddResponse) error
 	StorageQueueDelete(context.Context, *pb.StorageQueueDeleteRequest) error
+
+	HealthCheck(context.Context, *pb.HealthCheckResponse) error
+	Reload(context.Context) error
+	Drain(context.Context, *pb.AdminDrainResponse) error
+	DrainStatus(context.Context, *pb.AdminDrainResponse) error
 }
 
 type HTTPHandler struct {
 	duration       *prometheus.SummaryVec
+	tracer         trace.Tracer
 	log            duh.StandardLogger
 	metrics        http.Handler
 	service        Service
 	maxProduceSize int64
 }
 
-func NewHTTPHandler(s Service, metrics http.Handler, maxProduceSize int64, log duh.StandardLogger) *HTTPHandler {
+// NewHTTPHandler returns a handler which serves the Querator HTTP API. Trace context found in the headers
+// of incoming requests is propagated to the spans created by the TracerProvider. If the TracerProvider
+// is nil, no spans are created.
+func NewHTTPHandler(s Service, metrics http.Handler, maxProduceSize int64, tp trace.TracerProvider,
+	log duh.StandardLogger) *HTTPHandler {
 	set.Default(&maxProduceSize, int64(duh.MegaByte))
+	set.Default(&tp, noop.NewTracerProvider())
 
 	return &HTTPHandler{
 		duration: prometheus.NewSummaryVec(prometheus.SummaryOpts{
@@ -112,6 +174,7 @@ func NewHTTPHandler(s Service, metrics http.Handler, maxProduceSize int64, log d
 				0.99: 0.001,
 			},
 		}, []string{"path"}),
+		tracer:         tp.Tracer(TracerName),
 		maxProduceSize: maxProduceSize,
 		metrics:        metrics,
 		log:            log,
@@ -121,22 +184,38 @@ func NewHTTPHandler(s Service, metrics http.Handler, maxProduceSize int64, log d
 
 func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	defer prometheus.NewTimer(h.duration.WithLabelValues(r.URL.Path)).ObserveDuration()
-	ctx := r.Context()
 
-	if r.URL.Path == "/metrics" && r.Method == http.MethodGet {
-		h.metrics.ServeHTTP(w, r)
-		return
+	if r.Method == http.MethodGet {
+		switch r.URL.Path {
+		case "/metrics":
+			h.metrics.ServeHTTP(w, r)
+			return
+		case PathLiveness:
+			duh.Reply(w, r, duh.CodeOK, &pb.HealthCheckResponse{Status: HealthPass})
+			return
+		case PathReadiness:
+			h.Readiness(w, r)
+			return
+		}
 	}
 
+	ctx := propagation.TraceContext{}.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	ctx, span := h.tracer.Start(ctx, r.URL.Path,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("url.path", r.URL.Path),
+		))
+	defer span.End()
+	r = r.WithContext(ctx)
+	ctx = requestContext(r)
+
 	if r.Method != http.MethodPost {
 		duh.ReplyWithCode(w, r, duh.CodeBadRequest, nil,
 			fmt.Sprintf("http method '%s' not allowed; only POST", r.Method))
 		return
 	}
 
-	// TODO: Implement a custom duh.Reply method to capture internal errors and log them
-	//  instead of returning them to the caller.
-
 	switch r.URL.Path {
 	case RPCQueueProduce:
 		h.QueueProduce(ctx, w, r)
@@ -148,6 +227,30 @@ func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case RPCQueueComplete:
 		h.QueueComplete(ctx, w, r)
 		return
+	case RPCQueueExtend:
+		h.QueueExtend(ctx, w, r)
+		return
+	case RPCQueueRelease:
+		h.QueueRelease(ctx, w, r)
+		return
+	case RPCQueuePeek:
+		h.QueuePeek(ctx, w, r)
+		return
+	case RPCQueueGet:
+		h.QueueGet(ctx, w, r)
+		return
+	case RPCQueueRegister:
+		h.QueueRegister(ctx, w, r)
+		return
+	case RPCQueueHeartbeat:
+		h.QueueHeartbeat(ctx, w, r)
+		return
+	case RPCQueueDeregister:
+		h.QueueDeregister(ctx, w, r)
+		return
+	case RPCQueueConsumers:
+		h.QueueConsumers(ctx, w, r)
+		return
 	case RPCQueueStats:
 		h.QueueStats(ctx, w, r)
 		return
@@ -175,10 +278,33 @@ func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case RPCStorageQueueDelete:
 		h.StorageQueueDelete(ctx, w, r)
 		return
+	case RPCAdminReload:
+		h.AdminReload(ctx, w, r)
+		return
+	case RPCAdminDrain:
+		h.AdminDrain(ctx, w, r)
+		return
+	case RPCAdminDrainStatus:
+		h.AdminDrainStatus(ctx, w, r)
+		return
 	}
 	duh.ReplyWithCode(w, r, duh.CodeNotImplemented, nil, "no such method; "+r.URL.Path)
 }
 
+// Readiness replies with the result of Service.HealthCheck(), if any check failed the
+// reply has the status code 503 Service Unavailable
+func (h *HTTPHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), HealthCheckTimeout)
+	defer cancel()
+
+	var res pb.HealthCheckResponse
+	if err := h.service.HealthCheck(ctx, &res); err != nil {
+		duh.Reply(w, r, http.StatusServiceUnavailable, &res)
+		return
+	}
+	duh.Reply(w, r, duh.CodeOK, &res)
+}
+
 func (h *HTTPHandler) QueueProduce(ctx context.Context, w http.ResponseWriter, r *http.Request) {
 	var req pb.QueueProduceRequest
 	if err := duh.ReadRequest(r, &req, h.maxProduceSize); err != nil {
@@ -215,13 +341,136 @@ func (h *HTTPHandler) QueueComplete(ctx context.Context, w http.ResponseWriter,
 		return
 	}
 
-	if err := h.service.QueueComplete(ctx, &req); err != nil {
+	var resp pb.QueueCompleteResponse
+	if err := h.service.QueueComplete(ctx, &req, &resp); err != nil {
 		h.ReplyError(w, r, err)
 		return
 	}
+	if req.Detailed {
+		duh.Reply(w, r, duh.CodeOK, &resp)
+		return
+	}
 	duh.Reply(w, r, duh.CodeOK, &v1.Reply{Code: duh.CodeOK})
 }
 
+func (h *HTTPHandler) QueueExtend(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	var req pb.QueueExtendRequest
+	if err := duh.ReadRequest(r, &req, 256*duh.Kilobyte); err != nil {
+		h.ReplyError(w, r, err)
+		return
+	}
+
+	var resp pb.QueueExtendResponse
+	if err := h.service.QueueExtend(ctx, &req, &resp); err != nil {
+		h.ReplyError(w, r, err)
+		return
+	}
+	duh.Reply(w, r, duh.CodeOK, &resp)
+}
+
+func (h *HTTPHandler) QueueRelease(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	var req pb.QueueReleaseRequest
+	if err := duh.ReadRequest(r, &req, 256*duh.Kilobyte); err != nil {
+		h.ReplyError(w, r, err)
+		return
+	}
+
+	if err := h.service.QueueRelease(ctx, &req); err != nil {
+		h.ReplyError(w, r, err)
+		return
+	}
+	duh.Reply(w, r, duh.CodeOK, &v1.Reply{Code: duh.CodeOK})
+}
+
+func (h *HTTPHandler) QueuePeek(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	var req pb.QueuePeekRequest
+	if err := duh.ReadRequest(r, &req, 512*duh.Bytes); err != nil {
+		h.ReplyError(w, r, err)
+		return
+	}
+
+	var resp pb.QueuePeekResponse
+	if err := h.service.QueuePeek(ctx, &req, &resp); err != nil {
+		h.ReplyError(w, r, err)
+		return
+	}
+	duh.Reply(w, r, duh.CodeOK, &resp)
+}
+
+func (h *HTTPHandler) QueueGet(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	var req pb.QueueGetRequest
+	if err := duh.ReadRequest(r, &req, 256*duh.Kilobyte); err != nil {
+		h.ReplyError(w, r, err)
+		return
+	}
+
+	var resp pb.QueueGetResponse
+	if err := h.service.QueueGet(ctx, &req, &resp); err != nil {
+		h.ReplyError(w, r, err)
+		return
+	}
+	duh.Reply(w, r, duh.CodeOK, &resp)
+}
+
+func (h *HTTPHandler) QueueRegister(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	var req pb.QueueRegisterRequest
+	if err := duh.ReadRequest(r, &req, 512*duh.Bytes); err != nil {
+		h.ReplyError(w, r, err)
+		return
+	}
+
+	var resp pb.QueueHeartbeatResponse
+	if err := h.service.QueueRegister(ctx, &req, &resp); err != nil {
+		h.ReplyError(w, r, err)
+		return
+	}
+	duh.Reply(w, r, duh.CodeOK, &resp)
+}
+
+func (h *HTTPHandler) QueueHeartbeat(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	var req pb.QueueHeartbeatRequest
+	if err := duh.ReadRequest(r, &req, 512*duh.Bytes); err != nil {
+		h.ReplyError(w, r, err)
+		return
+	}
+
+	var resp pb.QueueHeartbeatResponse
+	if err := h.service.QueueHeartbeat(ctx, &req, &resp); err != nil {
+		h.ReplyError(w, r, err)
+		return
+	}
+	duh.Reply(w, r, duh.CodeOK, &resp)
+}
+
+func (h *HTTPHandler) QueueDeregister(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	var req pb.QueueDeregisterRequest
+	if err := duh.ReadRequest(r, &req, 512*duh.Bytes); err != nil {
+		h.ReplyError(w, r, err)
+		return
+	}
+
+	if err := h.service.QueueDeregister(ctx, &req); err != nil {
+		h.ReplyError(w, r, err)
+		return
+	}
+	duh.Reply(w, r, duh.CodeOK, &v1.Reply{Code: duh.CodeOK})
+}
+
+func (h *HTTPHandler) QueueConsumers(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	var req pb.QueueConsumersRequest
+	if err := duh.ReadRequest(r, &req, 512*duh.Bytes); err != nil {
+		h.ReplyError(w, r, err)
+		return
+	}
+
+	var resp pb.QueueConsumersResponse
+	if err := h.service.QueueConsumers(ctx, &req, &resp); err != nil {
+		h.ReplyError(w, r, err)
+		return
+	}
+	duh.Reply(w, r, duh.CodeOK, &resp)
+}
+
 // -------------------------------------------------
 // API to manage lists of queues
 // -------------------------------------------------
@@ -357,6 +606,32 @@ func (h *HTTPHandler) StorageQueueDelete(ctx context.Context, w http.ResponseWri
 	duh.Reply(w, r, duh.CodeOK, &v1.Reply{Code: duh.CodeOK})
 }
 
+func (h *HTTPHandler) AdminReload(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Reload(ctx); err != nil {
+		h.ReplyError(w, r, err)
+		return
+	}
+	duh.Reply(w, r, duh.CodeOK, &v1.Reply{Code: duh.CodeOK})
+}
+
+func (h *HTTPHandler) AdminDrain(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	var resp pb.AdminDrainResponse
+	if err := h.service.Drain(ctx, &resp); err != nil {
+		h.ReplyError(w, r, err)
+		return
+	}
+	duh.Reply(w, r, duh.CodeOK, &resp)
+}
+
+func (h *HTTPHandler) AdminDrainStatus(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	var resp pb.AdminDrainResponse
+	if err := h.service.DrainStatus(ctx, &resp); err != nil {
+		h.ReplyError(w, r, err)
+		return
+	}
+	duh.Reply(w, r, duh.CodeOK, &resp)
+}
+
 // Describe fetches prometheus metrics to be registered
 func (h *HTTPHandler) Describe(ch chan<- *prometheus.Desc) {
 	h.duration.Describe(ch)
@@ -367,20 +642,36 @@ func (h *HTTPHandler) Collect(ch chan<- prometheus.Metric) {
 	h.duration.Collect(ch)
 }
 
+// ReplyError replies to the client with the error provided. Errors which are typed `duh.Error`, such as the
+// errors in this package, are returned to the client as is. All other errors are unexpected and could
+// contain internal details like file paths or storage errors which should not be exposed to the client. Such
+// errors are logged along with any errors.Fields they carry and a generated error id, and the client receives
+// a generic internal error which includes only the error id for correlation.
 func (h *HTTPHandler) ReplyError(w http.ResponseWriter, r *http.Request, err error) {
+	var rr *ErrRetryRequest
+	if errors.As(err, &rr) && rr.RetryAfter() != 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rr.RetryAfter().Seconds()))))
+	}
+
+	span := trace.SpanFromContext(r.Context())
+	span.SetStatus(codes.Error, err.Error())
+
 	var re duh.Error
-	if errors.As(err, &re) {
+	if errors.As(err, &re) && re.Code() != duh.CodeInternalError {
 		duh.Reply(w, r, re.Code(), re.ProtoMessage())
 		return
 	}
-	// TODO: Extract error.Fields and add them to the log fields
 
-	h.log.Error(err.Error(),
+	id := ksuid.New().String()
+	attrs := append(errors.ToAttr(err),
 		"category", "http",
+		"error.id", id,
 		"http.request.status", duh.CodeInternalError,
 		"http.request.url", r.URL.String(),
-		"http.request.headers", r.Header,
 		"http.request.useragent", r.Header.Get("user-agent"),
 	)
-	duh.ReplyWithCode(w, r, duh.CodeInternalError, nil, "Internal Error")
+	h.log.Error(MsgInternalError, attrs...)
+	span.SetAttributes(attribute.String("error.id", id))
+	duh.ReplyWithCode(w, r, duh.CodeInternalError, map[string]string{DetailsErrorID: id},
+		fmt.Sprintf("%s; error id '%s'", MsgInternalError, id))
 }
@@ -0,0 +1,90 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"github.com/duh-rpc/duh-go"
+	v1 "github.com/duh-rpc/duh-go/proto/v1"
+	"github.com/kapetan-io/errors"
+	pb "github.com/kapetan-io/querator/proto"
+	"github.com/kapetan-io/tackle/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type errorService struct {
+	Service
+	err error
+}
+
+func (s *errorService) QueueProduce(context.Context, *pb.QueueProduceRequest) error {
+	return s.err
+}
+
+func produce(t *testing.T, h *HTTPHandler) (*httptest.ResponseRecorder, *v1.Reply) {
+	t.Helper()
+
+	payload, err := proto.Marshal(&pb.QueueProduceRequest{QueueName: "queue-1"})
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodPost, RPCQueueProduce, bytes.NewReader(payload))
+	r.Header.Set("Content-Type", duh.ContentTypeProtoBuf)
+	r.Header.Set("Accept", duh.ContentTypeProtoBuf)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	var reply v1.Reply
+	require.NoError(t, proto.Unmarshal(w.Body.Bytes(), &reply))
+	return w, &reply
+}
+
+func TestReplyError(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&buf, nil))
+	s := &errorService{}
+	h := NewHTTPHandler(s, nil, 0, nil, log)
+
+	t.Run("InternalError", func(t *testing.T) {
+		buf.Reset()
+		s.err = errors.Fields{"category", "bolt", "func", "Partition.Produce"}.
+			Errorf("while opening db '/var/lib/querator/queue-1.db': timeout")
+
+		w, reply := produce(t, h)
+		assert.Equal(t, duh.CodeInternalError, w.Code)
+		id := reply.Details[DetailsErrorID]
+		require.NotEmpty(t, id)
+		assert.Equal(t, "internal error; error id '"+id+"'", reply.Message)
+		assert.NotContains(t, reply.Message, "/var/lib/querator")
+
+		// The error and its fields are logged along with the error id
+		assert.Contains(t, buf.String(), `"error.id":"`+id+`"`)
+		assert.Contains(t, buf.String(), `"func":"Partition.Produce"`)
+		assert.Contains(t, buf.String(), "/var/lib/querator/queue-1.db")
+	})
+
+	t.Run("TypedError", func(t *testing.T) {
+		buf.Reset()
+		s.err = errors.Fields{"category", "bolt"}.Errorf("during Add(): %w",
+			NewInvalidOption("invalid queue; 'queue-1' already exists"))
+
+		w, reply := produce(t, h)
+		assert.Equal(t, duh.CodeBadRequest, w.Code)
+		assert.Equal(t, "invalid queue; 'queue-1' already exists", reply.Message)
+		assert.Empty(t, reply.Details[DetailsErrorID])
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("RetryAfter", func(t *testing.T) {
+		s.err = NewRetryRequestAfter(1500*clock.Millisecond, "rate limit exceeded")
+
+		w, reply := produce(t, h)
+		assert.Equal(t, duh.CodeRetryRequest, w.Code)
+		assert.Equal(t, "2", w.Header().Get("Retry-After"))
+		assert.Equal(t, "1.5s", reply.Details[DetailsRetryAfter])
+	})
+}
@@ -8,9 +8,14 @@ import (
 )
 
 const (
-	maxTimeoutLength  = 15
-	defaultAllocation = 512  // 2<<8
-	maxAllocation     = 2048 // 2<<10
+	maxTimeoutLength   = 15
+	maxReferenceSize   = 2_000
+	maxKindSize        = 512
+	maxEncodingSize    = 512
+	maxDedupIDSize     = 512
+	maxOrderingKeySize = 512
+	defaultAllocation  = 512  // 2<<8
+	maxAllocation      = 2048 // 2<<10
 )
 
 func allocInt32(mem int32) int {
@@ -23,7 +28,8 @@ func allocInt32(mem int32) int {
 	return int(mem)
 }
 
-func (s *Service) validateQueueProduceProto(in *proto.QueueProduceRequest, out *types.ProduceRequest) error {
+func (s *Service) validateQueueProduceProto(in *proto.QueueProduceRequest, out *types.ProduceRequest,
+	maxItemSize int) error {
 	var err error
 
 	if in.RequestTimeout != "" {
@@ -44,11 +50,112 @@ func (s *Service) validateQueueProduceProto(in *proto.QueueProduceRequest, out *
 		} else {
 			qi.Payload = []byte(item.Utf8)
 		}
+		if err := s.validateItemOptions(item, qi); err != nil {
+			return err
+		}
+		if err := s.validateItem(qi, maxItemSize); err != nil {
+			return err
+		}
 		out.Items = append(out.Items, qi)
 	}
 	return nil
 }
 
+// validateItemOptions validates the reservation options a producer can set for each item. The options are
+// validated against the limits of the queue by Logical.Produce()
+func (s *Service) validateItemOptions(in *proto.QueueProduceItem, out *types.Item) error {
+	var err error
+
+	if len(in.ReserveTimeout) > maxTimeoutLength {
+		return transport.NewInvalidOption("item reserve timeout is invalid; cannot be greater than '%d' characters", maxTimeoutLength)
+	}
+
+	if in.ReserveTimeout != "" {
+		out.ReserveTimeout, err = clock.ParseDuration(in.ReserveTimeout)
+		if err != nil {
+			return transport.NewInvalidOption("item reserve timeout is invalid; %s - expected format: 30s, 5m or 1h", err.Error())
+		}
+		if out.ReserveTimeout <= 0 {
+			return transport.NewInvalidOption("item reserve timeout is invalid; '%s' must be greater than zero", in.ReserveTimeout)
+		}
+	}
+
+	if len(in.DeadTimeout) > maxTimeoutLength {
+		return transport.NewInvalidOption("item dead timeout is invalid; cannot be greater than '%d' characters", maxTimeoutLength)
+	}
+
+	if in.DeadTimeout != "" {
+		out.DeadTimeout, err = clock.ParseDuration(in.DeadTimeout)
+		if err != nil {
+			return transport.NewInvalidOption("item dead timeout is invalid; %s - expected format: 60m, 2h or 24h", err.Error())
+		}
+		if out.DeadTimeout <= 0 {
+			return transport.NewInvalidOption("item dead timeout is invalid; '%s' must be greater than zero", in.DeadTimeout)
+		}
+	}
+
+	if len(in.ExpireTimeout) > maxTimeoutLength {
+		return transport.NewInvalidOption("item expire timeout is invalid; cannot be greater than '%d' characters", maxTimeoutLength)
+	}
+
+	if in.ExpireTimeout != "" {
+		out.ExpireTimeout, err = clock.ParseDuration(in.ExpireTimeout)
+		if err != nil {
+			return transport.NewInvalidOption("item expire timeout is invalid; %s - expected format: 30s, 5m or 1h", err.Error())
+		}
+		if out.ExpireTimeout <= 0 {
+			return transport.NewInvalidOption("item expire timeout is invalid; '%s' must be greater than zero", in.ExpireTimeout)
+		}
+	}
+
+	if in.MaxAttempts < 0 {
+		return transport.NewInvalidOption("item max attempts is invalid; cannot be negative number")
+	}
+
+	if len(in.DedupId) > maxDedupIDSize {
+		return transport.NewInvalidOption("item dedup id is invalid; cannot be greater than '%d' "+
+			"characters", maxDedupIDSize)
+	}
+	out.MaxAttempts = int(in.MaxAttempts)
+	out.DedupID = in.DedupId
+	out.OrderingKey = in.OrderingKey
+	out.Priority = int(in.Priority)
+	return nil
+}
+
+func (s *Service) validateItem(item *types.Item, maxItemSize int) error {
+	if len(item.Payload) > maxItemSize {
+		return transport.NewInvalidOption("item payload is invalid; max_item_size is %d bytes but "+
+			"received %d bytes", maxItemSize, len(item.Payload))
+	}
+
+	if len(item.Reference) > maxReferenceSize {
+		return transport.NewInvalidOption("item reference is invalid; cannot be greater than '%d' "+
+			"characters", maxReferenceSize)
+	}
+
+	if len(item.Kind) > maxKindSize {
+		return transport.NewInvalidOption("item kind is invalid; cannot be greater than '%d' "+
+			"characters", maxKindSize)
+	}
+
+	if len(item.Encoding) > maxEncodingSize {
+		return transport.NewInvalidOption("item encoding is invalid; cannot be greater than '%d' "+
+			"characters", maxEncodingSize)
+	}
+
+	if len(item.OrderingKey) > maxOrderingKeySize {
+		return transport.NewInvalidOption("item ordering key is invalid; cannot be greater than '%d' "+
+			"characters", maxOrderingKeySize)
+	}
+
+	if item.Priority < 0 || item.Priority >= types.NumPriorities {
+		return transport.NewInvalidOption("item priority is invalid; %d must be between 0 and %d",
+			item.Priority, types.NumPriorities-1)
+	}
+	return nil
+}
+
 func (s *Service) validateQueueReserveProto(in *proto.QueueReserveRequest, out *types.ReserveRequest) error {
 	var err error
 
@@ -65,6 +172,21 @@ func (s *Service) validateQueueReserveProto(in *proto.QueueReserveRequest, out *
 	return nil
 }
 
+func (s *Service) validateQueueRegisterProto(in *proto.QueueRegisterRequest, out *types.ConsumerRequest) error {
+	var err error
+
+	if in.HeartbeatTimeout != "" {
+		out.HeartbeatTimeout, err = clock.ParseDuration(in.HeartbeatTimeout)
+		if err != nil {
+			return transport.NewInvalidOption("heartbeat timeout is invalid; %s - expected format: 30s, 5m or 15m", err.Error())
+		}
+	}
+
+	out.ClientID = in.ClientId
+
+	return nil
+}
+
 func (s *Service) validateQueueCompleteProto(in *proto.QueueCompleteRequest, out *types.CompleteRequest) error {
 	var err error
 
@@ -83,6 +205,56 @@ func (s *Service) validateQueueCompleteProto(in *proto.QueueCompleteRequest, out
 	for _, id := range in.Ids {
 		out.Ids = append(out.Ids, []byte(id))
 	}
+	out.Detailed = in.Detailed
+
+	return nil
+}
+
+func (s *Service) validateQueueExtendProto(in *proto.QueueExtendRequest, out *types.ExtendRequest) error {
+	var err error
+
+	if in.RequestTimeout != "" {
+		out.RequestTimeout, err = clock.ParseDuration(in.RequestTimeout)
+		if err != nil {
+			return transport.NewInvalidOption("request timeout is invalid; %s - expected format: 900ms, 5m or 15m", err.Error())
+		}
+	}
+
+	if len(in.ReserveTimeout) > maxTimeoutLength {
+		return transport.NewInvalidOption("reserve timeout is invalid; cannot be greater than '%d' characters", maxTimeoutLength)
+	}
+
+	if in.ReserveTimeout != "" {
+		out.ReserveTimeout, err = clock.ParseDuration(in.ReserveTimeout)
+		if err != nil {
+			return transport.NewInvalidOption("reserve timeout is invalid; %s - expected format: 30s, 5m or 1h", err.Error())
+		}
+		if out.ReserveTimeout <= 0 {
+			return transport.NewInvalidOption("reserve timeout is invalid; '%s' must be greater than zero", in.ReserveTimeout)
+		}
+	}
+
+	for _, id := range in.Ids {
+		out.Ids = append(out.Ids, []byte(id))
+	}
+
+	return nil
+}
+
+func (s *Service) validateQueueReleaseProto(in *proto.QueueReleaseRequest, out *types.ReleaseRequest) error {
+	var err error
+
+	if in.RequestTimeout != "" {
+		out.RequestTimeout, err = clock.ParseDuration(in.RequestTimeout)
+		if err != nil {
+			return transport.NewInvalidOption("request timeout is invalid; %s - expected format: 900ms, 5m or 15m", err.Error())
+		}
+	}
+
+	for _, id := range in.Ids {
+		out.Ids = append(out.Ids, []byte(id))
+	}
+	out.SkipAttempt = in.SkipAttempt
 
 	return nil
 }
@@ -112,6 +284,59 @@ func (s *Service) validateQueueOptionsProto(in *proto.QueueInfo, out *types.Queu
 		}
 	}
 
+	if len(in.DedupWindow) > maxTimeoutLength {
+		return transport.NewInvalidOption("dedup window is invalid; cannot be greater than '%d' characters", maxTimeoutLength)
+	}
+
+	if in.DedupWindow != "" {
+		out.DedupWindow, err = clock.ParseDuration(in.DedupWindow)
+		if err != nil {
+			return transport.NewInvalidOption("dedup window is invalid; %s - expected format: 5m, 1h or 24h", err.Error())
+		}
+	}
+
+	if len(in.ExpireTimeout) > maxTimeoutLength {
+		return transport.NewInvalidOption("expire timeout is invalid; cannot be greater than '%d' characters", maxTimeoutLength)
+	}
+
+	if in.ExpireTimeout != "" {
+		out.ExpireTimeout, err = clock.ParseDuration(in.ExpireTimeout)
+		if err != nil {
+			return transport.NewInvalidOption("expire timeout is invalid; %s - expected format: 30s, 5m or 1h", err.Error())
+		}
+	}
+
+	if in.MaxItemSize < 0 {
+		return transport.NewInvalidOption("max item size is invalid; cannot be negative number")
+	}
+
+	if maxItemSize := s.conf.Load().MaxItemSize; int(in.MaxItemSize) > maxItemSize {
+		return transport.NewInvalidOption("max item size is invalid; cannot be greater than the service "+
+			"max_item_size of %d bytes", maxItemSize)
+	}
+
+	if len(in.PriorityWeights) != 0 && len(in.PriorityWeights) != types.NumPriorities {
+		return transport.NewInvalidOption("priority weights is invalid; expected %d weights but received %d",
+			types.NumPriorities, len(in.PriorityWeights))
+	}
+
+	// All weights may be zero to select strict priority, otherwise every level must have a weight
+	var total int32
+	for _, w := range in.PriorityWeights {
+		if w < 0 {
+			return transport.NewInvalidOption("priority weights is invalid; weight cannot be negative")
+		}
+		total += w
+	}
+	for _, w := range in.PriorityWeights {
+		if total != 0 && w == 0 {
+			return transport.NewInvalidOption("priority weights is invalid; weight cannot be 0 unless " +
+				"all weights are 0")
+		}
+		out.PriorityWeights = append(out.PriorityWeights, int(w))
+	}
+
+	out.MaxItemSize = int(in.MaxItemSize)
 	out.MaxAttempts = int(in.MaxAttempts)
 	out.Partitions = int(in.Partitions)
 	out.DeadQueue = in.DeadQueue